@@ -0,0 +1,86 @@
+// apikey_cmd.go
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"collision_app_go/internal/model"
+)
+
+// runAPIKey creates, lists or revokes API keys.
+func runAPIKey(args []string) error {
+	action := "list"
+	if len(args) > 0 && args[0] != "" && args[0][0] != '-' {
+		action, args = args[0], args[1:]
+	}
+	fs := flag.NewFlagSet("apikey", flag.ExitOnError)
+	name := fs.String("name", "", "key name, e.g. the calling system (create only)")
+	role := fs.String("role", model.RoleViewer, "viewer, operator or admin (create only)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	a, err := newApp(ctx, true)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	authService, err := a.authService()
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case "create":
+		raw, key, err := authService.CreateAPIKey(ctx, *name, *role)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Created key %d (%s, %s). Store it now; it cannot be shown again.\n", key.ID, key.Name, key.Role)
+		fmt.Println(raw)
+	case "list":
+		keys, err := authService.ListAPIKeys(ctx)
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tNAME\tROLE\tSTATUS\tCREATED\tLAST USED")
+		for _, k := range keys {
+			status, lastUsed := "active", "never"
+			if k.Revoked {
+				status = "revoked"
+			}
+			if k.LastUsedTime != nil {
+				lastUsed = k.LastUsedTime.Format(time.RFC3339)
+			}
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n", k.ID, k.Name, k.Role, status, k.CreateTime.Format(time.RFC3339), lastUsed)
+		}
+		return tw.Flush()
+	case "revoke":
+		if fs.NArg() != 1 {
+			return errors.New("usage: apikey revoke <id>")
+		}
+		id, err := strconv.ParseInt(fs.Arg(0), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid key id %q", fs.Arg(0))
+		}
+		if err := authService.RevokeAPIKey(ctx, id); err != nil {
+			return err
+		}
+		fmt.Printf("Revoked key %d\n", id)
+	default:
+		return fmt.Errorf("unknown apikey action %q (want create, list or revoke)", action)
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+// app.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"collision_app_go/config"
+	"collision_app_go/internal/audit"
+	"collision_app_go/internal/repository"
+	"collision_app_go/internal/service"
+	"collision_app_go/utils"
+)
+
+// app holds what every command needs: configuration, logger and database pool.
+type app struct {
+	cfg       *config.Config
+	dbpool    *pgxpool.Pool
+	logCloser io.Closer
+}
+
+// newApp loads configuration, sets up logging and connects to the database.
+// CLI commands other than serve log to stderr so their stdout stays clean.
+func newApp(ctx context.Context, cli bool) (*app, error) {
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	logCfg := cfg.Log
+	if cli && logCfg.Output == "stdout" {
+		logCfg.Output = "stderr"
+	}
+	logCloser, err := utils.SetupLogger(logCfg.Level, logCfg.Format, logCfg.Output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up logger: %w", err)
+	}
+
+	poolCfg := cfg.Pool
+	utils.Info(ctx, "database config loaded", "database", cfg.Database)
+	utils.Info(ctx, "connection pool config loaded", "min_conns", poolCfg.MinConns, "max_conns", poolCfg.MaxConns,
+		"max_conn_lifetime", poolCfg.MaxConnLifetime, "max_conn_idle_time", poolCfg.MaxConnIdleTime, "health_check_period", poolCfg.HealthCheckPeriod)
+
+	// Configure the pool; the password is kept out of the connection string
+	poolConfig, err := pgxpool.ParseConfig(cfg.Database.ConnectionString())
+	if err != nil {
+		logCloser.Close()
+		return nil, fmt.Errorf("failed to parse pool config: %w", err)
+	}
+	poolConfig.ConnConfig.Password = cfg.Database.Password
+	poolConfig.MinConns = poolCfg.MinConns
+	poolConfig.MaxConns = poolCfg.MaxConns
+	poolConfig.MaxConnLifetime = poolCfg.MaxConnLifetime
+	poolConfig.MaxConnIdleTime = poolCfg.MaxConnIdleTime
+	poolConfig.HealthCheckPeriod = poolCfg.HealthCheckPeriod
+	if cli {
+		// One-off commands do not need a warm pool
+		poolConfig.MinConns = 0
+	}
+
+	dbpool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		logCloser.Close()
+		return nil, fmt.Errorf("unable to create connection pool: %w", err)
+	}
+
+	// Test the connection
+	if err := dbpool.Ping(ctx); err != nil {
+		dbpool.Close()
+		logCloser.Close()
+		return nil, fmt.Errorf("unable to ping database: %w", err)
+	}
+	utils.Info(ctx, "successfully connected to the database")
+
+	return &app{cfg: cfg, dbpool: dbpool, logCloser: logCloser}, nil
+}
+
+// Close releases the database pool and the log sink.
+func (a *app) Close() {
+	a.dbpool.Close()
+	a.logCloser.Close()
+}
+
+// quietLogs raises the log level to warn when CLI logs go to the terminal, so that
+// they do not break up a progress bar drawn on stderr. Logs written to a file are
+// left alone.
+func (a *app) quietLogs() error {
+	logCfg := a.cfg.Log
+	if logCfg.Output != "" && logCfg.Output != "stdout" && logCfg.Output != "stderr" {
+		return nil
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(logCfg.Level)); err != nil || level >= slog.LevelWarn {
+		return err
+	}
+	logCloser, err := utils.SetupLogger("warn", logCfg.Format, "stderr")
+	if err != nil {
+		return fmt.Errorf("failed to set up logger: %w", err)
+	}
+	a.logCloser.Close()
+	a.logCloser = logCloser
+	return nil
+}
+
+// collisionService builds a collision service with the configured warning zones,
+// recording its checks with auditor when it is not nil.
+func (a *app) collisionService(auditor service.Auditor) *service.CollisionService {
+	return service.NewCollisionService(repository.NewBuildingRepository(a.dbpool), a.cfg.Collision.WarningZones, auditor)
+}
+
+// auditor starts the audit log recorder, or returns nil when the audit log is
+// disabled. stop writes the buffered entries and must be called before Close.
+func (a *app) auditor() (auditor service.Auditor, stop func()) {
+	au := a.cfg.Audit
+	if !au.Enabled {
+		return nil, func() {}
+	}
+	recorder := audit.NewRecorder(repository.NewAuditRepository(a.dbpool), audit.Options{
+		BufferSize:      au.BufferSize,
+		MaxWait:         au.MaxWait,
+		DropWhenFull:    au.DropWhenFull,
+		BatchSize:       au.BatchSize,
+		FlushInterval:   au.FlushInterval,
+		RetentionMonths: au.RetentionMonths,
+		StoreResults:    au.StoreResults,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		recorder.Run(ctx)
+	}()
+	return recorder, func() {
+		cancel()
+		<-done
+	}
+}
+
+// authService builds the API key and JWT authentication service.
+func (a *app) authService() (*service.AuthService, error) {
+	auth := a.cfg.Auth
+	var jwks *service.JWKS
+	if auth.JWKSFile != "" {
+		var err error
+		if jwks, err = service.LoadJWKS(auth.JWKSFile); err != nil {
+			return nil, err
+		}
+	}
+	return service.NewAuthService(repository.NewAPIKeyRepository(a.dbpool), jwks, service.JWTConfig{
+		Issuer:    auth.Issuer,
+		Audience:  auth.Audience,
+		RoleClaim: auth.RoleClaim,
+		Leeway:    auth.Leeway,
+	}), nil
+}
+
+// webhookService builds the webhook service, or returns nil when webhooks are
+// disabled. Events published through a nil service are dropped.
+func (a *app) webhookService() *service.WebhookService {
+	if !a.cfg.Webhooks.Enabled {
+		return nil
+	}
+	return service.NewWebhookService(repository.NewWebhookRepository(a.dbpool))
+}
+
+// deconflictionConfig returns the configured separation minima.
+func (a *app) deconflictionConfig() service.DeconflictionConfig {
+	col := a.cfg.Collision
+	return service.DeconflictionConfig{
+		HorizontalSeparation: col.HorizontalSeparation,
+		VerticalSeparation:   col.VerticalSeparation,
+		TimeBuffer:           col.TimeBuffer,
+		SliceDuration:        col.SliceDuration,
+		MaxTimeShift:         col.MaxTimeShift,
+		MaxPlanDuration:      col.MaxPlanDuration,
+		MaxPlanWaypoints:     col.MaxPlanWaypoints,
+	}
+}
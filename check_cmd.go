@@ -0,0 +1,81 @@
+// check_cmd.go
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"collision_app_go/internal/model"
+	"collision_app_go/internal/service"
+)
+
+// runCheck runs a single collision query and prints the result as JSON.
+func runCheck(args []string) error {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	lon := fs.Float64("lon", 0, "longitude (required)")
+	lat := fs.Float64("lat", 0, "latitude (required)")
+	height := fs.Float64("height", 0, "height in meters (required)")
+	distance := fs.Float64("distance", 0, "collision distance in meters (default from config)")
+	radius := fs.Float64("drone-radius", 0, "drone radius in meters")
+	mode := fs.String("mode", model.Mode25D, "intersection mode: 2.5d or 3d")
+	asOf := fs.String("as-of", "", "check against the buildings as they were at this RFC 3339 time (not with -mode 3d)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if !set["lon"] || !set["lat"] || !set["height"] {
+		return errors.New("usage: check --lon X --lat Y --height Z [--distance D] [--drone-radius R] [--mode 2.5d|3d] [--as-of T]")
+	}
+	if *mode != model.Mode25D && *mode != model.Mode3D {
+		return errors.New("mode must be 2.5d or 3d")
+	}
+	var at time.Time
+	if *asOf != "" {
+		var err error
+		if at, err = time.Parse(time.RFC3339, *asOf); err != nil {
+			return fmt.Errorf("-as-of must be an RFC 3339 time: %w", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	a, err := newApp(ctx, true)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	if !set["distance"] {
+		*distance = a.cfg.Collision.DefaultDistance
+	}
+	auditor, stopAudit := a.auditor()
+	defer stopAudit()
+	collisionService := a.collisionService(auditor)
+	if !at.IsZero() {
+		collisionService = collisionService.AsOf(at)
+	}
+	ctx = service.WithOrigin(ctx, model.SourceCLI, "")
+
+	footprint := model.Footprint{DroneRadius: *radius}
+	var result any
+	if *mode == model.Mode3D {
+		result, err = collisionService.CheckCollision3D(ctx, *lon, *lat, *height, *distance, footprint)
+	} else {
+		result, err = collisionService.CheckCollision(ctx, *lon, *lat, *height, *distance, footprint)
+	}
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(result)
+}
@@ -2,29 +2,337 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/url"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
+
+	"collision_app_go/internal/model"
 
 	"github.com/joho/godotenv" // 确保导入了这个包
+	"gopkg.in/yaml.v3"
 )
 
+// DefaultConfigFile is read when neither -config nor CONFIG_FILE names a file.
+// It is optional: without it the built-in defaults and environment variables are used.
+const DefaultConfigFile = "config.yaml"
+
+// DefaultWarningZones is used when COLLISION_ZONES is not set.
+const DefaultWarningZones = "caution:30:20,warning:15:10,critical:5:2"
+
+// Config is the complete application configuration.
+type Config struct {
+	Server    ServerConfig    `yaml:"server"`
+	GRPC      GRPCConfig      `yaml:"grpc"`
+	Telemetry TelemetryConfig `yaml:"telemetry"`
+	Webhooks  WebhookConfig   `yaml:"webhooks"`
+	Audit     AuditConfig     `yaml:"audit"`
+	Import    ImportConfig    `yaml:"import"`
+	Database  DBConfig        `yaml:"database"`
+	Pool      PoolConfig      `yaml:"pool"`
+	Collision CollisionConfig `yaml:"collision"`
+	Log       LogConfig       `yaml:"log"`
+	Auth      AuthConfig      `yaml:"auth"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+}
+
+// ServerConfig holds HTTP server settings.
+type ServerConfig struct {
+	Addr            string        `yaml:"addr"`             // SERVER_ADDR
+	GinMode         string        `yaml:"gin_mode"`         // GIN_MODE: debug, release or test
+	ReadTimeout     time.Duration `yaml:"read_timeout"`     // SERVER_READ_TIMEOUT, 0 means none
+	WriteTimeout    time.Duration `yaml:"write_timeout"`    // SERVER_WRITE_TIMEOUT, 0 means none
+	IdleTimeout     time.Duration `yaml:"idle_timeout"`     // SERVER_IDLE_TIMEOUT, 0 means none
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"` // SERVER_SHUTDOWN_TIMEOUT
+	DrainDelay      time.Duration `yaml:"drain_delay"`      // SERVER_DRAIN_DELAY: not-ready period before shutdown
+}
+
+// GRPCConfig holds gRPC server settings. The gRPC server listens on its own port
+// and shuts down together with the HTTP server.
+type GRPCConfig struct {
+	Enabled    bool   `yaml:"enabled"`    // GRPC_ENABLED
+	Addr       string `yaml:"addr"`       // GRPC_ADDR
+	Reflection bool   `yaml:"reflection"` // GRPC_REFLECTION: register the reflection service (for grpcurl)
+}
+
+// TelemetryConfig holds the MAVLink telemetry listener settings. Vehicles streaming
+// MAVLink v2 over UDP are tracked and checked against the warning zones.
+type TelemetryConfig struct {
+	Enabled       bool          `yaml:"enabled"`        // TELEMETRY_ENABLED
+	Addr          string        `yaml:"addr"`           // TELEMETRY_ADDR: UDP listen address
+	CheckInterval time.Duration `yaml:"check_interval"` // TELEMETRY_CHECK_INTERVAL: minimum time between checks of one vehicle
+	StaleAfter    time.Duration `yaml:"stale_after"`    // TELEMETRY_STALE_AFTER: forget vehicles silent for this long
+	DroneRadius   float64       `yaml:"drone_radius"`   // TELEMETRY_DRONE_RADIUS: airframe radius in meters
+	RecordFile    string        `yaml:"record_file"`    // TELEMETRY_RECORD_FILE: append received frames to this .tlog, empty to disable
+	AlertLog      bool          `yaml:"alert_log"`      // TELEMETRY_ALERT_LOG: write alerts to the log
+	// AllowedSources lists the IP addresses and CIDR networks datagrams are accepted
+	// from; others are dropped. Empty accepts every sender
+	// (TELEMETRY_ALLOWED_SOURCES, comma-separated).
+	AllowedSources []string `yaml:"allowed_sources"`
+	// GeofenceCorridor is the allowed horizontal distance, in meters, of a vehicle from
+	// the route of its active approved flight plan; 0 disables the geofence check
+	// (TELEMETRY_GEOFENCE_CORRIDOR). Flight plans match vehicles by MAVLink system ID.
+	GeofenceCorridor float64 `yaml:"geofence_corridor"`
+	// AlertWebhook receives every alert as a JSON POST, empty to disable (TELEMETRY_ALERT_WEBHOOK).
+	AlertWebhook   string        `yaml:"alert_webhook"`
+	WebhookTimeout time.Duration `yaml:"webhook_timeout"` // TELEMETRY_WEBHOOK_TIMEOUT
+}
+
+// WebhookConfig holds the webhook delivery settings. Events are queued in the
+// webhook_outbox table and sent by a background dispatcher, retrying failed
+// deliveries with exponential backoff.
+type WebhookConfig struct {
+	Enabled      bool          `yaml:"enabled"`       // WEBHOOK_ENABLED
+	PollInterval time.Duration `yaml:"poll_interval"` // WEBHOOK_POLL_INTERVAL: how often the outbox is checked
+	BatchSize    int           `yaml:"batch_size"`    // WEBHOOK_BATCH_SIZE: deliveries claimed per poll
+	Concurrency  int           `yaml:"concurrency"`   // WEBHOOK_CONCURRENCY: deliveries sent in parallel
+	MaxAttempts  int           `yaml:"max_attempts"`  // WEBHOOK_MAX_ATTEMPTS: attempts before a delivery is given up
+	BaseBackoff  time.Duration `yaml:"base_backoff"`  // WEBHOOK_BASE_BACKOFF: delay before the first retry
+	MaxBackoff   time.Duration `yaml:"max_backoff"`   // WEBHOOK_MAX_BACKOFF: upper bound of the retry delay
+	Timeout      time.Duration `yaml:"timeout"`       // WEBHOOK_TIMEOUT: timeout of one delivery request
+}
+
+// AuditConfig holds the collision check audit log settings. Checks are buffered in
+// memory and written to the monthly partitioned collision_audit table in batches;
+// when the buffer is full a check waits up to max_wait for room before its entry is
+// dropped, or is dropped at once with drop_when_full.
+type AuditConfig struct {
+	Enabled         bool          `yaml:"enabled"`          // AUDIT_ENABLED
+	BufferSize      int           `yaml:"buffer_size"`      // AUDIT_BUFFER_SIZE: entries held before checks wait
+	MaxWait         time.Duration `yaml:"max_wait"`         // AUDIT_MAX_WAIT: longest a check waits for room in a full buffer
+	DropWhenFull    bool          `yaml:"drop_when_full"`   // AUDIT_DROP_WHEN_FULL: drop entries at once rather than wait
+	BatchSize       int           `yaml:"batch_size"`       // AUDIT_BATCH_SIZE: entries written per COPY
+	FlushInterval   time.Duration `yaml:"flush_interval"`   // AUDIT_FLUSH_INTERVAL: longest an entry waits to be written
+	RetentionMonths int           `yaml:"retention_months"` // AUDIT_RETENTION_MONTHS: older partitions are dropped, 0 keeps all
+	StoreResults    bool          `yaml:"store_results"`    // AUDIT_STORE_RESULTS: keep the full answer, not just its outcome
+}
+
+// ImportConfig holds building import settings.
+type ImportConfig struct {
+	// InvalidGeometry is what happens to lines whose footprint fails validation:
+	// reject, repair (ST_MakeValid) or quarantine (IMPORT_INVALID_GEOMETRY).
+	// Requests and the import command may override it.
+	InvalidGeometry string `yaml:"invalid_geometry"`
+}
+
 // DBConfig holds database connection details.
 type DBConfig struct {
-	Host     string
-	Port     string
-	User     string
-	Password string
-	DBName   string
-	SSLMode  string
+	Host        string `yaml:"host"`         // DB_HOST
+	Port        string `yaml:"port"`         // DB_PORT
+	User        string `yaml:"user"`         // DB_USER
+	Password    string `yaml:"password"`     // DB_PASSWORD
+	DBName      string `yaml:"dbname"`       // DB_NAME
+	SSLMode     string `yaml:"sslmode"`      // DB_SSLMODE
+	AutoMigrate bool   `yaml:"auto_migrate"` // DB_AUTO_MIGRATE: apply pending migrations at startup
+}
+
+// PoolConfig holds connection pool configuration.
+type PoolConfig struct {
+	MinConns          int32         `yaml:"min_conns"`           // DB_MIN_CONN_SIZE
+	MaxConns          int32         `yaml:"max_conns"`           // DB_MAX_CONN_SIZE
+	MaxConnLifetime   time.Duration `yaml:"max_conn_lifetime"`   // DB_MAX_CONN_LIFETIME
+	MaxConnIdleTime   time.Duration `yaml:"max_conn_idle_time"`  // DB_MAX_CONN_IDLE_TIME
+	HealthCheckPeriod time.Duration `yaml:"health_check_period"` // DB_HEALTH_CHECK_PERIOD
+}
+
+// CollisionConfig holds request defaults for collision checks and flight plan deconfliction.
+type CollisionConfig struct {
+	DefaultDistance float64 `yaml:"collision_distance"` // COLLISION_DISTANCE: default collision_distance in meters
+	MaxDistance     float64 `yaml:"max_distance"`       // COLLISION_MAX_DISTANCE: largest collision_distance accepted
+	LookAhead       float64 `yaml:"look_ahead"`         // COLLISION_LOOK_AHEAD: default prediction horizon in seconds
+	PlanBuffer      float64 `yaml:"plan_buffer"`        // COLLISION_PLAN_BUFFER: default flight plan obstacle clearance in meters
+	// Zones is formatted as level:horizontal:vertical triples separated by commas (COLLISION_ZONES).
+	Zones string `yaml:"zones"`
+
+	HorizontalSeparation float64       `yaml:"horizontal_separation"` // DECONFLICT_HORIZONTAL_SEPARATION, meters
+	VerticalSeparation   float64       `yaml:"vertical_separation"`   // DECONFLICT_VERTICAL_SEPARATION, meters
+	TimeBuffer           time.Duration `yaml:"time_buffer"`           // DECONFLICT_TIME_BUFFER
+	SliceDuration        time.Duration `yaml:"slice_duration"`        // DECONFLICT_SLICE_DURATION
+	MaxTimeShift         time.Duration `yaml:"max_time_shift"`        // DECONFLICT_MAX_TIME_SHIFT
+	// MaxPlanDuration and MaxPlanWaypoints bound the flight plans accepted, and with
+	// them the work of deconflicting a plan while approvals are serialized
+	// (DECONFLICT_MAX_PLAN_DURATION, DECONFLICT_MAX_PLAN_WAYPOINTS).
+	MaxPlanDuration  time.Duration `yaml:"max_plan_duration"`
+	MaxPlanWaypoints int           `yaml:"max_plan_waypoints"`
+
+	// WarningZones is Zones parsed by Validate, most severe first.
+	WarningZones []model.WarningZone `yaml:"-"`
+}
+
+// LogConfig holds logging configuration.
+type LogConfig struct {
+	Level  string `yaml:"level"`  // LOG_LEVEL: debug, info, warn or error
+	Format string `yaml:"format"` // LOG_FORMAT: text or json
+	Output string `yaml:"output"` // LOG_OUTPUT: stdout, stderr or a file path
+}
+
+// AuthConfig holds API authentication settings.
+type AuthConfig struct {
+	Enabled   bool          `yaml:"enabled"`    // AUTH_ENABLED: when false every request runs as admin
+	JWKSFile  string        `yaml:"jwks_file"`  // AUTH_JWKS_FILE: local JWKS for bearer tokens, empty to accept API keys only
+	Issuer    string        `yaml:"issuer"`     // AUTH_JWT_ISSUER: required iss claim
+	Audience  string        `yaml:"audience"`   // AUTH_JWT_AUDIENCE: required aud claim
+	RoleClaim string        `yaml:"role_claim"` // AUTH_ROLE_CLAIM: claim holding the role, dotted for nested claims
+	Leeway    time.Duration `yaml:"leeway"`     // AUTH_JWT_LEEWAY: tolerated clock skew
+}
+
+// RateLimitConfig holds per-client rate limits and daily quotas. Clients are identified
+// by API key, JWT subject or, when anonymous, by IP.
+type RateLimitConfig struct {
+	Enabled    bool    `yaml:"enabled"`     // RATE_LIMIT_ENABLED
+	Rate       float64 `yaml:"rate"`        // RATE_LIMIT_RPS: requests per second for all API routes
+	Burst      int     `yaml:"burst"`       // RATE_LIMIT_BURST
+	HeavyRate  float64 `yaml:"heavy_rate"`  // RATE_LIMIT_HEAVY_RPS: additional limit per heavy route (imports, exports, route planning)
+	HeavyBurst int     `yaml:"heavy_burst"` // RATE_LIMIT_HEAVY_BURST
+	IPRate     float64 `yaml:"ip_rate"`     // RATE_LIMIT_IP_RPS: requests per second per client IP, applied before authentication
+	IPBurst    int     `yaml:"ip_burst"`    // RATE_LIMIT_IP_BURST
+	DailyQuota int64   `yaml:"daily_quota"` // RATE_LIMIT_DAILY_QUOTA: API requests per client per UTC day, 0 for unlimited
+	// QuotaFlushInterval is how often quota usage is written to the database (RATE_LIMIT_QUOTA_FLUSH_INTERVAL).
+	QuotaFlushInterval time.Duration `yaml:"quota_flush_interval"`
 }
 
-// Load loads configuration from environment variables or .env files.
+// Default returns the built-in configuration.
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Addr:            ":8800",
+			GinMode:         "debug",
+			ReadTimeout:     15 * time.Second,
+			WriteTimeout:    2 * time.Minute, // building imports run inside the request
+			IdleTimeout:     time.Minute,
+			ShutdownTimeout: 5 * time.Second,
+			DrainDelay:      5 * time.Second,
+		},
+		GRPC: GRPCConfig{
+			Enabled: true,
+			Addr:    ":9800",
+		},
+		Telemetry: TelemetryConfig{
+			Addr:             ":14550",
+			CheckInterval:    time.Second,
+			StaleAfter:       30 * time.Second,
+			AlertLog:         true,
+			AllowedSources:   []string{"127.0.0.1/32", "::1/128"},
+			GeofenceCorridor: 30,
+			WebhookTimeout:   5 * time.Second,
+		},
+		Webhooks: WebhookConfig{
+			PollInterval: 2 * time.Second,
+			BatchSize:    50,
+			Concurrency:  4,
+			MaxAttempts:  10,
+			BaseBackoff:  10 * time.Second,
+			MaxBackoff:   time.Hour,
+			Timeout:      10 * time.Second,
+		},
+		Audit: AuditConfig{
+			BufferSize:      10000,
+			MaxWait:         time.Second,
+			BatchSize:       500,
+			FlushInterval:   time.Second,
+			RetentionMonths: 12,
+			StoreResults:    true,
+		},
+		Import: ImportConfig{
+			InvalidGeometry: model.InvalidGeometryReject,
+		},
+		Database: DBConfig{
+			Host:     "localhost",
+			Port:     "5432",
+			User:     "postgres",
+			Password: "123456", // Default, consider security!
+			DBName:   "nyc",
+			SSLMode:  "disable",
+		},
+		Pool: PoolConfig{
+			MinConns:          5,
+			MaxConns:          20,
+			MaxConnLifetime:   time.Hour,
+			MaxConnIdleTime:   30 * time.Minute,
+			HealthCheckPeriod: time.Minute,
+		},
+		Collision: CollisionConfig{
+			DefaultDistance:      2,
+			MaxDistance:          100,
+			LookAhead:            30,
+			PlanBuffer:           2,
+			Zones:                DefaultWarningZones,
+			HorizontalSeparation: 50,
+			VerticalSeparation:   10,
+			TimeBuffer:           30 * time.Second,
+			SliceDuration:        10 * time.Second,
+			MaxTimeShift:         30 * time.Minute,
+			MaxPlanDuration:      4 * time.Hour,
+			MaxPlanWaypoints:     500,
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "text",
+			Output: "stdout",
+		},
+		Auth: AuthConfig{
+			Enabled:   true,
+			RoleClaim: "role",
+			Leeway:    30 * time.Second,
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:            true,
+			Rate:               20,
+			Burst:              40,
+			HeavyRate:          0.2, // one every 5 seconds
+			HeavyBurst:         3,
+			IPRate:             100,
+			IPBurst:            200,
+			DailyQuota:         100000,
+			QuotaFlushInterval: 30 * time.Second,
+		},
+	}
+}
+
+// Load builds the configuration and validates it.
 // Priority (highest to lowest):
 // 1. OS Environment Variables
 // 2. .env.{ENV} file (e.g., .env.test, .env.production) - loaded first if ENV is set
 // 3. Default .env file
-func Load() (*DBConfig, error) {
+// 4. The YAML config file: path, else CONFIG_FILE, else config.yaml if it exists
+// 5. Built-in defaults
+func Load(path string) (*Config, error) {
+	loadEnvFiles()
+
+	cfg := Default()
+
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	if path == "" {
+		if _, err := os.Stat(DefaultConfigFile); err == nil {
+			path = DefaultConfigFile
+		}
+	}
+	if path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.applyEnv(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// loadEnvFiles loads .env files into the process environment.
+func loadEnvFiles() {
 	// 1. Check for a specific environment variable (e.g., ENV)
 	//    This determines which specific .env file to load preferentially.
 	env := os.Getenv("ENV")
@@ -42,81 +350,417 @@ func Load() (*DBConfig, error) {
 		// Note: Standard practice is OS Env > File Env. godotenv.Load usually
 		// doesn't override existing OS Env Vars. Let's load specific env file first.
 		if err := godotenv.Overload(envFile); err != nil {
-			fmt.Printf("Info: No specific env file %s found or error overloading it: %v\n", envFile, err)
+			fmt.Fprintf(os.Stderr, "Info: No specific env file %s found or error overloading it: %v\n", envFile, err)
 			// It's okay if the specific env file doesn't exist
 		} else {
-			fmt.Printf("Overloaded environment variables from %s\n", envFile) // Indicate precedence
+			fmt.Fprintf(os.Stderr, "Overloaded environment variables from %s\n", envFile) // Indicate precedence
 		}
 	}
 
 	// 3. Load the default .env file SECOND (if it exists)
 	//    Values loaded here will NOT override those from .env.{ENV} (due to loading order and Overload above)
-	//    but WILL override any defaults in getEnv calls if .env.{ENV} wasn't loaded or didn't have the var.
 	//    It will also NOT override OS Environment Variables.
 	if err := godotenv.Load(); err != nil {
 		// It's okay if .env doesn't exist
-		fmt.Println("Info: No default .env file found or error loading it:", err)
+		fmt.Fprintln(os.Stderr, "Info: No default .env file found or error loading it:", err)
 	} else {
 		if env == "" {
-			fmt.Println("Loaded default .env file") // Only print this if no specific env was targeted
+			fmt.Fprintln(os.Stderr, "Loaded default .env file") // Only print this if no specific env was targeted
 		} else {
-			fmt.Println("Loaded default .env file (as fallback)")
+			fmt.Fprintln(os.Stderr, "Loaded default .env file (as fallback)")
 		}
 	}
+}
 
-	// 4. Load configuration from environment variables (including those just loaded from files)
-	//    OS Environment Variables have the highest priority implicitly (handled by os.Getenv)
-	//    If not found in OS Env, values from .env.{ENV} (if loaded and Overloaded) or .env (if loaded) are used.
-	//    If not found in any file, the hardcoded defaults are used.
-	cfg := &DBConfig{
-		Host:     getEnv("DB_HOST", "localhost"), // Default if not in any .env or OS env
-		Port:     getEnv("DB_PORT", "5432"),
-		User:     getEnv("DB_USER", "postgres"),
-		Password: getEnv("DB_PASSWORD", "123456"), // Default, consider security!
-		DBName:   getEnv("DB_NAME", "nyc"),        // Default if not set
-		SSLMode:  getEnv("DB_SSLMODE", "disable"),
+// loadFile overlays the YAML file at path onto cfg. Unknown keys are rejected so
+// that typos do not silently fall back to defaults.
+func (c *Config) loadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open config file: %w", err)
 	}
-	return cfg, nil
-}
+	defer f.Close()
 
-// getEnv gets an environment variable or returns a default value.
-// It checks OS environment variables. If a variable was loaded from a .env file
-// using godotenv.Load/Overload, it becomes part of the OS environment and will be found here.
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
 	}
-	return defaultValue
+	return nil
 }
 
-// ConnectionString builds the PostgreSQL connection string for pgxpool.
-func (c *DBConfig) ConnectionString() string {
-	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
-		c.User, c.Password, c.Host, c.Port, c.DBName, c.SSLMode)
-}
+// applyEnv overrides cfg with every environment variable that is set.
+// Malformed values are reported instead of being ignored.
+func (c *Config) applyEnv() error {
+	var errs []error
+	str := func(key string, dst *string) {
+		if v := os.Getenv(key); v != "" {
+			*dst = v
+		}
+	}
+	boolean := func(key string, dst *bool) {
+		if v := os.Getenv(key); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s=%q is not a boolean", key, v))
+				return
+			}
+			*dst = b
+		}
+	}
+	intVar := func(key string, dst *int) {
+		if v := os.Getenv(key); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s=%q is not an integer", key, v))
+				return
+			}
+			*dst = n
+		}
+	}
+	int64Var := func(key string, dst *int64) {
+		if v := os.Getenv(key); v != "" {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s=%q is not an integer", key, v))
+				return
+			}
+			*dst = n
+		}
+	}
+	int32Var := func(key string, dst *int32) {
+		if v := os.Getenv(key); v != "" {
+			n, err := strconv.ParseInt(v, 10, 32)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s=%q is not an integer", key, v))
+				return
+			}
+			*dst = int32(n)
+		}
+	}
+	float := func(key string, dst *float64) {
+		if v := os.Getenv(key); v != "" {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s=%q is not a number", key, v))
+				return
+			}
+			*dst = f
+		}
+	}
+	duration := func(key string, dst *time.Duration) {
+		if v := os.Getenv(key); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s=%q is not a duration (e.g. 5s, 1m30s)", key, v))
+				return
+			}
+			*dst = d
+		}
+	}
 
-// PoolConfig holds connection pool configuration.
-type PoolConfig struct {
-	MinConns int32
-	MaxConns int32
+	str("SERVER_ADDR", &c.Server.Addr)
+	str("GIN_MODE", &c.Server.GinMode)
+	duration("SERVER_READ_TIMEOUT", &c.Server.ReadTimeout)
+	duration("SERVER_WRITE_TIMEOUT", &c.Server.WriteTimeout)
+	duration("SERVER_IDLE_TIMEOUT", &c.Server.IdleTimeout)
+	duration("SERVER_SHUTDOWN_TIMEOUT", &c.Server.ShutdownTimeout)
+	duration("SERVER_DRAIN_DELAY", &c.Server.DrainDelay)
+
+	boolean("GRPC_ENABLED", &c.GRPC.Enabled)
+	str("GRPC_ADDR", &c.GRPC.Addr)
+	boolean("GRPC_REFLECTION", &c.GRPC.Reflection)
+
+	boolean("TELEMETRY_ENABLED", &c.Telemetry.Enabled)
+	str("TELEMETRY_ADDR", &c.Telemetry.Addr)
+	duration("TELEMETRY_CHECK_INTERVAL", &c.Telemetry.CheckInterval)
+	duration("TELEMETRY_STALE_AFTER", &c.Telemetry.StaleAfter)
+	float("TELEMETRY_DRONE_RADIUS", &c.Telemetry.DroneRadius)
+	str("TELEMETRY_RECORD_FILE", &c.Telemetry.RecordFile)
+	boolean("TELEMETRY_ALERT_LOG", &c.Telemetry.AlertLog)
+	if v, ok := os.LookupEnv("TELEMETRY_ALLOWED_SOURCES"); ok {
+		c.Telemetry.AllowedSources = nil
+		for _, src := range strings.Split(v, ",") {
+			if src = strings.TrimSpace(src); src != "" {
+				c.Telemetry.AllowedSources = append(c.Telemetry.AllowedSources, src)
+			}
+		}
+	}
+	float("TELEMETRY_GEOFENCE_CORRIDOR", &c.Telemetry.GeofenceCorridor)
+	str("TELEMETRY_ALERT_WEBHOOK", &c.Telemetry.AlertWebhook)
+	duration("TELEMETRY_WEBHOOK_TIMEOUT", &c.Telemetry.WebhookTimeout)
+
+	boolean("WEBHOOK_ENABLED", &c.Webhooks.Enabled)
+	duration("WEBHOOK_POLL_INTERVAL", &c.Webhooks.PollInterval)
+	intVar("WEBHOOK_BATCH_SIZE", &c.Webhooks.BatchSize)
+	intVar("WEBHOOK_CONCURRENCY", &c.Webhooks.Concurrency)
+	intVar("WEBHOOK_MAX_ATTEMPTS", &c.Webhooks.MaxAttempts)
+	duration("WEBHOOK_BASE_BACKOFF", &c.Webhooks.BaseBackoff)
+	duration("WEBHOOK_MAX_BACKOFF", &c.Webhooks.MaxBackoff)
+	duration("WEBHOOK_TIMEOUT", &c.Webhooks.Timeout)
+
+	boolean("AUDIT_ENABLED", &c.Audit.Enabled)
+	intVar("AUDIT_BUFFER_SIZE", &c.Audit.BufferSize)
+	duration("AUDIT_MAX_WAIT", &c.Audit.MaxWait)
+	boolean("AUDIT_DROP_WHEN_FULL", &c.Audit.DropWhenFull)
+	intVar("AUDIT_BATCH_SIZE", &c.Audit.BatchSize)
+	duration("AUDIT_FLUSH_INTERVAL", &c.Audit.FlushInterval)
+	intVar("AUDIT_RETENTION_MONTHS", &c.Audit.RetentionMonths)
+	boolean("AUDIT_STORE_RESULTS", &c.Audit.StoreResults)
+
+	str("IMPORT_INVALID_GEOMETRY", &c.Import.InvalidGeometry)
+
+	str("DB_HOST", &c.Database.Host)
+	str("DB_PORT", &c.Database.Port)
+	str("DB_USER", &c.Database.User)
+	str("DB_PASSWORD", &c.Database.Password)
+	str("DB_NAME", &c.Database.DBName)
+	str("DB_SSLMODE", &c.Database.SSLMode)
+	boolean("DB_AUTO_MIGRATE", &c.Database.AutoMigrate)
+
+	int32Var("DB_MIN_CONN_SIZE", &c.Pool.MinConns)
+	int32Var("DB_MAX_CONN_SIZE", &c.Pool.MaxConns)
+	duration("DB_MAX_CONN_LIFETIME", &c.Pool.MaxConnLifetime)
+	duration("DB_MAX_CONN_IDLE_TIME", &c.Pool.MaxConnIdleTime)
+	duration("DB_HEALTH_CHECK_PERIOD", &c.Pool.HealthCheckPeriod)
+
+	float("COLLISION_DISTANCE", &c.Collision.DefaultDistance)
+	float("COLLISION_MAX_DISTANCE", &c.Collision.MaxDistance)
+	float("COLLISION_LOOK_AHEAD", &c.Collision.LookAhead)
+	float("COLLISION_PLAN_BUFFER", &c.Collision.PlanBuffer)
+	str("COLLISION_ZONES", &c.Collision.Zones)
+	float("DECONFLICT_HORIZONTAL_SEPARATION", &c.Collision.HorizontalSeparation)
+	float("DECONFLICT_VERTICAL_SEPARATION", &c.Collision.VerticalSeparation)
+	duration("DECONFLICT_TIME_BUFFER", &c.Collision.TimeBuffer)
+	duration("DECONFLICT_SLICE_DURATION", &c.Collision.SliceDuration)
+	duration("DECONFLICT_MAX_TIME_SHIFT", &c.Collision.MaxTimeShift)
+	duration("DECONFLICT_MAX_PLAN_DURATION", &c.Collision.MaxPlanDuration)
+	intVar("DECONFLICT_MAX_PLAN_WAYPOINTS", &c.Collision.MaxPlanWaypoints)
+
+	// DEBUG=true is still honoured as a shortcut for LOG_LEVEL=debug
+	if os.Getenv("LOG_LEVEL") == "" && os.Getenv("DEBUG") == "true" {
+		c.Log.Level = "debug"
+	}
+	str("LOG_LEVEL", &c.Log.Level)
+	str("LOG_FORMAT", &c.Log.Format)
+	str("LOG_OUTPUT", &c.Log.Output)
+
+	boolean("AUTH_ENABLED", &c.Auth.Enabled)
+	str("AUTH_JWKS_FILE", &c.Auth.JWKSFile)
+	str("AUTH_JWT_ISSUER", &c.Auth.Issuer)
+	str("AUTH_JWT_AUDIENCE", &c.Auth.Audience)
+	str("AUTH_ROLE_CLAIM", &c.Auth.RoleClaim)
+	duration("AUTH_JWT_LEEWAY", &c.Auth.Leeway)
+
+	boolean("RATE_LIMIT_ENABLED", &c.RateLimit.Enabled)
+	float("RATE_LIMIT_RPS", &c.RateLimit.Rate)
+	intVar("RATE_LIMIT_BURST", &c.RateLimit.Burst)
+	float("RATE_LIMIT_HEAVY_RPS", &c.RateLimit.HeavyRate)
+	intVar("RATE_LIMIT_HEAVY_BURST", &c.RateLimit.HeavyBurst)
+	float("RATE_LIMIT_IP_RPS", &c.RateLimit.IPRate)
+	intVar("RATE_LIMIT_IP_BURST", &c.RateLimit.IPBurst)
+	int64Var("RATE_LIMIT_DAILY_QUOTA", &c.RateLimit.DailyQuota)
+	duration("RATE_LIMIT_QUOTA_FLUSH_INTERVAL", &c.RateLimit.QuotaFlushInterval)
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid environment:\n%w", errors.Join(errs...))
+	}
+	return nil
 }
 
-// LoadPoolConfig loads pool configuration from environment variables.
-func LoadPoolConfig() *PoolConfig {
-	return &PoolConfig{
-		MinConns: int32(getEnvInt("DB_MIN_CONN_SIZE", 5)),  // Default if not set
-		MaxConns: int32(getEnvInt("DB_MAX_CONN_SIZE", 20)), // Default if not set
+// Validate checks every setting and reports all problems at once.
+// It also parses Collision.Zones into Collision.WarningZones.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	s := c.Server
+	if _, _, err := net.SplitHostPort(s.Addr); err != nil {
+		errs = append(errs, fmt.Errorf("server.addr %q must be host:port (e.g. :8800): %v", s.Addr, err))
+	}
+	check(s.GinMode == "debug" || s.GinMode == "release" || s.GinMode == "test",
+		"server.gin_mode %q must be debug, release or test", s.GinMode)
+	check(s.ReadTimeout >= 0, "server.read_timeout must not be negative")
+	check(s.WriteTimeout >= 0, "server.write_timeout must not be negative")
+	check(s.IdleTimeout >= 0, "server.idle_timeout must not be negative")
+	check(s.ShutdownTimeout > 0, "server.shutdown_timeout must be positive")
+	check(s.DrainDelay >= 0, "server.drain_delay must not be negative")
+
+	if g := c.GRPC; g.Enabled {
+		if _, _, err := net.SplitHostPort(g.Addr); err != nil {
+			errs = append(errs, fmt.Errorf("grpc.addr %q must be host:port (e.g. :9800): %v", g.Addr, err))
+		}
+		check(g.Addr != s.Addr, "grpc.addr must differ from server.addr")
+	}
+
+	if t := c.Telemetry; t.Enabled {
+		if _, _, err := net.SplitHostPort(t.Addr); err != nil {
+			errs = append(errs, fmt.Errorf("telemetry.addr %q must be host:port (e.g. :14550): %v", t.Addr, err))
+		}
+		check(t.CheckInterval >= 0, "telemetry.check_interval must not be negative")
+		check(t.StaleAfter > 0, "telemetry.stale_after must be positive")
+		check(t.DroneRadius >= 0, "telemetry.drone_radius must not be negative")
+		check(t.GeofenceCorridor >= 0, "telemetry.geofence_corridor must not be negative")
+		if _, err := t.SourceNetworks(); err != nil {
+			errs = append(errs, err)
+		}
+		if t.AlertWebhook != "" {
+			if u, err := url.Parse(t.AlertWebhook); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				errs = append(errs, fmt.Errorf("telemetry.alert_webhook %q must be an http(s) URL", t.AlertWebhook))
+			}
+			check(t.WebhookTimeout > 0, "telemetry.webhook_timeout must be positive")
+		}
+	}
+
+	if w := c.Webhooks; w.Enabled {
+		check(w.PollInterval > 0, "webhooks.poll_interval must be positive")
+		check(w.BatchSize > 0, "webhooks.batch_size must be positive")
+		check(w.Concurrency > 0, "webhooks.concurrency must be positive")
+		check(w.MaxAttempts > 0, "webhooks.max_attempts must be positive")
+		check(w.BaseBackoff > 0, "webhooks.base_backoff must be positive")
+		check(w.MaxBackoff >= w.BaseBackoff, "webhooks.max_backoff must be >= webhooks.base_backoff")
+		check(w.Timeout > 0, "webhooks.timeout must be positive")
+	}
+
+	if au := c.Audit; au.Enabled {
+		check(au.BufferSize > 0, "audit.buffer_size must be positive")
+		check(au.MaxWait >= 0, "audit.max_wait must not be negative")
+		check(au.BatchSize > 0, "audit.batch_size must be positive")
+		check(au.FlushInterval > 0, "audit.flush_interval must be positive")
+		check(au.RetentionMonths >= 0, "audit.retention_months must not be negative")
+	}
+
+	check(slices.Contains(model.InvalidGeometryPolicies, c.Import.InvalidGeometry),
+		"import.invalid_geometry %q must be one of %s", c.Import.InvalidGeometry, strings.Join(model.InvalidGeometryPolicies, ", "))
+
+	d := c.Database
+	check(d.Host != "", "database.host must not be empty")
+	if port, err := strconv.Atoi(d.Port); err != nil || port < 1 || port > 65535 {
+		errs = append(errs, fmt.Errorf("database.port %q must be a number between 1 and 65535", d.Port))
+	}
+	check(d.User != "", "database.user must not be empty")
+	check(d.DBName != "", "database.dbname must not be empty")
+	switch d.SSLMode {
+	case "disable", "allow", "prefer", "require", "verify-ca", "verify-full":
+	default:
+		errs = append(errs, fmt.Errorf("database.sslmode %q must be one of disable, allow, prefer, require, verify-ca, verify-full", d.SSLMode))
+	}
+
+	p := c.Pool
+	check(p.MaxConns >= 1, "pool.max_conns must be at least 1")
+	check(p.MinConns >= 0, "pool.min_conns must not be negative")
+	check(p.MinConns <= p.MaxConns, "pool.min_conns (%d) must not exceed pool.max_conns (%d)", p.MinConns, p.MaxConns)
+	check(p.MaxConnLifetime > 0, "pool.max_conn_lifetime must be positive")
+	check(p.MaxConnIdleTime > 0, "pool.max_conn_idle_time must be positive")
+	check(p.HealthCheckPeriod > 0, "pool.health_check_period must be positive")
+
+	col := c.Collision
+	check(col.DefaultDistance >= 0, "collision.collision_distance must not be negative")
+	check(col.MaxDistance > 0, "collision.max_distance must be positive")
+	check(col.DefaultDistance <= col.MaxDistance, "collision.collision_distance (%g) must not exceed collision.max_distance (%g)", col.DefaultDistance, col.MaxDistance)
+	check(col.LookAhead > 0, "collision.look_ahead must be positive")
+	check(col.PlanBuffer >= 0, "collision.plan_buffer must not be negative")
+	check(col.HorizontalSeparation > 0, "collision.horizontal_separation must be positive")
+	check(col.VerticalSeparation > 0, "collision.vertical_separation must be positive")
+	check(col.TimeBuffer >= 0, "collision.time_buffer must not be negative")
+	check(col.SliceDuration > 0, "collision.slice_duration must be positive")
+	check(col.MaxTimeShift >= 0, "collision.max_time_shift must not be negative")
+	check(col.MaxPlanDuration > 0, "collision.max_plan_duration must be positive")
+	check(col.MaxPlanWaypoints >= 2 && col.MaxPlanWaypoints <= model.MaxRouteWaypoints,
+		"collision.max_plan_waypoints must be between 2 and %d", model.MaxRouteWaypoints)
+	zones, err := model.ParseWarningZones(col.Zones)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("collision.zones: %w", err))
+	}
+	c.Collision.WarningZones = zones
+
+	l := c.Log
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(l.Level)); err != nil {
+		errs = append(errs, fmt.Errorf("log.level %q must be debug, info, warn or error", l.Level))
 	}
+	format := strings.ToLower(l.Format)
+	check(format == "text" || format == "json", "log.format %q must be text or json", l.Format)
+	check(l.Output != "", "log.output must not be empty")
+
+	a := c.Auth
+	if a.JWKSFile != "" {
+		if _, err := os.Stat(a.JWKSFile); err != nil {
+			errs = append(errs, fmt.Errorf("auth.jwks_file: %v", err))
+		}
+		check(a.RoleClaim != "", "auth.role_claim must not be empty when auth.jwks_file is set")
+	}
+	check(a.Leeway >= 0, "auth.leeway must not be negative")
+
+	rl := c.RateLimit
+	if rl.Enabled {
+		check(rl.Rate > 0, "rate_limit.rate must be positive")
+		check(rl.Burst >= 1, "rate_limit.burst must be at least 1")
+		check(rl.HeavyRate > 0, "rate_limit.heavy_rate must be positive")
+		check(rl.HeavyBurst >= 1, "rate_limit.heavy_burst must be at least 1")
+		check(rl.IPRate > 0, "rate_limit.ip_rate must be positive")
+		check(rl.IPBurst >= 1, "rate_limit.ip_burst must be at least 1")
+		check(rl.DailyQuota >= 0, "rate_limit.daily_quota must not be negative")
+		check(rl.QuotaFlushInterval > 0, "rate_limit.quota_flush_interval must be positive")
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration:\n%w", errors.Join(errs...))
+	}
+	return nil
 }
 
-// getEnvInt gets an environment variable as an integer or returns a default value.
-func getEnvInt(key string, defaultValue int) int {
-	if valueStr := os.Getenv(key); valueStr != "" {
-		if val, err := strconv.Atoi(valueStr); err == nil {
-			return val
+// SourceNetworks parses AllowedSources. A plain IP address allows that host only.
+func (c TelemetryConfig) SourceNetworks() ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(c.AllowedSources))
+	for _, src := range c.AllowedSources {
+		if ip := net.ParseIP(src); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
 		}
-		// Log warning if parsing fails?
-		fmt.Printf("Warning: Failed to parse %s='%s' as integer, using default %d\n", key, valueStr, defaultValue)
+		_, network, err := net.ParseCIDR(src)
+		if err != nil {
+			return nil, fmt.Errorf("telemetry.allowed_sources %q must be an IP address or CIDR network", src)
+		}
+		networks = append(networks, network)
 	}
-	return defaultValue
+	return networks, nil
+}
+
+// ConnectionString builds the PostgreSQL connection string for pgxpool without the
+// password, so that it is safe to log. Set the password on the parsed config instead.
+func (c *DBConfig) ConnectionString() string {
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.User(c.User),
+		Host:     net.JoinHostPort(c.Host, c.Port),
+		Path:     "/" + c.DBName,
+		RawQuery: url.Values{"sslmode": {c.SSLMode}}.Encode(),
+	}
+	return u.String()
+}
+
+// LogValue implements slog.LogValuer so that logging a DBConfig never includes the password.
+func (c DBConfig) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("host", c.Host),
+		slog.String("port", c.Port),
+		slog.String("user", c.User),
+		slog.String("dbname", c.DBName),
+		slog.String("sslmode", c.SSLMode),
+		slog.Bool("auto_migrate", c.AutoMigrate),
+	)
+}
+
+// String redacts the password when the config is formatted with %v.
+func (c DBConfig) String() string {
+	return c.ConnectionString()
 }
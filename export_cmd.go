@@ -0,0 +1,93 @@
+// export_cmd.go
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+
+	"collision_app_go/internal/export"
+	"collision_app_go/internal/model"
+	"collision_app_go/internal/repository"
+	"collision_app_go/internal/service"
+)
+
+// runExport writes the buildings matching the filters in one of the export formats.
+// The default, WKT,height lines, is the same format import reads.
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	output := fs.String("o", "", "output file (default stdout)")
+	format := fs.String("format", model.ExportWKT, fmt.Sprintf("output format: one of %v", model.ExportFormats))
+	bbox := fs.String("bbox", "", "only buildings intersecting minLon,minLat,maxLon,maxLat")
+	areaCode := fs.String("area-code", "", "only buildings with this area code")
+	buildingType := fs.String("type", "", "only buildings of this type")
+	asOf := fs.String("as-of", "", "export the buildings as they were at this RFC 3339 time")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if !slices.Contains(model.ExportFormats, *format) {
+		return fmt.Errorf("-format must be one of %v", model.ExportFormats)
+	}
+	filter := model.BuildingFilter{AreaCode: *areaCode, Type: *buildingType}
+	if *bbox != "" {
+		var err error
+		if filter.BBox, err = model.ParseBBox(*bbox); err != nil {
+			return fmt.Errorf("-bbox: %w", err)
+		}
+	}
+	var at time.Time
+	if *asOf != "" {
+		var err error
+		if at, err = time.Parse(time.RFC3339, *asOf); err != nil {
+			return fmt.Errorf("-as-of must be an RFC 3339 time: %w", err)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	a, err := newApp(ctx, true)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	var out io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	w, err := export.New(*format, out)
+	if err != nil {
+		return err
+	}
+
+	count := 0
+	buildingsService := service.NewBuildingsService(repository.NewBuildingRepository(a.dbpool))
+	if !at.IsZero() {
+		buildingsService = buildingsService.AsOf(at)
+	}
+	err = buildingsService.ExportBuildings(ctx, filter, func(b model.BuildingRecord) error {
+		count++
+		return w.Write(b)
+	})
+	if err != nil {
+		w.Abort()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d building(s) as %s\n", count, *format)
+	return nil
+}
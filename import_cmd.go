@@ -0,0 +1,96 @@
+// import_cmd.go
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+
+	"collision_app_go/internal/model"
+	"collision_app_go/internal/repository"
+	"collision_app_go/internal/service"
+)
+
+// runImport imports a building file offline, showing a progress bar on stderr, with
+// only warnings and errors logged to the terminal meanwhile. With -dry-run it prints
+// what the import would do instead, writing nothing.
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	quiet := fs.Bool("quiet", false, "do not show the progress bar")
+	invalidGeometry := fs.String("invalid-geometry", "", "lines with an invalid footprint: reject, repair or quarantine (default from config)")
+	dryRun := fs.Bool("dry-run", false, "validate the file and report what the import would do, without writing")
+	reportFile := fs.String("report", "", "with -dry-run, write the report to this file instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: import [-quiet] [-invalid-geometry reject|repair|quarantine] [-dry-run [-report file]] <file>")
+	}
+	if *invalidGeometry != "" && !slices.Contains(model.InvalidGeometryPolicies, *invalidGeometry) {
+		return fmt.Errorf("-invalid-geometry must be one of %v", model.InvalidGeometryPolicies)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	a, err := newApp(ctx, true)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	var progress repository.ProgressFunc
+	if !*quiet {
+		if err := a.quietLogs(); err != nil {
+			return err
+		}
+		progress = newProgressBar(os.Stderr).Update
+	}
+
+	opts := model.ImportOptions{InvalidGeometry: a.cfg.Import.InvalidGeometry}
+	if *invalidGeometry != "" {
+		opts.InvalidGeometry = *invalidGeometry
+	}
+
+	buildingsService := service.NewBuildingsService(repository.NewBuildingRepository(a.dbpool))
+	if *dryRun {
+		report, err := buildingsService.DryRunImport(ctx, fs.Arg(0), opts, progress)
+		if err != nil {
+			return err
+		}
+		out := os.Stdout
+		if *reportFile != "" {
+			if out, err = os.Create(*reportFile); err != nil {
+				return err
+			}
+			defer out.Close()
+		}
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+		if *reportFile != "" {
+			fmt.Fprintf(os.Stderr, "Dry run: %d line(s) would be imported, %d not; %d duplicate(s). Report written to %s\n",
+				report.SuccessCount, report.ErrorCount, report.DuplicateCount, *reportFile)
+		}
+		return nil
+	}
+
+	result, err := buildingsService.InsertBuildings(ctx, fs.Arg(0), opts, progress)
+	if err != nil {
+		return err
+	}
+	// Queued in the outbox and delivered by the server's webhook dispatcher
+	a.webhookService().Publish(ctx, model.EventImportFinished, model.ImportEvent{Source: "cli", FilePath: fs.Arg(0), Result: *result})
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(result)
+}
@@ -0,0 +1,78 @@
+// internal/apperr/apperr.go
+package apperr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Error is an error classified with a catalogued code. Args fill the code's message
+// template; Details is optional structured information returned to the client.
+// The wrapped cause is logged but never sent to clients.
+type Error struct {
+	Code    Code
+	Args    []any
+	Details any
+	Err     error
+}
+
+// New returns an error with the given code and message arguments.
+func New(code Code, args ...any) *Error {
+	return &Error{Code: code, Args: args}
+}
+
+// Wrap classifies err. An err that already carries a code (or a context deadline)
+// keeps its classification; anything else is reported as code.
+func Wrap(code Code, err error) *Error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return &Error{Code: CodeTimeout, Err: err}
+	}
+	return &Error{Code: code, Err: err}
+}
+
+// From classifies any error, defaulting to CodeInternal.
+func From(err error) *Error {
+	return Wrap(CodeInternal, err)
+}
+
+// InvalidArgument reports a bad request parameter. rule and args name the violated
+// validation rule (see Rule); the reason is localized when the error is rendered.
+func InvalidArgument(name, rule string, args ...any) *Error {
+	return New(CodeInvalidArgument, name, RuleMessage{Rule: rule, Args: args})
+}
+
+// WithDetails attaches structured details returned to the client.
+func (e *Error) WithDetails(details any) *Error {
+	e.Details = details
+	return e
+}
+
+func (e *Error) Error() string {
+	msg := Message(e.Code, LangEN, e.Args...)
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, msg, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, msg)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Status returns the HTTP status of the error's code.
+func (e *Error) Status() int {
+	return HTTPStatus(e.Code)
+}
+
+// Message returns the error's client-facing message in lang.
+func (e *Error) Message(lang Lang) string {
+	return Message(e.Code, lang, e.Args...)
+}
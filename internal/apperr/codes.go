@@ -0,0 +1,119 @@
+// internal/apperr/codes.go
+package apperr
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// Code is a stable, machine-readable error code. Codes are part of the API contract:
+// add new ones freely, but never rename or repurpose an existing code.
+type Code string
+
+const (
+	CodeInvalidArgument         Code = "INVALID_ARGUMENT"
+	CodeUnauthenticated         Code = "UNAUTHENTICATED"
+	CodePermissionDenied        Code = "PERMISSION_DENIED"
+	CodeRouteNotFound           Code = "ROUTE_NOT_FOUND"
+	CodeFlightPlanNotFound      Code = "FLIGHT_PLAN_NOT_FOUND"
+	CodeFlightPlanSelfReview    Code = "FLIGHT_PLAN_SELF_REVIEW"
+	CodeFlightPlanNotReviewable Code = "FLIGHT_PLAN_NOT_REVIEWABLE"
+	CodeFlightPlanConflict      Code = "FLIGHT_PLAN_CONFLICT"
+	CodeWebhookNotFound         Code = "WEBHOOK_NOT_FOUND"
+	CodePayloadTooLarge         Code = "PAYLOAD_TOO_LARGE"
+	CodeRateLimited             Code = "RATE_LIMITED"
+	CodeQuotaExceeded           Code = "QUOTA_EXCEEDED"
+	CodeImportFileUnreadable    Code = "IMPORT_FILE_UNREADABLE"
+	CodeTimeout                 Code = "TIMEOUT"
+	CodeInternal                Code = "INTERNAL"
+
+	// Operation failures with an unclassified cause (typically the database).
+	CodeCollisionCheckFailed Code = "COLLISION_CHECK_FAILED"
+	CodeZoneCheckFailed      Code = "ZONE_CHECK_FAILED"
+	CodeLineOfSightFailed    Code = "LINE_OF_SIGHT_FAILED"
+	CodePredictionFailed     Code = "PREDICTION_FAILED"
+	CodeImportFailed         Code = "IMPORT_FAILED"
+	CodeUpdateFailed         Code = "UPDATE_FAILED"
+	CodeExportFailed         Code = "EXPORT_FAILED"
+	CodeFlightPlanFailed     Code = "FLIGHT_PLAN_FAILED"
+	CodeWebhookFailed        Code = "WEBHOOK_FAILED"
+)
+
+// entry is the catalogue record of one code: its HTTP status and message templates.
+// Templates are fmt formats applied to the error's Args.
+type entry struct {
+	status int
+	zh     string
+	en     string
+}
+
+var catalogue = map[Code]entry{
+	CodeInvalidArgument:         {http.StatusBadRequest, "参数 %s 无效: %s", "Invalid %s: %s"},
+	CodeUnauthenticated:         {http.StatusUnauthorized, "缺少或无效的认证信息, 请提供 X-API-Key 或 Authorization: Bearer", "Missing or invalid credentials: send X-API-Key or Authorization: Bearer"},
+	CodePermissionDenied:        {http.StatusForbidden, "需要 %s 角色", "Role %s required"},
+	CodeRouteNotFound:           {http.StatusNotFound, "接口不存在", "No such endpoint"},
+	CodeFlightPlanNotFound:      {http.StatusNotFound, "飞行计划不存在", "Flight plan not found"},
+	CodeFlightPlanSelfReview:    {http.StatusForbidden, "不能审批自己提交的飞行计划", "A flight plan cannot be reviewed by its submitter"},
+	CodeFlightPlanNotReviewable: {http.StatusConflict, "飞行计划状态为 %s, 只能审批待审核的计划", "Flight plan is %s, only plans needing review can be reviewed"},
+	CodeFlightPlanConflict:      {http.StatusConflict, "飞行计划与已批准的计划 %d 冲突", "Flight plan conflicts with approved plan %d"},
+	CodeWebhookNotFound:         {http.StatusNotFound, "Webhook 订阅不存在", "Webhook subscription not found"},
+	CodePayloadTooLarge:         {http.StatusRequestEntityTooLarge, "请求体过大", "Request body too large"},
+	CodeRateLimited:             {http.StatusTooManyRequests, "请求过于频繁, 请 %d 秒后重试", "Rate limit exceeded, retry in %d s"},
+	CodeQuotaExceeded:           {http.StatusTooManyRequests, "已超出每日请求配额", "Daily quota exceeded"},
+	CodeImportFileUnreadable:    {http.StatusUnprocessableEntity, "导入文件不存在或无法读取", "Import file not found or unreadable"},
+	CodeTimeout:                 {http.StatusGatewayTimeout, "请求处理超时", "Request timed out"},
+	CodeInternal:                {http.StatusInternalServerError, "服务器内部错误", "Internal server error"},
+
+	CodeCollisionCheckFailed: {http.StatusInternalServerError, "检测碰撞时发生错误", "Collision check failed"},
+	CodeZoneCheckFailed:      {http.StatusInternalServerError, "检测告警区域时发生错误", "Warning zone check failed"},
+	CodeLineOfSightFailed:    {http.StatusInternalServerError, "检测通视时发生错误", "Line of sight check failed"},
+	CodePredictionFailed:     {http.StatusInternalServerError, "预测碰撞时发生错误", "Collision prediction failed"},
+	CodeImportFailed:         {http.StatusInternalServerError, "导入建筑物信息发生错误", "Building import failed"},
+	CodeUpdateFailed:         {http.StatusInternalServerError, "更新建筑物时发生错误", "Building update failed"},
+	CodeExportFailed:         {http.StatusInternalServerError, "导出建筑物时发生错误", "Building export failed"},
+	CodeFlightPlanFailed:     {http.StatusInternalServerError, "处理飞行计划时发生错误", "Flight plan processing failed"},
+	CodeWebhookFailed:        {http.StatusInternalServerError, "处理 Webhook 订阅时发生错误", "Webhook subscription processing failed"},
+}
+
+// HTTPStatus returns the HTTP status for code (500 for unknown codes).
+func HTTPStatus(code Code) int {
+	if e, ok := catalogue[code]; ok {
+		return e.status
+	}
+	return http.StatusInternalServerError
+}
+
+// Message returns the localized message for code with args substituted.
+func Message(code Code, lang Lang, args ...any) string {
+	e, ok := catalogue[code]
+	if !ok {
+		e = catalogue[CodeInternal]
+		args = nil
+	}
+	tmpl := e.zh
+	if lang == LangEN {
+		tmpl = e.en
+	}
+	if len(args) == 0 {
+		return tmpl
+	}
+	localized := make([]any, len(args))
+	for i, arg := range args {
+		if l, ok := arg.(Localizer); ok {
+			arg = l.Localize(lang)
+		}
+		localized[i] = arg
+	}
+	return fmt.Sprintf(tmpl, localized...)
+}
+
+// Codes returns every catalogued code, sorted.
+func Codes() []string {
+	codes := make([]string, 0, len(catalogue))
+	for c := range catalogue {
+		codes = append(codes, string(c))
+	}
+	sort.Strings(codes)
+	return codes
+}
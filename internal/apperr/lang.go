@@ -0,0 +1,112 @@
+// internal/apperr/lang.go
+package apperr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Lang is a supported response language.
+type Lang string
+
+const (
+	LangZH Lang = "zh"
+	LangEN Lang = "en"
+)
+
+// DefaultLang is used when Accept-Language names no supported language.
+const DefaultLang = LangZH
+
+// Negotiate picks the response language from an Accept-Language header value,
+// honouring q-values (e.g. "en-US,en;q=0.9,zh;q=0.8" selects English).
+func Negotiate(acceptLanguage string) Lang {
+	best, bestQ := DefaultLang, 0.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		primary, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		var lang Lang
+		switch primary {
+		case "zh":
+			lang = LangZH
+		case "en":
+			lang = LangEN
+		default:
+			continue
+		}
+		if q > bestQ {
+			best, bestQ = lang, q
+		}
+	}
+	return best
+}
+
+// Localizer is a message argument rendered in the response language.
+type Localizer interface {
+	Localize(lang Lang) string
+}
+
+// RuleMessage is a validation rule with its arguments, localized on rendering.
+type RuleMessage struct {
+	Rule string
+	Args []any
+}
+
+func (m RuleMessage) Localize(lang Lang) string {
+	return Rule(lang, m.Rule, m.Args...)
+}
+
+// Rule messages describe why a parameter is invalid. They are used as the reason of
+// InvalidArgument and in validation details.
+var rules = map[string][2]string{ // rule -> {zh, en}
+	"required":      {"必填", "is required"},
+	"number":        {"必须为数字", "must be a number"},
+	"integer":       {"必须为整数", "must be an integer"},
+	"boolean":       {"必须为 true 或 false", "must be true or false"},
+	"string":        {"必须为字符串", "must be a string"},
+	"object":        {"必须为对象", "must be an object"},
+	"array":         {"必须为数组", "must be an array"},
+	"min":           {"必须 >= %v", "must be >= %v"},
+	"exclusive_min": {"必须 > %v", "must be > %v"},
+	"max":           {"必须 <= %v", "must be <= %v"},
+	"exclusive_max": {"必须 < %v", "must be < %v"},
+	"range":         {"必须在 %v 范围内", "must be in %v"},
+	"min_length":    {"长度至少为 %v", "must be at least %v characters"},
+	"max_length":    {"长度至多为 %v", "must be at most %v characters"},
+	"min_items":     {"至少包含 %v 项", "must have at least %v items"},
+	"max_items":     {"至多包含 %v 项", "must have at most %v items"},
+	"enum":          {"必须为以下之一: %v", "must be one of %v"},
+	"date_time":     {"必须为 RFC 3339 时间", "must be an RFC 3339 date-time"},
+	"unknown_field": {"不是已知字段", "is not a known field"},
+	"json":          {"不是合法的 JSON: %v", "is not valid JSON: %v"},
+	"gt_field":      {"必须大于 %v", "must be greater than %v"},
+	"not_negative":  {"不能为负数", "must not be negative"},
+	"format":        {"格式错误: %v", "is malformed: %v"},
+	"not_with":      {"不能与 %v 同时使用", "cannot be used together with %v"},
+	"invalid":       {"无效", "is invalid"},
+}
+
+// Rule returns the localized message of a validation rule with args substituted.
+// Unknown rules fall back to "invalid".
+func Rule(lang Lang, rule string, args ...any) string {
+	msgs, ok := rules[rule]
+	if !ok {
+		msgs, args = rules["invalid"], nil
+	}
+	tmpl := msgs[0]
+	if lang == LangEN {
+		tmpl = msgs[1]
+	}
+	if len(args) == 0 {
+		return tmpl
+	}
+	return fmt.Sprintf(tmpl, args...)
+}
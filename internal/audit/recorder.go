@@ -0,0 +1,149 @@
+// internal/audit/recorder.go
+// Package audit writes the collision check audit log in the background.
+package audit
+
+import (
+	"context"
+	"time"
+
+	"collision_app_go/internal/metrics"
+	"collision_app_go/internal/model"
+	"collision_app_go/internal/repository"
+	"collision_app_go/utils"
+)
+
+// maintenanceInterval is how often partitions are created ahead and expired ones
+// dropped.
+const maintenanceInterval = 12 * time.Hour
+
+// Options configures a Recorder.
+type Options struct {
+	BufferSize      int           // entries held before Record waits
+	MaxWait         time.Duration // longest Record waits for room before dropping an entry
+	DropWhenFull    bool          // drop entries at once when the buffer is full instead of waiting
+	BatchSize       int           // entries written per COPY
+	FlushInterval   time.Duration // longest an entry waits before it is written
+	RetentionMonths int           // months of partitions kept, 0 keeps all
+	StoreResults    bool          // keep the full answer besides its outcome
+}
+
+// Recorder buffers audit entries and writes them in batches. It implements
+// service.Auditor. When the buffer is full, Record waits up to MaxWait for room, so
+// a slow database costs request latency before it costs entries; with DropWhenFull
+// it drops entries at once instead.
+type Recorder struct {
+	repo    *repository.AuditRepository
+	opts    Options
+	entries chan model.AuditEntry
+	now     func() time.Time
+}
+
+func NewRecorder(repo *repository.AuditRepository, opts Options) *Recorder {
+	return &Recorder{
+		repo:    repo,
+		opts:    opts,
+		entries: make(chan model.AuditEntry, opts.BufferSize),
+		now:     time.Now,
+	}
+}
+
+// Record queues entry for writing. When the buffer is full it waits up to MaxWait,
+// or not at all with DropWhenFull, then drops the entry with a warning.
+func (r *Recorder) Record(entry model.AuditEntry) {
+	if !r.opts.StoreResults {
+		entry.Result = nil
+	}
+	select {
+	case r.entries <- entry:
+		return
+	default:
+	}
+	if !r.opts.DropWhenFull && r.opts.MaxWait > 0 {
+		timer := time.NewTimer(r.opts.MaxWait)
+		defer timer.Stop()
+		select {
+		case r.entries <- entry:
+			return
+		case <-timer.C:
+		}
+	}
+	metrics.AuditEntries.WithLabelValues("dropped").Inc()
+	utils.Warn(utils.WithRequestID(context.Background(), entry.RequestID), "audit buffer full, dropping entry",
+		"check", entry.Check, "source", entry.Source, "buffer_size", r.opts.BufferSize)
+}
+
+// Run writes queued entries until ctx is cancelled, then writes what is left in the
+// buffer. It also keeps the monthly partitions ahead of the clock and drops those
+// past the retention period.
+func (r *Recorder) Run(ctx context.Context) {
+	ctx = utils.WithRequestID(ctx, "audit-recorder")
+	r.maintain(ctx)
+
+	flush := time.NewTicker(r.opts.FlushInterval)
+	defer flush.Stop()
+	maintenance := time.NewTicker(maintenanceInterval)
+	defer maintenance.Stop()
+
+	batch := make([]model.AuditEntry, 0, r.opts.BatchSize)
+	for {
+		select {
+		case <-ctx.Done():
+			drainCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+			defer cancel()
+			for {
+				select {
+				case entry := <-r.entries:
+					if batch = append(batch, entry); len(batch) == r.opts.BatchSize {
+						batch = r.write(drainCtx, batch)
+					}
+				default:
+					r.write(drainCtx, batch)
+					return
+				}
+			}
+		case entry := <-r.entries:
+			if batch = append(batch, entry); len(batch) == r.opts.BatchSize {
+				batch = r.write(ctx, batch)
+			}
+		case <-flush.C:
+			batch = r.write(ctx, batch)
+		case <-maintenance.C:
+			r.maintain(ctx)
+		}
+	}
+}
+
+// write inserts batch and returns it emptied for reuse. A failed batch is counted
+// and discarded.
+func (r *Recorder) write(ctx context.Context, batch []model.AuditEntry) []model.AuditEntry {
+	if len(batch) == 0 {
+		return batch
+	}
+	if err := r.repo.InsertAuditEntries(ctx, batch); err != nil {
+		utils.Warn(ctx, "failed to write audit entries", "count", len(batch), "error", err)
+		metrics.AuditEntries.WithLabelValues("failed").Add(float64(len(batch)))
+	} else {
+		metrics.AuditEntries.WithLabelValues("written").Add(float64(len(batch)))
+	}
+	return batch[:0]
+}
+
+// maintain creates the partitions for this month and the next, and drops the
+// partitions that ended more than RetentionMonths ago.
+func (r *Recorder) maintain(ctx context.Context) {
+	now := r.now().UTC()
+	if err := r.repo.EnsureAuditPartitions(ctx, now, 1); err != nil {
+		utils.Warn(ctx, "failed to create audit partitions", "error", err)
+	}
+	if r.opts.RetentionMonths == 0 {
+		return
+	}
+	cutoff := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -r.opts.RetentionMonths, 0)
+	dropped, err := r.repo.DropAuditPartitionsBefore(ctx, cutoff)
+	if err != nil {
+		utils.Warn(ctx, "failed to drop expired audit partitions", "error", err)
+	}
+	if len(dropped) > 0 {
+		utils.Info(ctx, "dropped expired audit partitions", "partitions", dropped, "retention_months", r.opts.RetentionMonths)
+	}
+}
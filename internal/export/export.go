@@ -0,0 +1,93 @@
+// internal/export/export.go
+// Package export writes buildings in the supported exchange formats. Writers
+// stream: rows are written as they arrive, and the formats that need a complete
+// file before it can be sent (Shapefile and GeoPackage) are built in a temporary
+// directory rather than in memory.
+package export
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"collision_app_go/internal/geom"
+	"collision_app_go/internal/model"
+)
+
+// Layer is the name of the exported layer, used for file and table names.
+const Layer = "buildings"
+
+// Writer writes buildings in one format.
+type Writer interface {
+	// Write adds one building.
+	Write(b model.BuildingRecord) error
+	// Close completes the output. An export is not valid until Close returns nil.
+	Close() error
+	// Abort releases the writer after a failed export without completing the
+	// output. It does nothing after Close.
+	Abort()
+}
+
+// New returns a writer of format to w.
+func New(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case model.ExportWKT:
+		return newWKTWriter(w), nil
+	case model.ExportGeoJSON:
+		return newGeoJSONWriter(w, false), nil
+	case model.ExportNDJSON:
+		return newGeoJSONWriter(w, true), nil
+	case model.ExportCSV:
+		return newCSVWriter(w), nil
+	case model.ExportShapefile:
+		return newShapefileWriter(w)
+	case model.ExportGeoPackage:
+		return newGeoPackageWriter(w)
+	}
+	return nil, fmt.Errorf("unknown export format %q", format)
+}
+
+// ContentType returns the media type of format.
+func ContentType(format string) string {
+	switch format {
+	case model.ExportGeoJSON:
+		return "application/geo+json"
+	case model.ExportNDJSON:
+		return "application/x-ndjson"
+	case model.ExportCSV:
+		return "text/csv; charset=utf-8"
+	case model.ExportShapefile:
+		return "application/zip"
+	case model.ExportGeoPackage:
+		return "application/geopackage+sqlite3"
+	}
+	return "text/plain; charset=utf-8"
+}
+
+// FileName returns the download file name of format.
+func FileName(format string) string {
+	switch format {
+	case model.ExportShapefile:
+		return Layer + ".zip"
+	case model.ExportWKT:
+		return Layer + ".txt"
+	}
+	return Layer + "." + format
+}
+
+// footprint decodes the building's geometry; it returns nil for rows without one.
+func footprint(b model.BuildingRecord) (geom.MultiPolygon, error) {
+	if b.WKB == nil {
+		return nil, nil
+	}
+	m, err := geom.ParseWKB(b.WKB)
+	if err != nil {
+		return nil, fmt.Errorf("building %d: %w", b.BuildingID, err)
+	}
+	return m, nil
+}
+
+// formatTime formats the timestamps of exported rows.
+func formatTime(t time.Time) string {
+	return t.Format(time.RFC3339)
+}
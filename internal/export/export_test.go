@@ -0,0 +1,95 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"collision_app_go/internal/geom"
+	"collision_app_go/internal/model"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+// testBuilding is an exported building together with its footprint.
+type testBuilding struct {
+	record    model.BuildingRecord
+	footprint geom.MultiPolygon // nil for a building without geometry
+}
+
+// longName is longer than the 240 bytes of BLDG_NAME, and byte 240 falls inside
+// a character.
+var longName = "A" + strings.Repeat("大", 100)
+
+// testBuildings returns a simple polygon, a multipolygon whose first part has a
+// hole, and a building without geometry or attributes.
+func testBuildings() []testBuilding {
+	created := time.Date(2026, 3, 1, 8, 30, 0, 0, time.UTC)
+	updated := time.Date(2026, 3, 2, 9, 0, 0, 0, time.FixedZone("CST", 8*3600))
+	square := geom.MultiPolygon{{{{120, 30}, {120.001, 30}, {120.001, 30.001}, {120, 30.001}, {120, 30}}}}
+	multi := geom.MultiPolygon{
+		{
+			{{121, 31}, {121.01, 31}, {121.01, 31.01}, {121, 31.01}, {121, 31}},
+			{{121.002, 31.002}, {121.002, 31.004}, {121.004, 31.004}, {121.004, 31.002}, {121.002, 31.002}},
+		},
+		{{{121.02, 31.02}, {121.03, 31.02}, {121.03, 31.03}, {121.02, 31.02}}},
+	}
+
+	buildings := []testBuilding{
+		{
+			record: model.BuildingRecord{
+				BuildingID: 1, BuildingType: ptr("office"), BuildingName: ptr("Tower"), BuildingAddr: ptr("1 Main St"),
+				AreaCode: ptr("330106"), BuildingHeight: ptr(12.5), RoofHeight: ptr(3.25), CreateTime: created, UpdateTime: updated,
+			},
+			footprint: square,
+		},
+		{
+			record: model.BuildingRecord{
+				BuildingID: 9007199254740993, BuildingName: ptr(longName), AreaCode: ptr("330102"),
+				BuildingHeight: ptr(1e12), CreateTime: created, UpdateTime: created,
+			},
+			footprint: multi,
+		},
+		{
+			record: model.BuildingRecord{BuildingID: 3, CreateTime: created, UpdateTime: created},
+		},
+	}
+	for i, b := range buildings {
+		if b.footprint != nil {
+			buildings[i].record.WKB = b.footprint.WKB()
+		}
+	}
+	return buildings
+}
+
+// export writes buildings in format and returns the output.
+func export(t *testing.T, format string, buildings []testBuilding) []byte {
+	t.Helper()
+	var out bytes.Buffer
+	w, err := New(format, &out)
+	if err != nil {
+		t.Fatalf("New(%s) error: %v", format, err)
+	}
+	for _, b := range buildings {
+		if err := w.Write(b.record); err != nil {
+			w.Abort()
+			t.Fatalf("Write(%d) error: %v", b.record.BuildingID, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+	return out.Bytes()
+}
+
+// bounds returns the bounding box of every footprint.
+func bounds(buildings []testBuilding) geom.BBox {
+	b := geom.EmptyBBox()
+	for _, building := range buildings {
+		if building.footprint != nil {
+			b = b.Extend(building.footprint.Bounds())
+		}
+	}
+	return b
+}
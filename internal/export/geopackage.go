@@ -0,0 +1,235 @@
+// internal/export/geopackage.go
+package export
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"collision_app_go/internal/geom"
+	"collision_app_go/internal/model"
+
+	_ "modernc.org/sqlite" // registers the "sqlite" driver
+)
+
+// GeoPackage constants, from OGC 12-128r18.
+const (
+	gpkgApplicationID = 0x47504B47 // "GPKG"
+	gpkgUserVersion   = 10300      // version 1.3.0
+	// gpkgCommitEvery bounds the size of each transaction.
+	gpkgCommitEvery = 10000
+)
+
+// gpkgWGS84 is the OGC WKT definition of EPSG:4326.
+const gpkgWGS84 = `GEOGCS["WGS 84",DATUM["WGS_1984",SPHEROID["WGS 84",6378137,298.257223563,AUTHORITY["EPSG","7030"]],AUTHORITY["EPSG","6326"]],PRIMEM["Greenwich",0,AUTHORITY["EPSG","8901"]],UNIT["degree",0.0174532925199433,AUTHORITY["EPSG","9122"]],AUTHORITY["EPSG","4326"]]`
+
+// gpkgSchema creates the required metadata tables and the feature table.
+var gpkgSchema = []string{
+	fmt.Sprintf(`PRAGMA application_id = %d`, gpkgApplicationID),
+	fmt.Sprintf(`PRAGMA user_version = %d`, gpkgUserVersion),
+	`PRAGMA journal_mode = OFF`,
+	`PRAGMA synchronous = OFF`,
+	`CREATE TABLE gpkg_spatial_ref_sys (
+        srs_name TEXT NOT NULL,
+        srs_id INTEGER NOT NULL PRIMARY KEY,
+        organization TEXT NOT NULL,
+        organization_coordsys_id INTEGER NOT NULL,
+        definition TEXT NOT NULL,
+        description TEXT
+    )`,
+	`INSERT INTO gpkg_spatial_ref_sys VALUES
+        ('Undefined cartesian SRS', -1, 'NONE', -1, 'undefined', 'undefined cartesian coordinate reference system'),
+        ('Undefined geographic SRS', 0, 'NONE', 0, 'undefined', 'undefined geographic coordinate reference system'),
+        ('WGS 84 geodetic', 4326, 'EPSG', 4326, '` + gpkgWGS84 + `', 'longitude/latitude coordinates in decimal degrees on the WGS 84 spheroid')`,
+	`CREATE TABLE gpkg_contents (
+        table_name TEXT NOT NULL PRIMARY KEY,
+        data_type TEXT NOT NULL,
+        identifier TEXT UNIQUE,
+        description TEXT DEFAULT '',
+        last_change DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ','now')),
+        min_x DOUBLE,
+        min_y DOUBLE,
+        max_x DOUBLE,
+        max_y DOUBLE,
+        srs_id INTEGER,
+        CONSTRAINT fk_gc_r_srs_id FOREIGN KEY (srs_id) REFERENCES gpkg_spatial_ref_sys(srs_id)
+    )`,
+	`CREATE TABLE gpkg_geometry_columns (
+        table_name TEXT NOT NULL,
+        column_name TEXT NOT NULL,
+        geometry_type_name TEXT NOT NULL,
+        srs_id INTEGER NOT NULL,
+        z TINYINT NOT NULL,
+        m TINYINT NOT NULL,
+        CONSTRAINT pk_geom_cols PRIMARY KEY (table_name, column_name),
+        CONSTRAINT fk_gc_tn FOREIGN KEY (table_name) REFERENCES gpkg_contents(table_name),
+        CONSTRAINT fk_gc_srs FOREIGN KEY (srs_id) REFERENCES gpkg_spatial_ref_sys(srs_id)
+    )`,
+	`CREATE TABLE ` + Layer + ` (
+        fid INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+        geom MULTIPOLYGON,
+        building_id INTEGER,
+        building_type TEXT,
+        building_name TEXT,
+        building_addr TEXT,
+        area_code TEXT,
+        building_height DOUBLE,
+        roof_height DOUBLE,
+        create_time DATETIME,
+        update_time DATETIME
+    )`,
+	`INSERT INTO gpkg_contents (table_name, data_type, identifier, srs_id) VALUES ('` + Layer + `', 'features', '` + Layer + `', 4326)`,
+	`INSERT INTO gpkg_geometry_columns VALUES ('` + Layer + `', 'geom', 'MULTIPOLYGON', 4326, 0, 0)`,
+}
+
+// geoPackageWriter builds a GeoPackage in a temporary file and copies it to the
+// output on Close.
+type geoPackageWriter struct {
+	out    io.Writer
+	dir    string
+	path   string
+	db     *sql.DB
+	tx     *sql.Tx
+	insert *sql.Stmt
+	rows   int
+	bounds geom.BBox
+	closed bool
+}
+
+func newGeoPackageWriter(out io.Writer) (*geoPackageWriter, error) {
+	dir, err := os.MkdirTemp("", "export-gpkg-")
+	if err != nil {
+		return nil, err
+	}
+	x := &geoPackageWriter{out: out, dir: dir, path: filepath.Join(dir, Layer+".gpkg"), bounds: geom.EmptyBBox()}
+	if x.db, err = sql.Open("sqlite", x.path); err != nil {
+		x.cleanup()
+		return nil, err
+	}
+	// One connection, so that the pragmas apply to every statement
+	x.db.SetMaxOpenConns(1)
+	for _, stmt := range gpkgSchema {
+		if _, err := x.db.Exec(stmt); err != nil {
+			x.cleanup()
+			return nil, fmt.Errorf("failed to create GeoPackage: %w", err)
+		}
+	}
+	if err := x.begin(); err != nil {
+		x.cleanup()
+		return nil, err
+	}
+	return x, nil
+}
+
+// begin starts the transaction the next rows are inserted in.
+func (x *geoPackageWriter) begin() error {
+	var err error
+	if x.tx, err = x.db.Begin(); err != nil {
+		return err
+	}
+	x.insert, err = x.tx.Prepare(`INSERT INTO ` + Layer + ` (geom, building_id, building_type, building_name, building_addr,
+        area_code, building_height, roof_height, create_time, update_time) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	return err
+}
+
+func (x *geoPackageWriter) commit() error {
+	x.insert.Close()
+	return x.tx.Commit()
+}
+
+func (x *geoPackageWriter) Write(b model.BuildingRecord) error {
+	m, err := footprint(b)
+	if err != nil {
+		return err
+	}
+	var blob []byte
+	if m != nil {
+		bounds := m.Bounds()
+		x.bounds = x.bounds.Extend(bounds)
+		blob = gpkgGeometry(m, bounds)
+	}
+	_, err = x.insert.Exec(blob, b.BuildingID, b.BuildingType, b.BuildingName, b.BuildingAddr,
+		b.AreaCode, b.BuildingHeight, b.RoofHeight, formatTime(b.CreateTime), formatTime(b.UpdateTime))
+	if err != nil {
+		return fmt.Errorf("failed to write GeoPackage row: %w", err)
+	}
+	if x.rows++; x.rows%gpkgCommitEvery == 0 {
+		if err := x.commit(); err != nil {
+			return err
+		}
+		return x.begin()
+	}
+	return nil
+}
+
+// gpkgGeometry encodes m as a GeoPackage geometry blob: a little-endian header
+// with the SRS and the [minx, maxx, miny, maxy] envelope, followed by WKB.
+func gpkgGeometry(m geom.MultiPolygon, bounds geom.BBox) []byte {
+	le := binary.LittleEndian
+	wkb := m.WKB()
+	b := make([]byte, 0, 40+len(wkb))
+	b = append(b, 'G', 'P', 0, 0x03) // version 0; flags: envelope type 1, little-endian
+	b = le.AppendUint32(b, 4326)
+	for _, v := range []float64{bounds.MinX, bounds.MaxX, bounds.MinY, bounds.MaxY} {
+		b = le.AppendUint64(b, math.Float64bits(v))
+	}
+	return append(b, wkb...)
+}
+
+func (x *geoPackageWriter) Close() error {
+	if x.closed {
+		return nil
+	}
+	x.closed = true
+	defer x.cleanup()
+
+	if err := x.commit(); err != nil {
+		return err
+	}
+	var bounds []any
+	if !x.bounds.IsEmpty() {
+		bounds = []any{x.bounds.MinX, x.bounds.MinY, x.bounds.MaxX, x.bounds.MaxY}
+	} else {
+		bounds = []any{nil, nil, nil, nil}
+	}
+	_, err := x.db.ExecContext(context.Background(), `UPDATE gpkg_contents SET min_x = ?, min_y = ?, max_x = ?, max_y = ?, last_change = ? WHERE table_name = ?`,
+		append(bounds, time.Now().UTC().Format("2006-01-02T15:04:05.000Z"), Layer)...)
+	if err != nil {
+		return err
+	}
+	if err := x.db.Close(); err != nil {
+		return err
+	}
+
+	f, err := os.Open(x.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(x.out, f)
+	return err
+}
+
+func (x *geoPackageWriter) Abort() {
+	if !x.closed {
+		x.closed = true
+		x.cleanup()
+	}
+}
+
+// cleanup closes the database and removes the temporary file.
+func (x *geoPackageWriter) cleanup() {
+	if x.tx != nil {
+		x.tx.Rollback()
+	}
+	if x.db != nil {
+		x.db.Close()
+	}
+	os.RemoveAll(x.dir)
+}
@@ -0,0 +1,168 @@
+package export
+
+import (
+	"database/sql"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"collision_app_go/internal/geom"
+	"collision_app_go/internal/model"
+)
+
+// openGeoPackage saves an exported GeoPackage and opens it.
+func openGeoPackage(t *testing.T, data []byte) *sql.DB {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), Layer+".gpkg")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("sql.Open() error: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	var appID, version int64
+	if err := db.QueryRow(`PRAGMA application_id`).Scan(&appID); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		t.Fatal(err)
+	}
+	if appID != gpkgApplicationID || version != gpkgUserVersion {
+		t.Errorf("application_id, user_version = %#x, %d, want %#x, %d", appID, version, gpkgApplicationID, gpkgUserVersion)
+	}
+	var geomType string
+	var srsID int
+	err = db.QueryRow(`SELECT geometry_type_name, srs_id FROM gpkg_geometry_columns WHERE table_name = ? AND column_name = 'geom'`, Layer).
+		Scan(&geomType, &srsID)
+	if err != nil {
+		t.Fatalf("gpkg_geometry_columns: %v", err)
+	}
+	if geomType != "MULTIPOLYGON" || srsID != 4326 {
+		t.Errorf("geometry column = %s %d, want MULTIPOLYGON 4326", geomType, srsID)
+	}
+	return db
+}
+
+// contentsBounds returns the extent recorded in gpkg_contents.
+func contentsBounds(t *testing.T, db *sql.DB) [4]sql.NullFloat64 {
+	t.Helper()
+	var b [4]sql.NullFloat64
+	err := db.QueryRow(`SELECT min_x, min_y, max_x, max_y FROM gpkg_contents WHERE table_name = ?`, Layer).
+		Scan(&b[0], &b[1], &b[2], &b[3])
+	if err != nil {
+		t.Fatalf("gpkg_contents: %v", err)
+	}
+	return b
+}
+
+// decodeGeometry checks the header of a GeoPackage geometry blob and decodes it.
+func decodeGeometry(t *testing.T, blob []byte) (geom.MultiPolygon, geom.BBox) {
+	t.Helper()
+	if len(blob) < 40 || blob[0] != 'G' || blob[1] != 'P' || blob[2] != 0 || blob[3] != 0x03 {
+		t.Fatalf("geometry header = % x, want 47 50 00 03", blob[:min(len(blob), 4)])
+	}
+	le := binary.LittleEndian
+	if srs := le.Uint32(blob[4:]); srs != 4326 {
+		t.Errorf("geometry srs_id = %d, want 4326", srs)
+	}
+	f := func(i int) float64 { return math.Float64frombits(le.Uint64(blob[8+8*i:])) }
+	envelope := geom.BBox{MinX: f(0), MaxX: f(1), MinY: f(2), MaxY: f(3)}
+	m, err := geom.ParseWKB(blob[40:])
+	if err != nil {
+		t.Fatalf("ParseWKB() error: %v", err)
+	}
+	return m, envelope
+}
+
+func TestGeoPackageWriter(t *testing.T) {
+	buildings := testBuildings()
+	db := openGeoPackage(t, export(t, model.ExportGeoPackage, buildings))
+
+	want := bounds(buildings)
+	got := contentsBounds(t, db)
+	for _, v := range got {
+		if !v.Valid {
+			t.Fatalf("gpkg_contents bounds = %v, want %+v", got, want)
+		}
+	}
+	if box := (geom.BBox{MinX: got[0].Float64, MinY: got[1].Float64, MaxX: got[2].Float64, MaxY: got[3].Float64}); box != want {
+		t.Errorf("gpkg_contents bounds = %+v, want %+v", box, want)
+	}
+
+	rows, err := db.Query(`SELECT geom, building_id, building_type, building_name, building_addr, area_code,
+        building_height, roof_height, create_time, update_time FROM ` + Layer + ` ORDER BY fid`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	i := 0
+	for ; rows.Next(); i++ {
+		var (
+			blob                   []byte
+			got                    model.BuildingRecord
+			createTime, updateTime string
+		)
+		err := rows.Scan(&blob, &got.BuildingID, &got.BuildingType, &got.BuildingName, &got.BuildingAddr, &got.AreaCode,
+			&got.BuildingHeight, &got.RoofHeight, &createTime, &updateTime)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i >= len(buildings) {
+			continue
+		}
+		b := buildings[i]
+
+		if b.footprint == nil {
+			if blob != nil {
+				t.Errorf("row %d geom = % x, want NULL", i+1, blob)
+			}
+		} else {
+			m, envelope := decodeGeometry(t, blob)
+			if !reflect.DeepEqual(m, b.footprint) {
+				t.Errorf("row %d geom = %v, want %v", i+1, m, b.footprint)
+			}
+			if want := b.footprint.Bounds(); envelope != want {
+				t.Errorf("row %d envelope = %+v, want %+v", i+1, envelope, want)
+			}
+		}
+
+		// Text is stored in full, unlike the fixed-width Shapefile fields
+		wantRecord := b.record
+		wantRecord.WKB, wantRecord.CreateTime, wantRecord.UpdateTime = nil, got.CreateTime, got.UpdateTime
+		if !reflect.DeepEqual(got, wantRecord) {
+			t.Errorf("row %d = %+v, want %+v", i+1, got, wantRecord)
+		}
+		if want := formatTime(b.record.CreateTime); createTime != want {
+			t.Errorf("row %d create_time = %q, want %q", i+1, createTime, want)
+		}
+		if want := formatTime(b.record.UpdateTime); updateTime != want {
+			t.Errorf("row %d update_time = %q, want %q", i+1, updateTime, want)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if i != len(buildings) {
+		t.Errorf("got %d rows, want %d", i, len(buildings))
+	}
+}
+
+func TestGeoPackageWriterEmpty(t *testing.T) {
+	db := openGeoPackage(t, export(t, model.ExportGeoPackage, nil))
+	if got := contentsBounds(t, db); got != [4]sql.NullFloat64{} {
+		t.Errorf("gpkg_contents bounds = %v, want NULL", got)
+	}
+	var n int
+	if err := db.QueryRow(`SELECT count(*) FROM ` + Layer).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 {
+		t.Errorf("got %d rows, want 0", n)
+	}
+}
@@ -0,0 +1,314 @@
+// internal/export/shapefile.go
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"collision_app_go/internal/geom"
+	"collision_app_go/internal/model"
+)
+
+// Shapefile constants, from the ESRI Shapefile Technical Description.
+const (
+	shpFileCode   = 9994
+	shpVersion    = 1000
+	shpNull       = 0
+	shpPolygon    = 5
+	shpHeaderSize = 100
+	// shpMaxSize is the largest .shp or .dbf file: offsets are 32-bit counts of
+	// 16-bit words.
+	shpMaxSize = math.MaxInt32
+)
+
+// prjWGS84 is the ESRI projection file of EPSG:4326.
+const prjWGS84 = `GEOGCS["GCS_WGS_1984",DATUM["D_WGS_1984",SPHEROID["WGS_1984",6378137.0,298.257223563]],PRIMEM["Greenwich",0.0],UNIT["Degree",0.0174532925199433]]`
+
+// dbfField is a column of the attribute table. Character fields are UTF-8, as
+// declared by the .cpg file.
+type dbfField struct {
+	name     string // at most 10 characters
+	typ      byte   // C (character) or N (numeric)
+	size     int
+	decimals int
+}
+
+var dbfFields = []dbfField{
+	{"BLDG_ID", 'N', 20, 0},
+	{"BLDG_TYPE", 'C', 240, 0},
+	{"BLDG_NAME", 'C', 240, 0},
+	{"BLDG_ADDR", 'C', 254, 0},
+	{"AREA_CODE", 'C', 20, 0},
+	{"HEIGHT", 'N', 12, 2},
+	{"ROOF_HGT", 'N', 12, 2},
+	{"CREATED", 'C', 25, 0},
+	{"UPDATED", 'C', 25, 0},
+}
+
+// shapefileWriter writes the .shp, .shx and .dbf files to a temporary directory,
+// since their headers hold totals known only at the end, and zips them on Close.
+type shapefileWriter struct {
+	out              io.Writer
+	dir              string
+	shp, shx, dbf    *os.File
+	shpBuf, dbfBuf   *bufio.Writer
+	shxBuf           *bufio.Writer
+	shpSize, dbfSize int64
+	records          int
+	bounds           geom.BBox
+	recordLen        int
+	closed           bool
+}
+
+func newShapefileWriter(out io.Writer) (*shapefileWriter, error) {
+	dir, err := os.MkdirTemp("", "export-shp-")
+	if err != nil {
+		return nil, err
+	}
+	x := &shapefileWriter{out: out, dir: dir, bounds: geom.EmptyBBox(), shpSize: shpHeaderSize}
+	for _, f := range []struct {
+		ext string
+		dst **os.File
+	}{{"shp", &x.shp}, {"shx", &x.shx}, {"dbf", &x.dbf}} {
+		if *f.dst, err = os.Create(filepath.Join(dir, Layer+"."+f.ext)); err != nil {
+			x.cleanup()
+			return nil, err
+		}
+	}
+
+	// Headers are written as placeholders and rewritten with the totals on Close
+	x.shpBuf, x.shxBuf, x.dbfBuf = bufio.NewWriter(x.shp), bufio.NewWriter(x.shx), bufio.NewWriter(x.dbf)
+	x.shpBuf.Write(make([]byte, shpHeaderSize))
+	x.shxBuf.Write(make([]byte, shpHeaderSize))
+	x.recordLen = 1 // deletion flag
+	for _, f := range dbfFields {
+		x.recordLen += f.size
+	}
+	header := x.dbfHeader()
+	x.dbfBuf.Write(header)
+	x.dbfSize = int64(len(header))
+	return x, nil
+}
+
+func (x *shapefileWriter) Write(b model.BuildingRecord) error {
+	m, err := footprint(b)
+	if err != nil {
+		return err
+	}
+
+	content := shapeContent(m)
+	if x.shpSize+8+int64(len(content)) > shpMaxSize || x.dbfSize+int64(x.recordLen) > shpMaxSize {
+		return errors.New("export exceeds the 2 GB Shapefile limit; narrow the filter or use another format")
+	}
+	if m != nil {
+		x.bounds = x.bounds.Extend(m.Bounds())
+	}
+	x.records++
+
+	// Record header: 1-based record number and content length in 16-bit words
+	var rec [8]byte
+	binary.BigEndian.PutUint32(rec[0:], uint32(x.records))
+	binary.BigEndian.PutUint32(rec[4:], uint32(len(content)/2))
+	var idx [8]byte
+	binary.BigEndian.PutUint32(idx[0:], uint32(x.shpSize/2))
+	binary.BigEndian.PutUint32(idx[4:], uint32(len(content)/2))
+	x.shxBuf.Write(idx[:])
+	x.shpBuf.Write(rec[:])
+	x.shpBuf.Write(content)
+	x.shpSize += int64(len(rec) + len(content))
+
+	x.dbfBuf.WriteByte(' ') // not deleted
+	values := []string{
+		strconv.FormatInt(b.BuildingID, 10), str(b.BuildingType), str(b.BuildingName), str(b.BuildingAddr), str(b.AreaCode),
+		fixed(b.BuildingHeight, 2), fixed(b.RoofHeight, 2), formatTime(b.CreateTime), formatTime(b.UpdateTime),
+	}
+	for i, f := range dbfFields {
+		x.dbfBuf.Write(dbfValue(f, values[i]))
+	}
+	x.dbfSize += int64(x.recordLen)
+	return nil
+}
+
+// shapeContent encodes m as a Polygon record, or a Null record when m is nil.
+// Shapefile exterior rings are clockwise and holes counter-clockwise.
+func shapeContent(m geom.MultiPolygon) []byte {
+	le := binary.LittleEndian
+	if m == nil {
+		return le.AppendUint32(nil, shpNull)
+	}
+	m = m.Oriented(false)
+	var parts []uint32
+	var points []geom.Point
+	for _, poly := range m {
+		for _, ring := range poly {
+			parts = append(parts, uint32(len(points)))
+			points = append(points, ring...)
+		}
+	}
+	bounds := m.Bounds()
+	b := make([]byte, 0, 44+4*len(parts)+16*len(points))
+	b = le.AppendUint32(b, shpPolygon)
+	for _, v := range []float64{bounds.MinX, bounds.MinY, bounds.MaxX, bounds.MaxY} {
+		b = le.AppendUint64(b, math.Float64bits(v))
+	}
+	b = le.AppendUint32(b, uint32(len(parts)))
+	b = le.AppendUint32(b, uint32(len(points)))
+	for _, p := range parts {
+		b = le.AppendUint32(b, p)
+	}
+	for _, p := range points {
+		b = le.AppendUint64(b, math.Float64bits(p[0]))
+		b = le.AppendUint64(b, math.Float64bits(p[1]))
+	}
+	return b
+}
+
+// dbfValue pads or truncates s to the field width: text is left-aligned and cut
+// at a character boundary, numbers are right-aligned.
+func dbfValue(f dbfField, s string) []byte {
+	out := make([]byte, f.size)
+	for i := range out {
+		out[i] = ' '
+	}
+	if len(s) > f.size {
+		if f.typ == 'N' {
+			return out // does not fit: stored as null
+		}
+		cut := f.size
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		s = s[:cut]
+	}
+	if f.typ == 'N' {
+		copy(out[f.size-len(s):], s)
+	} else {
+		copy(out, s)
+	}
+	return out
+}
+
+// dbfHeader returns the dBASE III header; the record count is filled in on Close.
+func (x *shapefileWriter) dbfHeader() []byte {
+	headerLen := 32 + 32*len(dbfFields) + 1
+	now := time.Now()
+	b := make([]byte, headerLen)
+	b[0] = 0x03
+	b[1], b[2], b[3] = byte(now.Year()-1900), byte(now.Month()), byte(now.Day())
+	binary.LittleEndian.PutUint16(b[8:], uint16(headerLen))
+	binary.LittleEndian.PutUint16(b[10:], uint16(x.recordLen))
+	for i, f := range dbfFields {
+		d := b[32+32*i:]
+		copy(d[:11], f.name)
+		d[11] = f.typ
+		d[16] = byte(f.size)
+		d[17] = byte(f.decimals)
+	}
+	b[headerLen-1] = 0x0D
+	return b
+}
+
+// mainHeader returns the common .shp/.shx header for a file of size bytes.
+func (x *shapefileWriter) mainHeader(size int64) []byte {
+	b := make([]byte, shpHeaderSize)
+	binary.BigEndian.PutUint32(b[0:], shpFileCode)
+	binary.BigEndian.PutUint32(b[24:], uint32(size/2))
+	binary.LittleEndian.PutUint32(b[28:], shpVersion)
+	binary.LittleEndian.PutUint32(b[32:], shpPolygon)
+	bounds := x.bounds
+	if bounds.IsEmpty() {
+		bounds = geom.BBox{}
+	}
+	for i, v := range []float64{bounds.MinX, bounds.MinY, bounds.MaxX, bounds.MaxY} {
+		binary.LittleEndian.PutUint64(b[36+8*i:], math.Float64bits(v))
+	}
+	return b
+}
+
+func (x *shapefileWriter) Close() error {
+	if x.closed {
+		return nil
+	}
+	x.closed = true
+	defer x.cleanup()
+
+	x.dbfBuf.WriteByte(0x1A) // end of file
+	for _, w := range []*bufio.Writer{x.shpBuf, x.shxBuf, x.dbfBuf} {
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	if _, err := x.shp.WriteAt(x.mainHeader(x.shpSize), 0); err != nil {
+		return err
+	}
+	if _, err := x.shx.WriteAt(x.mainHeader(shpHeaderSize+8*int64(x.records)), 0); err != nil {
+		return err
+	}
+	var count [4]byte
+	binary.LittleEndian.PutUint32(count[:], uint32(x.records))
+	if _, err := x.dbf.WriteAt(count[:], 4); err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(x.out)
+	now := time.Now()
+	create := func(name string) (io.Writer, error) {
+		return zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: now})
+	}
+	for _, f := range []*os.File{x.shp, x.shx, x.dbf} {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		w, err := create(filepath.Base(f.Name()))
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(w, f); err != nil {
+			return err
+		}
+	}
+	for _, f := range [][2]string{{Layer + ".prj", prjWGS84}, {Layer + ".cpg", "UTF-8"}} {
+		w, err := create(f[0])
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, f[1]); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func (x *shapefileWriter) Abort() {
+	if !x.closed {
+		x.closed = true
+		x.cleanup()
+	}
+}
+
+// cleanup removes the temporary files.
+func (x *shapefileWriter) cleanup() {
+	for _, f := range []*os.File{x.shp, x.shx, x.dbf} {
+		if f != nil {
+			f.Close()
+		}
+	}
+	os.RemoveAll(x.dir)
+}
+
+// fixed formats f with the given decimals, or returns "" for nil.
+func fixed(f *float64, decimals int) string {
+	if f == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*f, 'f', decimals, 64)
+}
@@ -0,0 +1,298 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"collision_app_go/internal/geom"
+	"collision_app_go/internal/model"
+)
+
+// unzipShapefile returns the files of an exported Shapefile archive by name.
+func unzipShapefile(t *testing.T, data []byte) map[string][]byte {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("zip.NewReader() error: %v", err)
+	}
+	files := make(map[string][]byte)
+	for _, f := range zr.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		b, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatalf("read %s: %v", f.Name, err)
+		}
+		files[f.Name] = b
+	}
+	for _, ext := range []string{"shp", "shx", "dbf", "prj", "cpg"} {
+		if _, ok := files[Layer+"."+ext]; !ok {
+			t.Fatalf("archive has no %s.%s", Layer, ext)
+		}
+	}
+	return files
+}
+
+// readBBox decodes four little-endian doubles in the order minx, miny, maxx, maxy.
+func readBBox(b []byte) geom.BBox {
+	f := func(i int) float64 { return math.Float64frombits(binary.LittleEndian.Uint64(b[8*i:])) }
+	return geom.BBox{MinX: f(0), MinY: f(1), MaxX: f(2), MaxY: f(3)}
+}
+
+// checkMainHeader checks the .shp/.shx header of a file.
+func checkMainHeader(t *testing.T, name string, b []byte, want geom.BBox) {
+	t.Helper()
+	if len(b) < shpHeaderSize {
+		t.Fatalf("%s is %d bytes, want at least %d", name, len(b), shpHeaderSize)
+	}
+	if got := binary.BigEndian.Uint32(b[0:]); got != shpFileCode {
+		t.Errorf("%s file code = %d, want %d", name, got, shpFileCode)
+	}
+	if got := int(binary.BigEndian.Uint32(b[24:])) * 2; got != len(b) {
+		t.Errorf("%s file length = %d, want %d", name, got, len(b))
+	}
+	if got := binary.LittleEndian.Uint32(b[28:]); got != shpVersion {
+		t.Errorf("%s version = %d, want %d", name, got, shpVersion)
+	}
+	if got := binary.LittleEndian.Uint32(b[32:]); got != shpPolygon {
+		t.Errorf("%s shape type = %d, want %d", name, got, shpPolygon)
+	}
+	if got := readBBox(b[36:]); got != want {
+		t.Errorf("%s bbox = %+v, want %+v", name, got, want)
+	}
+}
+
+// shpRecord is a decoded .shp record.
+type shpRecord struct {
+	offset int // of the record header, in bytes
+	number int
+	typ    uint32
+	bounds geom.BBox
+	rings  []geom.Ring
+}
+
+// readShapes decodes the records of a .shp file.
+func readShapes(t *testing.T, b []byte) []shpRecord {
+	t.Helper()
+	le := binary.LittleEndian
+	var records []shpRecord
+	for off := shpHeaderSize; off < len(b); {
+		rec := shpRecord{offset: off, number: int(binary.BigEndian.Uint32(b[off:]))}
+		size := int(binary.BigEndian.Uint32(b[off+4:])) * 2
+		c := b[off+8 : off+8+size]
+		rec.typ = le.Uint32(c)
+		if rec.typ == shpPolygon {
+			rec.bounds = readBBox(c[4:])
+			numParts, numPoints := int(le.Uint32(c[36:])), int(le.Uint32(c[40:]))
+			points := c[44+4*numParts:]
+			if len(points) != 16*numPoints {
+				t.Fatalf("record %d has %d bytes of points, want %d", rec.number, len(points), 16*numPoints)
+			}
+			for i := 0; i < numParts; i++ {
+				start, end := int(le.Uint32(c[44+4*i:])), numPoints
+				if i+1 < numParts {
+					end = int(le.Uint32(c[48+4*i:]))
+				}
+				var ring geom.Ring
+				for j := start; j < end; j++ {
+					ring = append(ring, geom.Point{
+						math.Float64frombits(le.Uint64(points[16*j:])),
+						math.Float64frombits(le.Uint64(points[16*j+8:])),
+					})
+				}
+				rec.rings = append(rec.rings, ring)
+			}
+		}
+		records = append(records, rec)
+		off += 8 + size
+	}
+	return records
+}
+
+// dbfTable is a decoded .dbf file.
+type dbfTable struct {
+	fields []dbfField
+	rows   [][][]byte // raw field values of each record
+}
+
+// readDBF decodes a dBASE III file.
+func readDBF(t *testing.T, b []byte) dbfTable {
+	t.Helper()
+	le := binary.LittleEndian
+	if b[0] != 0x03 {
+		t.Errorf("dbf version = %#x, want 0x03", b[0])
+	}
+	count, headerLen, recordLen := int(le.Uint32(b[4:])), int(le.Uint16(b[8:])), int(le.Uint16(b[10:]))
+	if b[headerLen-1] != 0x0D {
+		t.Errorf("dbf header terminator = %#x, want 0x0D", b[headerLen-1])
+	}
+	if want := headerLen + count*recordLen + 1; len(b) != want {
+		t.Fatalf("dbf is %d bytes, want %d", len(b), want)
+	}
+	if b[len(b)-1] != 0x1A {
+		t.Errorf("dbf end of file = %#x, want 0x1A", b[len(b)-1])
+	}
+
+	var table dbfTable
+	size := 1
+	for d := b[32 : headerLen-1]; len(d) >= 32; d = d[32:] {
+		f := dbfField{
+			name:     string(bytes.TrimRight(d[:11], "\x00")),
+			typ:      d[11],
+			size:     int(d[16]),
+			decimals: int(d[17]),
+		}
+		table.fields = append(table.fields, f)
+		size += f.size
+	}
+	if size != recordLen {
+		t.Errorf("dbf record length = %d, fields add up to %d", recordLen, size)
+	}
+	for i := 0; i < count; i++ {
+		rec := b[headerLen+i*recordLen : headerLen+(i+1)*recordLen]
+		if rec[0] != ' ' {
+			t.Errorf("record %d deletion flag = %q, want ' '", i+1, rec[0])
+		}
+		var row [][]byte
+		for off, f := 1, 0; f < len(table.fields); f++ {
+			row = append(row, rec[off:off+table.fields[f].size])
+			off += table.fields[f].size
+		}
+		table.rows = append(table.rows, row)
+	}
+	return table
+}
+
+func TestShapefileWriter(t *testing.T) {
+	buildings := testBuildings()
+	files := unzipShapefile(t, export(t, model.ExportShapefile, buildings))
+	shp, shx, dbf := files[Layer+".shp"], files[Layer+".shx"], files[Layer+".dbf"]
+	want := bounds(buildings)
+	checkMainHeader(t, "shp", shp, want)
+	checkMainHeader(t, "shx", shx, want)
+	if got := string(files[Layer+".prj"]); got != prjWGS84 {
+		t.Errorf("prj = %q, want %q", got, prjWGS84)
+	}
+	if got := string(files[Layer+".cpg"]); got != "UTF-8" {
+		t.Errorf("cpg = %q, want %q", got, "UTF-8")
+	}
+
+	records := readShapes(t, shp)
+	if len(records) != len(buildings) {
+		t.Fatalf("shp has %d records, want %d", len(records), len(buildings))
+	}
+	if got, want := len(shx), shpHeaderSize+8*len(buildings); got != want {
+		t.Fatalf("shx is %d bytes, want %d", got, want)
+	}
+	for i, b := range buildings {
+		rec := records[i]
+		if rec.number != i+1 {
+			t.Errorf("record %d number = %d", i+1, rec.number)
+		}
+		idx := shx[shpHeaderSize+8*i:]
+		if got := int(binary.BigEndian.Uint32(idx)) * 2; got != rec.offset {
+			t.Errorf("record %d shx offset = %d, want %d", i+1, got, rec.offset)
+		}
+
+		if b.footprint == nil {
+			if rec.typ != shpNull {
+				t.Errorf("record %d shape type = %d, want null", i+1, rec.typ)
+			}
+			continue
+		}
+		if rec.typ != shpPolygon {
+			t.Fatalf("record %d shape type = %d, want %d", i+1, rec.typ, shpPolygon)
+		}
+		if got, want := rec.bounds, b.footprint.Bounds(); got != want {
+			t.Errorf("record %d bbox = %+v, want %+v", i+1, got, want)
+		}
+		// Exterior rings are clockwise and holes counter-clockwise
+		var wantRings []geom.Ring
+		for _, poly := range b.footprint {
+			for j, ring := range poly {
+				if (ring.SignedArea() > 0) == (j == 0) {
+					ring = ring.Reversed()
+				}
+				wantRings = append(wantRings, ring)
+			}
+		}
+		if !reflect.DeepEqual(rec.rings, wantRings) {
+			t.Errorf("record %d rings = %v, want %v", i+1, rec.rings, wantRings)
+		}
+	}
+
+	table := readDBF(t, dbf)
+	if !reflect.DeepEqual(table.fields, dbfFields) {
+		t.Errorf("dbf fields = %v, want %v", table.fields, dbfFields)
+	}
+	if len(table.rows) != len(buildings) {
+		t.Fatalf("dbf has %d records, want %d", len(table.rows), len(buildings))
+	}
+	wantRows := [][]string{
+		{"1", "office", "Tower", "1 Main St", "330106", "12.50", "3.25", "2026-03-01T08:30:00Z", "2026-03-02T09:00:00+08:00"},
+		{"9007199254740993", "", "A" + strings.Repeat("大", 79), "", "330102", "", "", "2026-03-01T08:30:00Z", "2026-03-01T08:30:00Z"},
+		{"3", "", "", "", "", "", "", "2026-03-01T08:30:00Z", "2026-03-01T08:30:00Z"},
+	}
+	for i, row := range table.rows {
+		for j, raw := range row {
+			f := dbfFields[j]
+			if !utf8.Valid(raw) {
+				t.Errorf("record %d %s is not valid UTF-8: %q", i+1, f.name, raw)
+			}
+			got := strings.TrimSpace(string(raw))
+			if got != wantRows[i][j] {
+				t.Errorf("record %d %s = %q, want %q", i+1, f.name, got, wantRows[i][j])
+			}
+			// Numbers are right-aligned and text left-aligned
+			if got != "" && (f.typ == 'N') != (raw[0] == ' ') {
+				t.Errorf("record %d %s = %q is misaligned", i+1, f.name, raw)
+			}
+		}
+	}
+}
+
+func TestShapefileWriterEmpty(t *testing.T) {
+	files := unzipShapefile(t, export(t, model.ExportShapefile, nil))
+	checkMainHeader(t, "shp", files[Layer+".shp"], geom.BBox{})
+	checkMainHeader(t, "shx", files[Layer+".shx"], geom.BBox{})
+	if table := readDBF(t, files[Layer+".dbf"]); len(table.rows) != 0 {
+		t.Errorf("dbf has %d records, want 0", len(table.rows))
+	}
+}
+
+func TestDBFValue(t *testing.T) {
+	text := dbfField{"NAME", 'C', 6, 0}
+	number := dbfField{"HEIGHT", 'N', 6, 2}
+	tests := []struct {
+		name  string
+		field dbfField
+		value string
+		want  string
+	}{
+		{"text padded", text, "ab", "ab    "},
+		{"text exact", text, "abcdef", "abcdef"},
+		{"text truncated", text, "abcdefgh", "abcdef"},
+		{"text cut before a character", text, "ab大厦", "ab大 "},
+		{"text empty", text, "", "      "},
+		{"number right-aligned", number, "12.50", " 12.50"},
+		{"number overflow is null", number, "1234.50", "      "},
+		{"number empty", number, "", "      "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(dbfValue(tt.field, tt.value)); got != tt.want {
+				t.Errorf("dbfValue(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
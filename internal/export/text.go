@@ -0,0 +1,191 @@
+// internal/export/text.go
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"collision_app_go/internal/geom"
+	"collision_app_go/internal/model"
+)
+
+// wktWriter writes "WKT,height" lines, the format the import reads. Buildings
+// without a footprint or height cannot be imported again and are skipped.
+type wktWriter struct {
+	w *bufio.Writer
+}
+
+func newWKTWriter(w io.Writer) *wktWriter {
+	return &wktWriter{w: bufio.NewWriter(w)}
+}
+
+func (x *wktWriter) Write(b model.BuildingRecord) error {
+	m, err := footprint(b)
+	if err != nil || m == nil || b.BuildingHeight == nil {
+		return err
+	}
+	x.w.WriteString(m.WKT())
+	x.w.WriteByte(',')
+	x.w.WriteString(strconv.FormatFloat(*b.BuildingHeight, 'f', -1, 64))
+	return x.w.WriteByte('\n')
+}
+
+func (x *wktWriter) Close() error {
+	return x.w.Flush()
+}
+
+func (x *wktWriter) Abort() {}
+
+// geoJSONWriter writes a FeatureCollection, or with lines set, one Feature per
+// line (newline-delimited GeoJSON). Rings follow the RFC 7946 right-hand rule.
+type geoJSONWriter struct {
+	w       *bufio.Writer
+	lines   bool
+	started bool
+}
+
+type feature struct {
+	Type       string            `json:"type"`
+	ID         int64             `json:"id"`
+	Geometry   *multiPolygonJSON `json:"geometry"`
+	Properties featureProperties `json:"properties"`
+}
+
+type multiPolygonJSON struct {
+	Type        string            `json:"type"`
+	Coordinates geom.MultiPolygon `json:"coordinates"`
+}
+
+type featureProperties struct {
+	BuildingID     int64    `json:"building_id"`
+	BuildingType   *string  `json:"building_type"`
+	BuildingName   *string  `json:"building_name"`
+	BuildingAddr   *string  `json:"building_addr"`
+	AreaCode       *string  `json:"area_code"`
+	BuildingHeight *float64 `json:"building_height"`
+	RoofHeight     *float64 `json:"roof_height"`
+	CreateTime     string   `json:"create_time"`
+	UpdateTime     string   `json:"update_time"`
+}
+
+func newGeoJSONWriter(w io.Writer, lines bool) *geoJSONWriter {
+	return &geoJSONWriter{w: bufio.NewWriter(w), lines: lines}
+}
+
+func (x *geoJSONWriter) Write(b model.BuildingRecord) error {
+	m, err := footprint(b)
+	if err != nil {
+		return err
+	}
+	f := feature{
+		Type: "Feature",
+		ID:   b.BuildingID,
+		Properties: featureProperties{
+			BuildingID:     b.BuildingID,
+			BuildingType:   b.BuildingType,
+			BuildingName:   b.BuildingName,
+			BuildingAddr:   b.BuildingAddr,
+			AreaCode:       b.AreaCode,
+			BuildingHeight: b.BuildingHeight,
+			RoofHeight:     b.RoofHeight,
+			CreateTime:     formatTime(b.CreateTime),
+			UpdateTime:     formatTime(b.UpdateTime),
+		},
+	}
+	if m != nil {
+		f.Geometry = &multiPolygonJSON{Type: "MultiPolygon", Coordinates: m.Oriented(true)}
+	}
+	data, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case x.lines:
+	case !x.started:
+		x.w.WriteString(`{"type":"FeatureCollection","features":[` + "\n")
+	default:
+		x.w.WriteString(",\n")
+	}
+	x.started = true
+	x.w.Write(data)
+	if x.lines {
+		return x.w.WriteByte('\n')
+	}
+	return nil
+}
+
+func (x *geoJSONWriter) Close() error {
+	if !x.lines {
+		if !x.started {
+			x.w.WriteString(`{"type":"FeatureCollection","features":[`)
+		}
+		x.w.WriteString("\n]}\n")
+	}
+	return x.w.Flush()
+}
+
+func (x *geoJSONWriter) Abort() {}
+
+// csvWriter writes the attributes of each building with its footprint as WKT.
+type csvWriter struct {
+	w           *csv.Writer
+	wroteHeader bool
+}
+
+var csvHeader = []string{
+	"building_id", "building_type", "building_name", "building_addr", "area_code",
+	"building_height", "roof_height", "create_time", "update_time", "wkt",
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (x *csvWriter) Write(b model.BuildingRecord) error {
+	if !x.wroteHeader {
+		if err := x.w.Write(csvHeader); err != nil {
+			return err
+		}
+		x.wroteHeader = true
+	}
+	m, err := footprint(b)
+	if err != nil {
+		return err
+	}
+	wkt := ""
+	if m != nil {
+		wkt = m.WKT()
+	}
+	return x.w.Write([]string{
+		strconv.FormatInt(b.BuildingID, 10), str(b.BuildingType), str(b.BuildingName), str(b.BuildingAddr), str(b.AreaCode),
+		num(b.BuildingHeight), num(b.RoofHeight), formatTime(b.CreateTime), formatTime(b.UpdateTime), wkt,
+	})
+}
+
+func (x *csvWriter) Close() error {
+	if !x.wroteHeader {
+		x.w.Write(csvHeader)
+	}
+	x.w.Flush()
+	return x.w.Error()
+}
+
+func (x *csvWriter) Abort() {}
+
+func str(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func num(f *float64) string {
+	if f == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*f, 'f', -1, 64)
+}
@@ -0,0 +1,249 @@
+// proto/collision/v1/collision.proto
+//
+// 碰撞检测 gRPC 接口, 与 HTTP /api/v1 接口共用同一套检测逻辑。
+// 认证: metadata 中携带 x-api-key 或 authorization: Bearer <token>, 需要 viewer 角色。
+// 错误: 使用标准 gRPC 状态码, 状态消息按 metadata accept-language (zh/en) 本地化,
+// 稳定的错误码 (与 HTTP 接口相同) 放在 trailer "error-code" 中。
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: collision/v1/collision.proto
+
+package collisionv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	CollisionService_CheckPoint_FullMethodName      = "/collision.v1.CollisionService/CheckPoint"
+	CollisionService_CheckBatch_FullMethodName      = "/collision.v1.CollisionService/CheckBatch"
+	CollisionService_CheckRoute_FullMethodName      = "/collision.v1.CollisionService/CheckRoute"
+	CollisionService_StreamPositions_FullMethodName = "/collision.v1.CollisionService/StreamPositions"
+)
+
+// CollisionServiceClient is the client API for CollisionService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CollisionServiceClient interface {
+	// CheckPoint checks a single position against nearby buildings.
+	CheckPoint(ctx context.Context, in *CheckPointRequest, opts ...grpc.CallOption) (*CheckPointResponse, error)
+	// CheckBatch checks up to 500 positions; each point gets its own result or error.
+	CheckBatch(ctx context.Context, in *CheckBatchRequest, opts ...grpc.CallOption) (*CheckBatchResponse, error)
+	// CheckRoute checks every leg of a 3D route against the buildings along it.
+	CheckRoute(ctx context.Context, in *CheckRouteRequest, opts ...grpc.CallOption) (*CheckRouteResponse, error)
+	// StreamPositions checks each position sent by the client and answers with one
+	// result per position, in order. A failed check is reported in the result and
+	// does not end the stream.
+	StreamPositions(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[PositionUpdate, PositionResult], error)
+}
+
+type collisionServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCollisionServiceClient(cc grpc.ClientConnInterface) CollisionServiceClient {
+	return &collisionServiceClient{cc}
+}
+
+func (c *collisionServiceClient) CheckPoint(ctx context.Context, in *CheckPointRequest, opts ...grpc.CallOption) (*CheckPointResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckPointResponse)
+	err := c.cc.Invoke(ctx, CollisionService_CheckPoint_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collisionServiceClient) CheckBatch(ctx context.Context, in *CheckBatchRequest, opts ...grpc.CallOption) (*CheckBatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckBatchResponse)
+	err := c.cc.Invoke(ctx, CollisionService_CheckBatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collisionServiceClient) CheckRoute(ctx context.Context, in *CheckRouteRequest, opts ...grpc.CallOption) (*CheckRouteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckRouteResponse)
+	err := c.cc.Invoke(ctx, CollisionService_CheckRoute_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collisionServiceClient) StreamPositions(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[PositionUpdate, PositionResult], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CollisionService_ServiceDesc.Streams[0], CollisionService_StreamPositions_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[PositionUpdate, PositionResult]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CollisionService_StreamPositionsClient = grpc.BidiStreamingClient[PositionUpdate, PositionResult]
+
+// CollisionServiceServer is the server API for CollisionService service.
+// All implementations must embed UnimplementedCollisionServiceServer
+// for forward compatibility.
+type CollisionServiceServer interface {
+	// CheckPoint checks a single position against nearby buildings.
+	CheckPoint(context.Context, *CheckPointRequest) (*CheckPointResponse, error)
+	// CheckBatch checks up to 500 positions; each point gets its own result or error.
+	CheckBatch(context.Context, *CheckBatchRequest) (*CheckBatchResponse, error)
+	// CheckRoute checks every leg of a 3D route against the buildings along it.
+	CheckRoute(context.Context, *CheckRouteRequest) (*CheckRouteResponse, error)
+	// StreamPositions checks each position sent by the client and answers with one
+	// result per position, in order. A failed check is reported in the result and
+	// does not end the stream.
+	StreamPositions(grpc.BidiStreamingServer[PositionUpdate, PositionResult]) error
+	mustEmbedUnimplementedCollisionServiceServer()
+}
+
+// UnimplementedCollisionServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedCollisionServiceServer struct{}
+
+func (UnimplementedCollisionServiceServer) CheckPoint(context.Context, *CheckPointRequest) (*CheckPointResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckPoint not implemented")
+}
+func (UnimplementedCollisionServiceServer) CheckBatch(context.Context, *CheckBatchRequest) (*CheckBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckBatch not implemented")
+}
+func (UnimplementedCollisionServiceServer) CheckRoute(context.Context, *CheckRouteRequest) (*CheckRouteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckRoute not implemented")
+}
+func (UnimplementedCollisionServiceServer) StreamPositions(grpc.BidiStreamingServer[PositionUpdate, PositionResult]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamPositions not implemented")
+}
+func (UnimplementedCollisionServiceServer) mustEmbedUnimplementedCollisionServiceServer() {}
+func (UnimplementedCollisionServiceServer) testEmbeddedByValue()                          {}
+
+// UnsafeCollisionServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CollisionServiceServer will
+// result in compilation errors.
+type UnsafeCollisionServiceServer interface {
+	mustEmbedUnimplementedCollisionServiceServer()
+}
+
+func RegisterCollisionServiceServer(s grpc.ServiceRegistrar, srv CollisionServiceServer) {
+	// If the following call pancis, it indicates UnimplementedCollisionServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&CollisionService_ServiceDesc, srv)
+}
+
+func _CollisionService_CheckPoint_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckPointRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollisionServiceServer).CheckPoint(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollisionService_CheckPoint_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollisionServiceServer).CheckPoint(ctx, req.(*CheckPointRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollisionService_CheckBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollisionServiceServer).CheckBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollisionService_CheckBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollisionServiceServer).CheckBatch(ctx, req.(*CheckBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollisionService_CheckRoute_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckRouteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollisionServiceServer).CheckRoute(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollisionService_CheckRoute_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollisionServiceServer).CheckRoute(ctx, req.(*CheckRouteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollisionService_StreamPositions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(CollisionServiceServer).StreamPositions(&grpc.GenericServerStream[PositionUpdate, PositionResult]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CollisionService_StreamPositionsServer = grpc.BidiStreamingServer[PositionUpdate, PositionResult]
+
+// CollisionService_ServiceDesc is the grpc.ServiceDesc for CollisionService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CollisionService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "collision.v1.CollisionService",
+	HandlerType: (*CollisionServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CheckPoint",
+			Handler:    _CollisionService_CheckPoint_Handler,
+		},
+		{
+			MethodName: "CheckBatch",
+			Handler:    _CollisionService_CheckBatch_Handler,
+		},
+		{
+			MethodName: "CheckRoute",
+			Handler:    _CollisionService_CheckRoute_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamPositions",
+			Handler:       _CollisionService_StreamPositions_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "collision/v1/collision.proto",
+}
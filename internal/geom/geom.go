@@ -0,0 +1,282 @@
+// internal/geom/geom.go
+// Package geom holds the planar building footprints read from and written to
+// PostGIS, with WKB and WKT encodings.
+package geom
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// WKB geometry types.
+const (
+	wkbPolygon      = 3
+	wkbMultiPolygon = 6
+)
+
+// Point is a longitude/latitude pair.
+type Point [2]float64
+
+// Ring is a closed sequence of points: the first and last are equal.
+type Ring []Point
+
+// Polygon is an exterior ring followed by its holes.
+type Polygon []Ring
+
+// MultiPolygon is a building footprint.
+type MultiPolygon []Polygon
+
+// BBox is an axis-aligned bounding box.
+type BBox struct {
+	MinX, MinY, MaxX, MaxY float64
+}
+
+// EmptyBBox returns a box that Extend grows from nothing.
+func EmptyBBox() BBox {
+	return BBox{MinX: math.Inf(1), MinY: math.Inf(1), MaxX: math.Inf(-1), MaxY: math.Inf(-1)}
+}
+
+// IsEmpty reports whether no point has been added to b.
+func (b BBox) IsEmpty() bool {
+	return b.MinX > b.MaxX
+}
+
+// Extend returns b grown to include o.
+func (b BBox) Extend(o BBox) BBox {
+	return BBox{
+		MinX: math.Min(b.MinX, o.MinX), MinY: math.Min(b.MinY, o.MinY),
+		MaxX: math.Max(b.MaxX, o.MaxX), MaxY: math.Max(b.MaxY, o.MaxY),
+	}
+}
+
+// Bounds returns the bounding box of m.
+func (m MultiPolygon) Bounds() BBox {
+	b := EmptyBBox()
+	for _, poly := range m {
+		for _, ring := range poly {
+			for _, p := range ring {
+				b.MinX, b.MaxX = math.Min(b.MinX, p[0]), math.Max(b.MaxX, p[0])
+				b.MinY, b.MaxY = math.Min(b.MinY, p[1]), math.Max(b.MaxY, p[1])
+			}
+		}
+	}
+	return b
+}
+
+// NumPoints returns the number of points in all rings of m.
+func (m MultiPolygon) NumPoints() int {
+	n := 0
+	for _, poly := range m {
+		for _, ring := range poly {
+			n += len(ring)
+		}
+	}
+	return n
+}
+
+// SignedArea returns the shoelace area of r: positive when r is counter-clockwise.
+func (r Ring) SignedArea() float64 {
+	sum := 0.0
+	for i := 0; i+1 < len(r); i++ {
+		sum += r[i][0]*r[i+1][1] - r[i+1][0]*r[i][1]
+	}
+	return sum / 2
+}
+
+// Reversed returns r with its points in the opposite order.
+func (r Ring) Reversed() Ring {
+	out := make(Ring, len(r))
+	for i, p := range r {
+		out[len(r)-1-i] = p
+	}
+	return out
+}
+
+// Oriented returns m with exterior rings counter-clockwise and holes clockwise when
+// ccw is true, or the other way round.
+func (m MultiPolygon) Oriented(ccw bool) MultiPolygon {
+	out := make(MultiPolygon, len(m))
+	for i, poly := range m {
+		out[i] = make(Polygon, len(poly))
+		for j, ring := range poly {
+			wantCCW := ccw == (j == 0)
+			if (ring.SignedArea() > 0) != wantCCW {
+				ring = ring.Reversed()
+			}
+			out[i][j] = ring
+		}
+	}
+	return out
+}
+
+// ErrInvalidWKB is returned for malformed or unsupported WKB.
+var ErrInvalidWKB = errors.New("invalid WKB")
+
+// ParseWKB decodes a Polygon or MultiPolygon from WKB. A Polygon is returned as a
+// MultiPolygon of one.
+func ParseWKB(b []byte) (MultiPolygon, error) {
+	r := &wkbReader{b: b}
+	typ := r.header()
+	var m MultiPolygon
+	switch typ {
+	case wkbPolygon:
+		m = MultiPolygon{r.polygon()}
+	case wkbMultiPolygon:
+		n := r.count()
+		for i := 0; i < n && r.err == nil; i++ {
+			if t := r.header(); t != wkbPolygon && r.err == nil {
+				r.err = fmt.Errorf("%w: type %d inside MultiPolygon", ErrInvalidWKB, t)
+			}
+			m = append(m, r.polygon())
+		}
+	default:
+		if r.err == nil {
+			r.err = fmt.Errorf("%w: unsupported type %d", ErrInvalidWKB, typ)
+		}
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return m, nil
+}
+
+type wkbReader struct {
+	b     []byte
+	order binary.ByteOrder
+	err   error
+}
+
+func (r *wkbReader) take(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if len(r.b) < n {
+		r.err = fmt.Errorf("%w: truncated", ErrInvalidWKB)
+		return nil
+	}
+	out := r.b[:n]
+	r.b = r.b[n:]
+	return out
+}
+
+// header reads the byte order and the geometry type, ignoring Z/M/SRID flags,
+// which building footprints do not use.
+func (r *wkbReader) header() uint32 {
+	order := r.take(1)
+	if order == nil {
+		return 0
+	}
+	r.order = binary.LittleEndian
+	if order[0] == 0 {
+		r.order = binary.BigEndian
+	}
+	b := r.take(4)
+	if b == nil {
+		return 0
+	}
+	typ := r.order.Uint32(b)
+	if typ&0xE0000000 != 0 || typ > 1000 {
+		r.err = fmt.Errorf("%w: only 2D geometries are supported", ErrInvalidWKB)
+	}
+	return typ
+}
+
+func (r *wkbReader) count() int {
+	b := r.take(4)
+	if b == nil {
+		return 0
+	}
+	n := int(r.order.Uint32(b))
+	if n > len(r.b) {
+		r.err = fmt.Errorf("%w: count %d exceeds data", ErrInvalidWKB, n)
+		return 0
+	}
+	return n
+}
+
+func (r *wkbReader) polygon() Polygon {
+	n := r.count()
+	poly := make(Polygon, 0, n)
+	for i := 0; i < n && r.err == nil; i++ {
+		points := r.count()
+		ring := make(Ring, 0, points)
+		for j := 0; j < points && r.err == nil; j++ {
+			b := r.take(16)
+			if b == nil {
+				break
+			}
+			ring = append(ring, Point{
+				math.Float64frombits(r.order.Uint64(b[:8])),
+				math.Float64frombits(r.order.Uint64(b[8:])),
+			})
+		}
+		poly = append(poly, ring)
+	}
+	return poly
+}
+
+// WKB encodes m as little-endian WKB.
+func (m MultiPolygon) WKB() []byte {
+	size := 9
+	for _, poly := range m {
+		size += 9
+		for _, ring := range poly {
+			size += 4 + 16*len(ring)
+		}
+	}
+	b := make([]byte, 0, size)
+	le := binary.LittleEndian
+	b = append(b, 1)
+	b = le.AppendUint32(b, wkbMultiPolygon)
+	b = le.AppendUint32(b, uint32(len(m)))
+	for _, poly := range m {
+		b = append(b, 1)
+		b = le.AppendUint32(b, wkbPolygon)
+		b = le.AppendUint32(b, uint32(len(poly)))
+		for _, ring := range poly {
+			b = le.AppendUint32(b, uint32(len(ring)))
+			for _, p := range ring {
+				b = le.AppendUint64(b, math.Float64bits(p[0]))
+				b = le.AppendUint64(b, math.Float64bits(p[1]))
+			}
+		}
+	}
+	return b
+}
+
+// WKT formats m as MULTIPOLYGON WKT, the format the import reads.
+func (m MultiPolygon) WKT() string {
+	if len(m) == 0 {
+		return "MULTIPOLYGON EMPTY"
+	}
+	var sb strings.Builder
+	sb.WriteString("MULTIPOLYGON(")
+	for i, poly := range m {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteByte('(')
+		for j, ring := range poly {
+			if j > 0 {
+				sb.WriteByte(',')
+			}
+			sb.WriteByte('(')
+			for k, p := range ring {
+				if k > 0 {
+					sb.WriteByte(',')
+				}
+				sb.WriteString(strconv.FormatFloat(p[0], 'f', -1, 64))
+				sb.WriteByte(' ')
+				sb.WriteString(strconv.FormatFloat(p[1], 'f', -1, 64))
+			}
+			sb.WriteByte(')')
+		}
+		sb.WriteByte(')')
+	}
+	sb.WriteByte(')')
+	return sb.String()
+}
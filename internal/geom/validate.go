@@ -0,0 +1,224 @@
+// internal/geom/validate.go
+package geom
+
+import (
+	"fmt"
+	"math"
+)
+
+// Reasons a footprint fails Validate.
+const (
+	ReasonEmpty            = "empty"
+	ReasonUnclosedRing     = "unclosed_ring"
+	ReasonTooFewPoints     = "too_few_points"
+	ReasonOutOfRange       = "coordinate_out_of_range"
+	ReasonZeroArea         = "zero_area"
+	ReasonSelfIntersection = "self_intersection"
+)
+
+// Problem describes why a footprint is invalid.
+type Problem struct {
+	Reason string // one of the Reason constants
+	Detail string // where the problem was found
+}
+
+func (p *Problem) Error() string {
+	return p.Reason + ": " + p.Detail
+}
+
+// Repairable reports whether ST_MakeValid can produce a footprint from the
+// geometry, once its rings are closed.
+func (p *Problem) Repairable() bool {
+	switch p.Reason {
+	case ReasonUnclosedRing, ReasonZeroArea, ReasonSelfIntersection:
+		return true
+	}
+	return false
+}
+
+// Validate checks that m is a usable longitude/latitude footprint: it is not empty,
+// every ring is closed, has at least four points and encloses an area, coordinates
+// are within [-180, 180] x [-90, 90], no ring crosses or touches itself, and rings of
+// one polygon cross each other nowhere (they may touch at points). It returns the
+// first problem found, or nil.
+//
+// Ring orientation is not an error: see IsOriented. Relations between polygons and
+// between a shell and its holes are left to PostGIS (ST_IsValid).
+func Validate(m MultiPolygon) *Problem {
+	if len(m) == 0 {
+		return &Problem{ReasonEmpty, "the geometry has no polygons"}
+	}
+	for i, poly := range m {
+		if len(poly) == 0 {
+			return &Problem{ReasonEmpty, fmt.Sprintf("polygon %d has no rings", i+1)}
+		}
+		for j, ring := range poly {
+			where := ringName(i, j)
+			if len(ring) > 0 && ring[0] != ring[len(ring)-1] {
+				return &Problem{ReasonUnclosedRing, where + " does not end at its first point"}
+			}
+			if len(ring) < 4 {
+				return &Problem{ReasonTooFewPoints, fmt.Sprintf("%s has %d points, at least 4 are required", where, len(ring))}
+			}
+			for k, pt := range ring {
+				if !(pt[0] >= -180 && pt[0] <= 180 && pt[1] >= -90 && pt[1] <= 90) {
+					return &Problem{ReasonOutOfRange, fmt.Sprintf("point %d of %s is (%g %g)", k+1, where, pt[0], pt[1])}
+				}
+			}
+			if a, b, ok := ring.selfIntersection(); ok {
+				return &Problem{ReasonSelfIntersection, fmt.Sprintf("segments %d and %d of %s intersect", a+1, b+1, where)}
+			}
+			if ring.SignedArea() == 0 {
+				return &Problem{ReasonZeroArea, where + " encloses no area"}
+			}
+		}
+		for j := 0; j < len(poly); j++ {
+			for k := j + 1; k < len(poly); k++ {
+				if ringsCross(poly[j], poly[k]) {
+					return &Problem{ReasonSelfIntersection, fmt.Sprintf("%s crosses %s", ringName(i, j), ringName(i, k))}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func ringName(poly, ring int) string {
+	if ring == 0 {
+		return fmt.Sprintf("the exterior ring of polygon %d", poly+1)
+	}
+	return fmt.Sprintf("hole %d of polygon %d", ring, poly+1)
+}
+
+// IsOriented reports whether every exterior ring of m is counter-clockwise and every
+// hole clockwise when ccw is true, or the other way round.
+func (m MultiPolygon) IsOriented(ccw bool) bool {
+	for _, poly := range m {
+		for j, ring := range poly {
+			if area := ring.SignedArea(); area != 0 && (area > 0) != (ccw == (j == 0)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Closed returns m with every unclosed ring closed by repeating its first point.
+func (m MultiPolygon) Closed() MultiPolygon {
+	out := make(MultiPolygon, len(m))
+	for i, poly := range m {
+		out[i] = make(Polygon, len(poly))
+		for j, ring := range poly {
+			if len(ring) > 0 && ring[0] != ring[len(ring)-1] {
+				ring = append(ring[:len(ring):len(ring)], ring[0])
+			}
+			out[i][j] = ring
+		}
+	}
+	return out
+}
+
+// selfIntersection returns the indexes of two segments of the closed ring r that
+// intersect other than at the point shared by neighbours. Repeated points are
+// ignored.
+func (r Ring) selfIntersection() (int, int, bool) {
+	// Drop repeated points, remembering the index of each segment in r
+	pts := make([]Point, 0, len(r))
+	idx := make([]int, 0, len(r))
+	for i, p := range r {
+		if len(pts) == 0 || p != pts[len(pts)-1] {
+			pts = append(pts, p)
+			idx = append(idx, i)
+		}
+	}
+	n := len(pts) - 1 // segments
+	for i := 0; i < n; i++ {
+		a, b := pts[i], pts[i+1]
+		for j := i + 1; j < n; j++ {
+			c, d := pts[j], pts[j+1]
+			// Neighbours share a point (b == c, or d == a for the last and first
+			// segments) and are only invalid when they fold back over each other
+			var hit bool
+			switch {
+			case j == i+1:
+				hit = orientation(a, b, d) == 0 && dot(a, b, d) > 0
+			case i == 0 && j == n-1:
+				hit = orientation(c, a, b) == 0 && dot(c, a, b) > 0
+			default:
+				hit = segmentsIntersect(a, b, c, d)
+			}
+			if hit {
+				return idx[i], idx[j], true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// ringsCross reports whether two rings of a polygon cross or overlap along a
+// segment. Touching at isolated points is allowed.
+func ringsCross(r, s Ring) bool {
+	for i := 0; i+1 < len(r); i++ {
+		for j := 0; j+1 < len(s); j++ {
+			a, b, c, d := r[i], r[i+1], s[j], s[j+1]
+			o1, o2 := orientation(a, b, c), orientation(a, b, d)
+			o3, o4 := orientation(c, d, a), orientation(c, d, b)
+			if o1*o2 < 0 && o3*o4 < 0 {
+				return true // proper crossing
+			}
+			if o1 == 0 && o2 == 0 && a != b && c != d && collinearOverlap(a, b, c, d) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// orientation returns the sign of the turn a -> b -> c: positive for a left turn,
+// negative for a right turn and 0 when the points are collinear.
+func orientation(a, b, c Point) float64 {
+	v := (b[0]-a[0])*(c[1]-a[1]) - (b[1]-a[1])*(c[0]-a[0])
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	}
+	return 0
+}
+
+// dot returns the dot product of (a - b) and (c - b), positive when c turns back
+// towards a.
+func dot(a, b, c Point) float64 {
+	return (a[0]-b[0])*(c[0]-b[0]) + (a[1]-b[1])*(c[1]-b[1])
+}
+
+// onSegment reports whether p, collinear with a and b, lies within their box.
+func onSegment(a, b, p Point) bool {
+	return math.Min(a[0], b[0]) <= p[0] && p[0] <= math.Max(a[0], b[0]) &&
+		math.Min(a[1], b[1]) <= p[1] && p[1] <= math.Max(a[1], b[1])
+}
+
+// segmentsIntersect reports whether segments ab and cd share any point.
+func segmentsIntersect(a, b, c, d Point) bool {
+	o1, o2 := orientation(a, b, c), orientation(a, b, d)
+	o3, o4 := orientation(c, d, a), orientation(c, d, b)
+	if o1*o2 < 0 && o3*o4 < 0 {
+		return true
+	}
+	return (o1 == 0 && onSegment(a, b, c)) || (o2 == 0 && onSegment(a, b, d)) ||
+		(o3 == 0 && onSegment(c, d, a)) || (o4 == 0 && onSegment(c, d, b))
+}
+
+// collinearOverlap reports whether collinear segments ab and cd share more than a
+// single point.
+func collinearOverlap(a, b, c, d Point) bool {
+	// Project onto the longer axis of ab
+	axis := 0
+	if math.Abs(b[1]-a[1]) > math.Abs(b[0]-a[0]) {
+		axis = 1
+	}
+	lo1, hi1 := math.Min(a[axis], b[axis]), math.Max(a[axis], b[axis])
+	lo2, hi2 := math.Min(c[axis], d[axis]), math.Max(c[axis], d[axis])
+	return math.Min(hi1, hi2) > math.Max(lo1, lo2)
+}
@@ -0,0 +1,134 @@
+package geom
+
+import (
+	"math"
+	"testing"
+)
+
+// ring builds a ring from x, y pairs.
+func ring(coords ...float64) Ring {
+	r := make(Ring, 0, len(coords)/2)
+	for i := 0; i+1 < len(coords); i += 2 {
+		r = append(r, Point{coords[i], coords[i+1]})
+	}
+	return r
+}
+
+func TestValidate(t *testing.T) {
+	square := ring(0, 0, 4, 0, 4, 4, 0, 4, 0, 0)
+
+	tests := []struct {
+		name   string
+		m      MultiPolygon
+		reason string // "" when valid
+	}{
+		{"square", MultiPolygon{{square}}, ""},
+		{"clockwise square", MultiPolygon{{square.Reversed()}}, ""},
+		{"repeated points", MultiPolygon{{ring(0, 0, 1, 0, 1, 0, 1, 1, 0, 1, 0, 0)}}, ""},
+		{"collinear neighbours", MultiPolygon{{ring(0, 0, 1, 0, 2, 0, 2, 1, 0, 0)}}, ""},
+		{"hole", MultiPolygon{{square, ring(1, 1, 1, 2, 2, 2, 2, 1, 1, 1)}}, ""},
+		{"hole touching the shell at a point", MultiPolygon{{square, ring(2, 0, 3, 1, 1, 1, 2, 0)}}, ""},
+		{"two polygons", MultiPolygon{{square}, {ring(5, 5, 6, 5, 6, 6, 5, 5)}}, ""},
+
+		{"no polygons", MultiPolygon{}, ReasonEmpty},
+		{"polygon without rings", MultiPolygon{{}}, ReasonEmpty},
+		{"unclosed ring", MultiPolygon{{ring(0, 0, 4, 0, 4, 4, 0, 4)}}, ReasonUnclosedRing},
+		{"unclosed hole", MultiPolygon{{square, ring(1, 1, 1, 2, 2, 2, 2, 1)}}, ReasonUnclosedRing},
+		{"empty ring", MultiPolygon{{Ring{}}}, ReasonTooFewPoints},
+		{"closed ring of three points", MultiPolygon{{ring(0, 0, 1, 0, 0, 0)}}, ReasonTooFewPoints},
+		{"longitude out of range", MultiPolygon{{ring(179, 0, 181, 0, 181, 1, 179, 0)}}, ReasonOutOfRange},
+		{"latitude out of range", MultiPolygon{{ring(0, 89, 1, 89, 1, 91, 0, 89)}}, ReasonOutOfRange},
+		{"NaN coordinate", MultiPolygon{{ring(0, 0, math.NaN(), 0, 1, 1, 0, 0)}}, ReasonOutOfRange},
+		{"infinite coordinate", MultiPolygon{{ring(0, 0, math.Inf(1), 0, 1, 1, 0, 0)}}, ReasonOutOfRange},
+		{"single repeated point", MultiPolygon{{ring(0, 0, 0, 0, 0, 0, 0, 0)}}, ReasonZeroArea},
+		{"bow tie", MultiPolygon{{ring(0, 0, 1, 1, 1, 0, 0, 1, 0, 0)}}, ReasonSelfIntersection},
+		{"ring touching itself", MultiPolygon{{ring(0, 0, 4, 0, 2, 2, 4, 4, 0, 4, 2, 2, 0, 0)}}, ReasonSelfIntersection},
+		{"neighbours folding back", MultiPolygon{{ring(0, 0, 2, 0, 1, 0, 1, 1, 0, 0)}}, ReasonSelfIntersection},
+		{"last and first segments folding back", MultiPolygon{{ring(1, 0, 2, 0, 2, 1, 3, 0, 1, 0)}}, ReasonSelfIntersection},
+		{"flat ring", MultiPolygon{{ring(0, 0, 1, 0, 2, 0, 0, 0)}}, ReasonSelfIntersection},
+		{"hole crossing the shell", MultiPolygon{{square, ring(1, 1, 5, 1, 5, 2, 1, 2, 1, 1)}}, ReasonSelfIntersection},
+		{"hole sharing an edge with the shell", MultiPolygon{{square, ring(1, 0, 3, 0, 2, 1, 1, 0)}}, ReasonSelfIntersection},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Validate(tt.m)
+			switch {
+			case tt.reason == "" && p != nil:
+				t.Fatalf("Validate() = %v, want nil", p)
+			case tt.reason != "" && p == nil:
+				t.Fatalf("Validate() = nil, want %s", tt.reason)
+			case p != nil && p.Reason != tt.reason:
+				t.Fatalf("Validate() = %v, want %s", p, tt.reason)
+			}
+		})
+	}
+}
+
+func TestValidateDetail(t *testing.T) {
+	p := Validate(MultiPolygon{{ring(1, 0, 2, 0, 2, 1, 3, 0, 1, 0)}})
+	if want := "segments 1 and 4 of the exterior ring of polygon 1 intersect"; p == nil || p.Detail != want {
+		t.Fatalf("Validate() = %v, want detail %q", p, want)
+	}
+}
+
+func TestProblemRepairable(t *testing.T) {
+	tests := []struct {
+		reason string
+		want   bool
+	}{
+		{ReasonUnclosedRing, true},
+		{ReasonZeroArea, true},
+		{ReasonSelfIntersection, true},
+		{ReasonEmpty, false},
+		{ReasonTooFewPoints, false},
+		{ReasonOutOfRange, false},
+	}
+	for _, tt := range tests {
+		if got := (&Problem{Reason: tt.reason}).Repairable(); got != tt.want {
+			t.Errorf("Repairable() for %s = %v, want %v", tt.reason, got, tt.want)
+		}
+	}
+}
+
+func TestClosed(t *testing.T) {
+	open := ring(0, 0, 4, 0, 4, 4, 0, 4)
+	m := MultiPolygon{{open, ring(1, 1, 1, 2, 2, 2, 1, 1)}}
+
+	closed := m.Closed()
+	if p := Validate(closed); p != nil {
+		t.Fatalf("Validate(Closed()) = %v, want nil", p)
+	}
+	if got := len(closed[0][0]); got != 5 {
+		t.Errorf("closed exterior has %d points, want 5", got)
+	}
+	if got := len(closed[0][1]); got != 4 {
+		t.Errorf("closed hole has %d points, want 4", got)
+	}
+	if len(open) != 4 || len(m[0][0]) != 4 {
+		t.Errorf("Closed() modified its input")
+	}
+}
+
+func TestIsOriented(t *testing.T) {
+	ccw := ring(0, 0, 4, 0, 4, 4, 0, 4, 0, 0)
+	cw := ring(1, 1, 1, 2, 2, 2, 2, 1, 1, 1)
+
+	tests := []struct {
+		name string
+		m    MultiPolygon
+		ccw  bool
+		want bool
+	}{
+		{"counter-clockwise shell", MultiPolygon{{ccw}}, true, true},
+		{"counter-clockwise shell, clockwise wanted", MultiPolygon{{ccw}}, false, false},
+		{"clockwise shell", MultiPolygon{{ccw.Reversed()}}, false, true},
+		{"clockwise hole", MultiPolygon{{ccw, cw}}, true, true},
+		{"counter-clockwise hole", MultiPolygon{{ccw, cw.Reversed()}}, true, false},
+		{"zero area ring", MultiPolygon{{ring(0, 0, 0, 0, 0, 0, 0, 0)}}, false, true},
+	}
+	for _, tt := range tests {
+		if got := tt.m.IsOriented(tt.ccw); got != tt.want {
+			t.Errorf("%s: IsOriented(%v) = %v, want %v", tt.name, tt.ccw, got, tt.want)
+		}
+	}
+}
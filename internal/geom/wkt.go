@@ -0,0 +1,171 @@
+// internal/geom/wkt.go
+package geom
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidWKT is returned for malformed or unsupported WKT.
+var ErrInvalidWKT = errors.New("invalid WKT")
+
+// ParseWKT parses a POLYGON or MULTIPOLYGON from WKT. Keywords are case-insensitive
+// and a Polygon is returned as a MultiPolygon of one. Only 2D coordinates are
+// accepted. Rings are returned as written: closure and the other rules of a valid
+// footprint are checked by Validate.
+func ParseWKT(s string) (MultiPolygon, error) {
+	p := &wktParser{s: s}
+	var m MultiPolygon
+	switch typ := strings.ToUpper(p.word()); typ {
+	case "POLYGON":
+		if p.empty() {
+			return MultiPolygon{}, p.end()
+		}
+		m = MultiPolygon{p.polygon()}
+	case "MULTIPOLYGON":
+		if p.empty() {
+			return MultiPolygon{}, p.end()
+		}
+		p.expect('(')
+		for p.err == nil {
+			m = append(m, p.polygon())
+			if !p.next(',') {
+				break
+			}
+		}
+		p.expect(')')
+	case "":
+		p.fail("expected a geometry type")
+	default:
+		p.fail("unsupported geometry type %s", typ)
+	}
+	if err := p.end(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+type wktParser struct {
+	s   string
+	pos int
+	err error
+}
+
+func (p *wktParser) fail(format string, args ...any) {
+	if p.err == nil {
+		p.err = fmt.Errorf("%w at offset %d: %s", ErrInvalidWKT, p.pos, fmt.Sprintf(format, args...))
+	}
+}
+
+func (p *wktParser) skipSpace() {
+	for p.pos < len(p.s) && strings.IndexByte(" \t\r\n", p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// word reads a keyword.
+func (p *wktParser) word() string {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.s) {
+		c := p.s[p.pos] | 0x20 // lower case
+		if c < 'a' || c > 'z' {
+			break
+		}
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+// empty reports whether the geometry is EMPTY, and rejects Z, M and ZM geometries.
+func (p *wktParser) empty() bool {
+	save := p.pos
+	switch w := strings.ToUpper(p.word()); w {
+	case "EMPTY":
+		return true
+	case "":
+		p.pos = save
+		return false
+	case "Z", "M", "ZM":
+		p.fail("only 2D geometries are supported")
+	default:
+		p.fail("unexpected %s", w)
+	}
+	return false
+}
+
+// next consumes c if it is the next character.
+func (p *wktParser) next(c byte) bool {
+	p.skipSpace()
+	if p.err == nil && p.pos < len(p.s) && p.s[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *wktParser) expect(c byte) {
+	if !p.next(c) {
+		p.fail("expected %q", c)
+	}
+}
+
+// end checks that nothing follows the geometry.
+func (p *wktParser) end() error {
+	p.skipSpace()
+	if p.err == nil && p.pos < len(p.s) {
+		p.fail("unexpected text after the geometry")
+	}
+	return p.err
+}
+
+func (p *wktParser) polygon() Polygon {
+	var poly Polygon
+	p.expect('(')
+	for p.err == nil {
+		poly = append(poly, p.ring())
+		if !p.next(',') {
+			break
+		}
+	}
+	p.expect(')')
+	return poly
+}
+
+func (p *wktParser) ring() Ring {
+	var ring Ring
+	p.expect('(')
+	for p.err == nil {
+		x := p.number()
+		y := p.number()
+		ring = append(ring, Point{x, y})
+		if !p.next(',') {
+			break
+		}
+	}
+	p.skipSpace()
+	if p.err == nil && p.pos < len(p.s) && p.s[p.pos] != ')' {
+		p.fail("expected 2 coordinates per point")
+	}
+	p.expect(')')
+	return ring
+}
+
+func (p *wktParser) number() float64 {
+	p.skipSpace()
+	if p.err != nil {
+		return 0
+	}
+	start := p.pos
+	for p.pos < len(p.s) && strings.IndexByte("+-.0123456789eE", p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+	v, err := strconv.ParseFloat(p.s[start:p.pos], 64)
+	if err != nil {
+		p.pos = start
+		p.fail("expected a number")
+	}
+	return v
+}
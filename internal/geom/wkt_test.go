@@ -0,0 +1,94 @@
+package geom
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseWKT(t *testing.T) {
+	square := ring(0, 0, 1, 0, 1, 1, 0, 1, 0, 0)
+
+	tests := []struct {
+		name string
+		wkt  string
+		want MultiPolygon
+	}{
+		{"polygon", "POLYGON((0 0,1 0,1 1,0 1,0 0))", MultiPolygon{{square}}},
+		{"lower case", "polygon((0 0,1 0,1 1,0 1,0 0))", MultiPolygon{{square}}},
+		{"mixed case", "MultiPolygon(((0 0,1 0,1 1,0 1,0 0)))", MultiPolygon{{square}}},
+		{"whitespace", " POLYGON ( ( 0 0 ,\t1 0,\n1 1 , 0 1,0 0 ) ) ", MultiPolygon{{square}}},
+		{"signs and exponents", "POLYGON((-1.5 +2,1e-3 2,1E1 -0.25,-1.5 +2))",
+			MultiPolygon{{ring(-1.5, 2, 0.001, 2, 10, -0.25, -1.5, 2)}}},
+		{"unclosed ring kept as written", "POLYGON((0 0,1 0,1 1))", MultiPolygon{{ring(0, 0, 1, 0, 1, 1)}}},
+		{"polygon with hole", "POLYGON((0 0,4 0,4 4,0 0),(1 1,2 1,2 2,1 1))",
+			MultiPolygon{{ring(0, 0, 4, 0, 4, 4, 0, 0), ring(1, 1, 2, 1, 2, 2, 1, 1)}}},
+		{"multipolygon", "MULTIPOLYGON(((0 0,1 0,1 1,0 1,0 0)),((5 5,6 5,6 6,5 5)))",
+			MultiPolygon{{square}, {ring(5, 5, 6, 5, 6, 6, 5, 5)}}},
+		{"empty polygon", "POLYGON EMPTY", MultiPolygon{}},
+		{"empty multipolygon", "multipolygon empty", MultiPolygon{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseWKT(tt.wkt)
+			if err != nil {
+				t.Fatalf("ParseWKT(%q) error: %v", tt.wkt, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ParseWKT(%q) = %v, want %v", tt.wkt, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseWKTErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		wkt  string
+	}{
+		{"empty string", ""},
+		{"blank", "   "},
+		{"unsupported type", "POINT(1 2)"},
+		{"linestring", "LINESTRING(0 0,1 1)"},
+		{"3D", "POLYGON Z ((0 0 0,1 0 0,1 1 0,0 0 0))"},
+		{"measured", "POLYGON M ((0 0 0,1 0 0,1 1 0,0 0 0))"},
+		{"unexpected keyword", "POLYGON FOO"},
+		{"three coordinates", "POLYGON((0 0 1,1 0 1,1 1 1,0 0 1))"},
+		{"one coordinate", "POLYGON((0,1 0,1 1,0 0))"},
+		{"missing ring parenthesis", "POLYGON(0 0,1 0,1 1,0 0)"},
+		{"missing polygon parenthesis", "MULTIPOLYGON((0 0,1 0,1 1,0 0))"},
+		{"unterminated", "POLYGON((0 0,1 0,1 1,0 0)"},
+		{"trailing comma", "POLYGON((0 0,1 0,1 1,0 0,))"},
+		{"text after geometry", "POLYGON((0 0,1 0,1 1,0 0)) x"},
+		{"text after empty", "POLYGON EMPTY ()"},
+		{"not a number", "POLYGON((0 0,a 0,1 1,0 0))"},
+		{"NaN", "POLYGON((0 0,NaN 0,1 1,0 0))"},
+		{"overflow", "POLYGON((0 0,1e999 0,1 1,0 0))"},
+		{"malformed number", "POLYGON((0 0,1..2 0,1 1,0 0))"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := ParseWKT(tt.wkt)
+			if !errors.Is(err, ErrInvalidWKT) {
+				t.Fatalf("ParseWKT(%q) = %v, %v; want ErrInvalidWKT", tt.wkt, m, err)
+			}
+		})
+	}
+}
+
+func TestWKTRoundTrip(t *testing.T) {
+	tests := []MultiPolygon{
+		{},
+		{{ring(120.123456789, 30.5, 120.2, 30.5, 120.2, 30.6, 120.123456789, 30.5)}},
+		{{ring(0, 0, 4, 0, 4, 4, 0, 0), ring(1, 1, 2, 1, 2, 2, 1, 1)}, {ring(-5, -5, -6, -5, -6, -6, -5, -5)}},
+	}
+	for _, m := range tests {
+		got, err := ParseWKT(m.WKT())
+		if err != nil {
+			t.Fatalf("ParseWKT(%q) error: %v", m.WKT(), err)
+		}
+		if !reflect.DeepEqual(got, m) {
+			t.Errorf("ParseWKT(%q) = %v, want %v", m.WKT(), got, m)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// internal/grpcserver/convert.go
+package grpcserver
+
+import (
+	"math"
+
+	"collision_app_go/internal/apperr"
+	collisionv1 "collision_app_go/internal/gen/collision/v1"
+	"collision_app_go/internal/model"
+)
+
+// fromPosition validates a position with the same bounds as the HTTP API.
+func fromPosition(p *collisionv1.Position, name string) (model.Point3D, error) {
+	if p == nil {
+		return model.Point3D{}, apperr.InvalidArgument(name, "required")
+	}
+	switch {
+	case !finite(p.GetLongitude()) || p.GetLongitude() < -180 || p.GetLongitude() > 180:
+		return model.Point3D{}, apperr.InvalidArgument(name+".longitude", "range", "[-180, 180]")
+	case !finite(p.GetLatitude()) || p.GetLatitude() < -90 || p.GetLatitude() > 90:
+		return model.Point3D{}, apperr.InvalidArgument(name+".latitude", "range", "[-90, 90]")
+	case !finite(p.GetHeight()) || p.GetHeight() < 0:
+		return model.Point3D{}, apperr.InvalidArgument(name+".height", "not_negative")
+	}
+	return model.Point3D{Longitude: p.GetLongitude(), Latitude: p.GetLatitude(), Height: p.GetHeight()}, nil
+}
+
+func fromFootprint(f *collisionv1.Footprint) (model.Footprint, error) {
+	footprint := model.Footprint{
+		DroneRadius:           f.GetDroneRadius(),
+		HorizontalUncertainty: f.GetHorizontalUncertainty(),
+		VerticalUncertainty:   f.GetVerticalUncertainty(),
+	}
+	for name, v := range map[string]float64{
+		"footprint.drone_radius":           footprint.DroneRadius,
+		"footprint.horizontal_uncertainty": footprint.HorizontalUncertainty,
+		"footprint.vertical_uncertainty":   footprint.VerticalUncertainty,
+	} {
+		if !finite(v) || v < 0 {
+			return model.Footprint{}, apperr.InvalidArgument(name, "not_negative")
+		}
+	}
+	return footprint, nil
+}
+
+func finite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
+
+func toMargin(m model.MarginBreakdown) *collisionv1.Margin {
+	return &collisionv1.Margin{
+		ConfiguredHorizontal:  m.ConfiguredHorizontal,
+		ConfiguredVertical:    m.ConfiguredVertical,
+		DroneRadius:           m.DroneRadius,
+		HorizontalUncertainty: m.HorizontalUncertainty,
+		VerticalUncertainty:   m.VerticalUncertainty,
+		TotalHorizontal:       m.TotalHorizontal,
+		TotalVertical:         m.TotalVertical,
+	}
+}
+
+func toBuilding(b model.Building) *collisionv1.Building {
+	return &collisionv1.Building{
+		BuildingId:     b.BuildingID,
+		BuildingName:   b.BuildingName,
+		BuildingHeight: b.BuildingHeight,
+	}
+}
+
+func toPointResponse(r *model.CollisionResult) *collisionv1.CheckPointResponse {
+	resp := &collisionv1.CheckPointResponse{
+		IsCollision: r.IsCollision,
+		Mode:        collisionv1.Mode_MODE_2_5D,
+		Margin:      toMargin(r.Margin),
+	}
+	for _, b := range r.BuildingInfos {
+		resp.Buildings = append(resp.Buildings, toBuilding(b))
+	}
+	return resp
+}
+
+func toSolidResponse(r *model.SolidCollisionResult) *collisionv1.CheckPointResponse {
+	resp := &collisionv1.CheckPointResponse{
+		IsCollision: r.IsCollision,
+		Mode:        collisionv1.Mode_MODE_3D,
+		Margin:      toMargin(r.Margin),
+	}
+	for _, hit := range r.BuildingInfos {
+		b := toBuilding(hit.Building)
+		partID, distance := int32(hit.PartID), hit.Distance
+		b.PartId, b.Distance = &partID, &distance
+		resp.Buildings = append(resp.Buildings, b)
+	}
+	return resp
+}
+
+func toRouteResponse(r *model.RouteResult) *collisionv1.CheckRouteResponse {
+	resp := &collisionv1.CheckRouteResponse{
+		IsCollision: r.IsCollision,
+		Margin:      toMargin(r.Margin),
+	}
+	for _, leg := range r.Legs {
+		out := &collisionv1.RouteLeg{Index: int32(leg.Index)}
+		for _, bd := range leg.Buildings {
+			b := toBuilding(bd.Building)
+			distance := bd.Distance
+			b.Distance = &distance
+			out.Buildings = append(out.Buildings, b)
+		}
+		resp.Legs = append(resp.Legs, out)
+	}
+	return resp
+}
+
+// toErrorMessage renders an error inside a batch or stream result.
+func toErrorMessage(err error, lang apperr.Lang) *collisionv1.Error {
+	e := apperr.From(err)
+	return &collisionv1.Error{Code: string(e.Code), Message: e.Message(lang)}
+}
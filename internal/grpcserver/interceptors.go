@@ -0,0 +1,316 @@
+// internal/grpcserver/interceptors.go
+package grpcserver
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"collision_app_go/internal/apperr"
+	"collision_app_go/internal/metrics"
+	"collision_app_go/internal/model"
+	"collision_app_go/internal/ratelimit"
+	"collision_app_go/internal/service"
+	"collision_app_go/utils"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Metadata keys. gRPC metadata keys are lower case.
+const (
+	apiKeyMetadata    = "x-api-key"
+	requestIDMetadata = "x-request-id"
+	// errorCodeTrailer carries the stable apperr code of a failed call.
+	errorCodeTrailer = "error-code"
+)
+
+// anonymous is the principal of every call when authentication is disabled.
+var anonymous = &model.Principal{Subject: "anonymous", Role: model.RoleAdmin, Method: model.AuthMethodNone}
+
+// interceptors mirror the HTTP middleware chain: request ID, access log, metrics,
+// panic recovery, authentication, viewer role, rate limits and error mapping.
+type interceptors struct {
+	auth      *service.AuthService
+	ipLimiter *ratelimit.Limiter
+	limiter   *ratelimit.Limiter
+	quota     *ratelimit.Quota
+}
+
+func (i *interceptors) unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	ctx = withRequestID(ctx)
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			utils.Error(ctx, "panic while handling grpc call", "method", info.FullMethod, "panic", r)
+			err = apperr.New(apperr.CodeInternal)
+		}
+		err = toStatus(ctx, err)
+		observe(ctx, info.FullMethod, start, err)
+	}()
+
+	ctx, err = i.admit(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := i.charge(ctx); err != nil {
+		return nil, err
+	}
+	return handler(context.WithValue(ctx, chargeKey{}, i.charge), req)
+}
+
+func (i *interceptors) stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	ctx := withRequestID(ss.Context())
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			utils.Error(ctx, "panic while handling grpc stream", "method", info.FullMethod, "panic", r)
+			err = apperr.New(apperr.CodeInternal)
+		}
+		err = toStatus(ctx, err)
+		observe(ctx, info.FullMethod, start, err)
+	}()
+
+	// Opening a stream is free: every received message is charged by the handler
+	ctx, err = i.admit(ctx)
+	if err != nil {
+		return err
+	}
+	ctx = context.WithValue(ctx, chargeKey{}, i.charge)
+	return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+}
+
+// chargeKey carries the caller's charge function to the handlers, which call it
+// for each check beyond the one paid for by the call: batch points and stream
+// messages.
+type chargeKey struct{}
+
+// charge takes one request from the rate limit and daily quota of the caller in ctx,
+// using the charge function set by the interceptors. Without one it does nothing.
+func charge(ctx context.Context) error {
+	if f, ok := ctx.Value(chargeKey{}).(func(context.Context) error); ok {
+		return f(ctx)
+	}
+	return nil
+}
+
+// admit applies the per-IP limit, authenticates the caller and requires the viewer
+// role. It returns the context carrying the principal.
+func (i *interceptors) admit(ctx context.Context) (context.Context, error) {
+	if i.ipLimiter != nil {
+		key := "ip:" + peerAddr(ctx)
+		if ok, _, retryAfter := i.ipLimiter.Allow(key); !ok {
+			return ctx, rateLimited(ctx, "ip", key, retryAfter, apperr.CodeRateLimited)
+		}
+	}
+	principal := anonymous
+	if i.auth != nil {
+		var err error
+		principal, err = i.authenticate(ctx)
+		if err != nil {
+			return ctx, err
+		}
+	}
+	ctx = service.WithPrincipal(ctx, principal)
+	ctx = service.WithOrigin(ctx, model.SourceGRPC, peerAddr(ctx))
+	if !model.RoleAllows(principal.Role, model.RoleViewer) {
+		utils.Warn(ctx, "access denied", "subject", principal.Subject, "role", principal.Role, "required_role", model.RoleViewer)
+		return ctx, apperr.New(apperr.CodePermissionDenied, model.RoleViewer)
+	}
+	return ctx, nil
+}
+
+// charge applies the rate limit and daily quota to the admitted caller in ctx.
+func (i *interceptors) charge(ctx context.Context) error {
+	key := clientKey(ctx, service.PrincipalFromContext(ctx))
+	if i.limiter != nil {
+		if ok, _, retryAfter := i.limiter.Allow(key); !ok {
+			return rateLimited(ctx, "default", key, retryAfter, apperr.CodeRateLimited)
+		}
+	}
+	if i.quota != nil {
+		if ok, _, retryAfter := i.quota.Take(key); !ok {
+			return rateLimited(ctx, "quota", key, retryAfter, apperr.CodeQuotaExceeded)
+		}
+	}
+	return nil
+}
+
+// authenticate resolves the caller from the x-api-key or authorization metadata,
+// like middleware.Authenticate.
+func (i *interceptors) authenticate(ctx context.Context) (*model.Principal, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var principal *model.Principal
+	var err error
+	if key := first(md, apiKeyMetadata); key != "" {
+		principal, err = i.auth.AuthenticateAPIKey(ctx, key)
+	} else if scheme, token, ok := strings.Cut(first(md, "authorization"), " "); ok && strings.EqualFold(scheme, "Bearer") && strings.TrimSpace(token) != "" {
+		token = strings.TrimSpace(token)
+		if strings.HasPrefix(token, service.APIKeyPrefix) {
+			principal, err = i.auth.AuthenticateAPIKey(ctx, token)
+		} else {
+			principal, err = i.auth.AuthenticateToken(ctx, token)
+		}
+	} else {
+		return nil, apperr.New(apperr.CodeUnauthenticated)
+	}
+
+	if err != nil {
+		if errors.Is(err, service.ErrUnauthenticated) {
+			utils.Warn(ctx, "authentication failed", "client_ip", peerAddr(ctx), "error", err)
+			return nil, apperr.New(apperr.CodeUnauthenticated)
+		}
+		return nil, apperr.From(err)
+	}
+	return principal, nil
+}
+
+func rateLimited(ctx context.Context, limiter, key string, retryAfter time.Duration, code apperr.Code) error {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	metrics.RateLimited.WithLabelValues(limiter).Inc()
+	utils.Warn(ctx, "request rate limited", "limiter", limiter, "client", key, "retry_after_s", seconds)
+	_ = grpc.SetTrailer(ctx, metadata.Pairs("retry-after", strconv.Itoa(seconds)))
+	if code == apperr.CodeRateLimited {
+		return apperr.New(code, seconds)
+	}
+	return apperr.New(code)
+}
+
+// clientKey identifies the caller like the HTTP middleware: API key ID, JWT subject,
+// or peer IP when the call is anonymous.
+func clientKey(ctx context.Context, p *model.Principal) string {
+	switch p.Method {
+	case model.AuthMethodAPIKey:
+		return "key:" + strconv.FormatInt(p.KeyID, 10)
+	case model.AuthMethodJWT:
+		return "sub:" + p.Subject
+	}
+	return "ip:" + peerAddr(ctx)
+}
+
+// toStatus converts an error to a gRPC status with a localized message and sets the
+// stable error code as a trailer. Status errors and nil pass through unchanged.
+func toStatus(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	if errors.Is(err, context.Canceled) {
+		return status.Error(codes.Canceled, err.Error())
+	}
+
+	e := apperr.From(err)
+	if e.Status() >= http.StatusInternalServerError {
+		utils.Error(ctx, "grpc call failed", "code", e.Code, "error", err)
+	}
+	_ = grpc.SetTrailer(ctx, metadata.Pairs(errorCodeTrailer, string(e.Code)))
+	return status.Error(grpcCode(e.Status()), e.Message(language(ctx)))
+}
+
+// grpcCode maps the HTTP status of an apperr code to the matching gRPC code.
+func grpcCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.FailedPrecondition
+	case http.StatusRequestEntityTooLarge, http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusUnprocessableEntity:
+		return codes.FailedPrecondition
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	}
+	return codes.Internal
+}
+
+// observe writes the access log line and records the call latency.
+func observe(ctx context.Context, method string, start time.Time, err error) {
+	code := status.Code(err)
+	metrics.GRPCRequestDuration.WithLabelValues(method, code.String()).Observe(time.Since(start).Seconds())
+
+	args := []any{
+		"method", method,
+		"code", code.String(),
+		"latency_ms", float64(time.Since(start).Microseconds()) / 1000,
+		"client_ip", peerAddr(ctx),
+	}
+	if p := service.PrincipalFromContext(ctx); p != nil {
+		args = append(args, "subject", p.Subject, "auth", p.Method)
+	}
+	if code == codes.Internal || code == codes.Unknown {
+		utils.Error(ctx, "grpc call completed", args...)
+	} else {
+		utils.Info(ctx, "grpc call completed", args...)
+	}
+}
+
+// language negotiates the message language from the accept-language metadata.
+func language(ctx context.Context) apperr.Lang {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return apperr.Negotiate(first(md, "accept-language"))
+}
+
+// withRequestID takes the request ID from the x-request-id metadata or generates one,
+// and echoes it in the response header.
+func withRequestID(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	id := first(md, requestIDMetadata)
+	if id == "" || len(id) > 128 {
+		b := make([]byte, 16)
+		_, _ = rand.Read(b)
+		id = hex.EncodeToString(b)
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadata, id))
+	return utils.WithRequestID(ctx, id)
+}
+
+func peerAddr(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+func first(md metadata.MD, key string) string {
+	if v := md.Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// contextStream overrides the context of a server stream.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,251 @@
+// internal/grpcserver/server.go
+package grpcserver
+
+//go:generate protoc -I ../../proto --go_out=../.. --go_opt=module=collision_app_go --go-grpc_out=../.. --go-grpc_opt=module=collision_app_go collision/v1/collision.proto
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"collision_app_go/internal/apperr"
+	collisionv1 "collision_app_go/internal/gen/collision/v1"
+	"collision_app_go/internal/model"
+	"collision_app_go/internal/ratelimit"
+	"collision_app_go/internal/service"
+	"collision_app_go/utils"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+// Request size limits.
+const (
+	maxBatchSize      = 500
+	maxRouteWaypoints = 100
+)
+
+// Options configures the gRPC server.
+type Options struct {
+	DefaultCollisionDistance float64
+	MaxCollisionDistance     float64
+	// AuthService authenticates callers; nil disables authentication and every
+	// call runs as an anonymous admin, as on the HTTP API.
+	AuthService *service.AuthService
+	// IPLimiter, when non-nil, limits calls per peer IP before authentication.
+	IPLimiter *ratelimit.Limiter
+	// Limiter and Quota, when non-nil, apply the HTTP API's per-client limits to
+	// every check: each unary call, each point of a batch after the first and each
+	// stream message.
+	Limiter    *ratelimit.Limiter
+	Quota      *ratelimit.Quota
+	Reflection bool
+	// Webhooks receives collision events for positive results; nil disables them.
+	Webhooks *service.WebhookService
+}
+
+// Server implements collisionv1.CollisionServiceServer on top of CollisionService.
+type Server struct {
+	collisionv1.UnimplementedCollisionServiceServer
+	collision *service.CollisionService
+	opts      Options
+}
+
+// New returns a gRPC server with the collision service and the authentication,
+// rate limiting, logging and error-mapping interceptors registered.
+func New(collision *service.CollisionService, opts Options) *grpc.Server {
+	i := &interceptors{auth: opts.AuthService, ipLimiter: opts.IPLimiter, limiter: opts.Limiter, quota: opts.Quota}
+	s := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(i.unary),
+		grpc.ChainStreamInterceptor(i.stream),
+	)
+	collisionv1.RegisterCollisionServiceServer(s, &Server{collision: collision, opts: opts})
+	if opts.Reflection {
+		reflection.Register(s)
+	}
+	return s
+}
+
+// CheckPoint checks a single position against nearby buildings.
+func (s *Server) CheckPoint(ctx context.Context, req *collisionv1.CheckPointRequest) (*collisionv1.CheckPointResponse, error) {
+	resp, check, err := s.checkPoint(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	s.publish(ctx, "", check)
+	return resp, nil
+}
+
+// CheckBatch checks each point independently; a failed point does not fail the batch.
+// Every point counts against the caller's rate limit and quota: once they run out,
+// the remaining points fail with the limit error.
+func (s *Server) CheckBatch(ctx context.Context, req *collisionv1.CheckBatchRequest) (*collisionv1.CheckBatchResponse, error) {
+	if len(req.GetPoints()) == 0 {
+		return nil, apperr.InvalidArgument("points", "required")
+	}
+	if len(req.GetPoints()) > maxBatchSize {
+		return nil, apperr.InvalidArgument("points", "max_items", maxBatchSize)
+	}
+
+	lang := language(ctx)
+	results := make([]*collisionv1.CheckBatchResult, len(req.GetPoints()))
+	for i, point := range req.GetPoints() {
+		var resp *collisionv1.CheckPointResponse
+		var check *pointCheck
+		var err error
+		if i > 0 { // the first point is paid for by the call
+			err = charge(ctx)
+		}
+		if err == nil {
+			resp, check, err = s.checkPoint(ctx, point)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			results[i] = &collisionv1.CheckBatchResult{Result: &collisionv1.CheckBatchResult_Error{Error: toErrorMessage(err, lang)}}
+			continue
+		}
+		s.publish(ctx, "", check)
+		results[i] = &collisionv1.CheckBatchResult{Result: &collisionv1.CheckBatchResult_Response{Response: resp}}
+	}
+	return &collisionv1.CheckBatchResponse{Results: results}, nil
+}
+
+// CheckRoute checks every leg of a 3D route.
+func (s *Server) CheckRoute(ctx context.Context, req *collisionv1.CheckRouteRequest) (*collisionv1.CheckRouteResponse, error) {
+	waypoints := req.GetWaypoints()
+	if len(waypoints) < 2 {
+		return nil, apperr.InvalidArgument("waypoints", "min_items", 2)
+	}
+	if len(waypoints) > maxRouteWaypoints {
+		return nil, apperr.InvalidArgument("waypoints", "max_items", maxRouteWaypoints)
+	}
+	route := make([]model.Point3D, len(waypoints))
+	for i, p := range waypoints {
+		point, err := fromPosition(p, "waypoints")
+		if err != nil {
+			return nil, err
+		}
+		route[i] = point
+	}
+	distance, err := s.collisionDistance(req.CollisionDistance)
+	if err != nil {
+		return nil, err
+	}
+	footprint, err := fromFootprint(req.GetFootprint())
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.collision.CheckRoute(ctx, route, distance, footprint)
+	if err != nil {
+		return nil, err
+	}
+	s.publish(ctx, "", &pointCheck{kind: "route", pos: route[0], result: result})
+	return toRouteResponse(result), nil
+}
+
+// StreamPositions answers every position update with a check result, in order.
+// Each update counts against the caller's rate limit and quota like a unary call.
+// Failed checks are reported in the result; the stream ends when the client closes
+// its side or the call is cancelled. A collision event is published when a drone
+// starts colliding, not for every colliding update.
+func (s *Server) StreamPositions(stream collisionv1.CollisionService_StreamPositionsServer) error {
+	ctx := stream.Context()
+	lang := language(ctx)
+	colliding := make(map[string]bool)
+	for {
+		update, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		result := &collisionv1.PositionResult{DroneId: update.GetDroneId(), Sequence: update.GetSequence()}
+		var resp *collisionv1.CheckPointResponse
+		var check *pointCheck
+		err = charge(ctx)
+		if err == nil {
+			resp, check, err = s.checkPoint(ctx, &collisionv1.CheckPointRequest{
+				Position:          update.GetPosition(),
+				CollisionDistance: update.CollisionDistance,
+				Footprint:         update.GetFootprint(),
+				Mode:              update.GetMode(),
+			})
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			utils.Warn(ctx, "stream position check failed", "drone_id", update.GetDroneId(), "sequence", update.GetSequence(), "error", err)
+			result.Result = &collisionv1.PositionResult_Error{Error: toErrorMessage(err, lang)}
+		} else {
+			result.Result = &collisionv1.PositionResult_Response{Response: resp}
+			if id := update.GetDroneId(); resp.GetIsCollision() != colliding[id] {
+				colliding[id] = resp.GetIsCollision()
+				check.kind = "stream"
+				s.publish(ctx, id, check)
+			}
+		}
+		if err := stream.Send(result); err != nil {
+			return err
+		}
+	}
+}
+
+// pointCheck is a completed check, kept to publish it as a webhook event.
+type pointCheck struct {
+	kind   string // point, point_3d, route or stream
+	pos    model.Point3D
+	result any
+}
+
+func (s *Server) checkPoint(ctx context.Context, req *collisionv1.CheckPointRequest) (*collisionv1.CheckPointResponse, *pointCheck, error) {
+	pos, err := fromPosition(req.GetPosition(), "position")
+	if err != nil {
+		return nil, nil, err
+	}
+	distance, err := s.collisionDistance(req.CollisionDistance)
+	if err != nil {
+		return nil, nil, err
+	}
+	footprint, err := fromFootprint(req.GetFootprint())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if req.GetMode() == collisionv1.Mode_MODE_3D {
+		result, err := s.collision.CheckCollision3D(ctx, pos.Longitude, pos.Latitude, pos.Height, distance, footprint)
+		if err != nil {
+			return nil, nil, err
+		}
+		return toSolidResponse(result), &pointCheck{kind: "point_3d", pos: pos, result: result}, nil
+	}
+	result, err := s.collision.CheckCollision(ctx, pos.Longitude, pos.Latitude, pos.Height, distance, footprint)
+	if err != nil {
+		return nil, nil, err
+	}
+	return toPointResponse(result), &pointCheck{kind: "point", pos: pos, result: result}, nil
+}
+
+// publish queues a collision event for a positive check.
+func (s *Server) publish(ctx context.Context, droneID string, check *pointCheck) {
+	s.opts.Webhooks.PublishCheck(ctx, "grpc", check.kind, droneID, &check.pos, check.result)
+}
+
+// collisionDistance applies the configured default and maximum.
+func (s *Server) collisionDistance(v *float64) (float64, error) {
+	if v == nil {
+		return s.opts.DefaultCollisionDistance, nil
+	}
+	if !finite(*v) || *v < 0 {
+		return 0, apperr.InvalidArgument("collision_distance", "not_negative")
+	}
+	if *v > s.opts.MaxCollisionDistance {
+		return 0, apperr.InvalidArgument("collision_distance", "max", s.opts.MaxCollisionDistance)
+	}
+	return *v, nil
+}
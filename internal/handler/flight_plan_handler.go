@@ -0,0 +1,155 @@
+// internal/handler/flight_plan_handler.go
+package handler
+
+import (
+	"collision_app_go/internal/apperr"
+	"collision_app_go/internal/model"
+	"collision_app_go/internal/service"
+	"collision_app_go/utils"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type submitFlightPlanRequest struct {
+	DroneID     string           `json:"drone_id" binding:"required,max=80"`
+	Route       []model.Waypoint `json:"route" binding:"required,min=2,max=1000,dive"` // max is model.MaxRouteWaypoints
+	AltitudeMin float64          `json:"altitude_min" binding:"gte=0"`
+	AltitudeMax float64          `json:"altitude_max" binding:"gtfield=AltitudeMin"`
+	StartTime   time.Time        `json:"start_time" binding:"required"`
+	EndTime     time.Time        `json:"end_time" binding:"required,gtfield=StartTime"`
+	Buffer      *float64         `json:"buffer" binding:"omitempty,gte=0"`
+}
+
+type reviewFlightPlanRequest struct {
+	Decision string `json:"decision" binding:"required,oneof=approved rejected"`
+	Reviewer string `json:"reviewer" binding:"max=80"` // ignored when the caller is authenticated
+	Comment  string `json:"comment"`
+}
+
+// SubmitFlightPlan godoc
+func (h *Handler) SubmitFlightPlan(c *gin.Context) {
+	var req submitFlightPlanRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	buffer := h.defaults.PlanBuffer
+	if req.Buffer != nil {
+		buffer = *req.Buffer
+	}
+	plan := model.FlightPlan{
+		DroneID:     req.DroneID,
+		Route:       req.Route,
+		AltitudeMin: req.AltitudeMin,
+		AltitudeMax: req.AltitudeMax,
+		StartTime:   req.StartTime,
+		EndTime:     req.EndTime,
+	}
+	if p := service.PrincipalFromContext(c.Request.Context()); p != nil && p.Method != model.AuthMethodNone {
+		plan.SubmittedBy = p.Subject
+	}
+
+	utils.Info(c.Request.Context(), "received flight plan submission",
+		"drone_id", plan.DroneID, "waypoints", len(plan.Route), "altitude_min", plan.AltitudeMin, "altitude_max", plan.AltitudeMax,
+		"start_time", plan.StartTime, "end_time", plan.EndTime)
+
+	result, err := h.flightPlanService.SubmitFlightPlan(c.Request.Context(), plan, buffer)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	respond(c, result)
+}
+
+// ListFlightPlans godoc
+func (h *Handler) ListFlightPlans(c *gin.Context) {
+	plans, err := h.flightPlanService.ListFlightPlans(c.Request.Context(), c.Query("drone_id"), c.Query("status"))
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	respond(c, plans)
+}
+
+// GetFlightPlan godoc
+func (h *Handler) GetFlightPlan(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.flightPlanService.GetFlightPlan(c.Request.Context(), id)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	respond(c, result)
+}
+
+// FlightPlanConflicts godoc
+func (h *Handler) FlightPlanConflicts(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	suggestShift, err := strconv.ParseBool(c.DefaultQuery("suggest_shift", "false"))
+	if err != nil {
+		fail(c, apperr.InvalidArgument("suggest_shift", "boolean"))
+		return
+	}
+
+	result, err := h.flightPlanService.CheckFlightPlanConflicts(c.Request.Context(), id, suggestShift)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	respond(c, result)
+}
+
+// ReviewFlightPlan godoc
+func (h *Handler) ReviewFlightPlan(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	var req reviewFlightPlanRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	// The authenticated identity is the reviewer of record
+	reviewer := req.Reviewer
+	if p := service.PrincipalFromContext(c.Request.Context()); p != nil && p.Method != model.AuthMethodNone {
+		reviewer = p.Subject
+	}
+	if reviewer == "" {
+		fail(c, apperr.InvalidArgument("reviewer", "required"))
+		return
+	}
+
+	utils.Info(c.Request.Context(), "received flight plan review", "plan_id", id, "decision", req.Decision, "reviewer", reviewer)
+
+	plan, err := h.flightPlanService.ReviewFlightPlan(c.Request.Context(), id, req.Decision, reviewer, req.Comment)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	respond(c, plan)
+}
+
+func parseID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		fail(c, apperr.InvalidArgument("id", "integer"))
+		return 0, false
+	}
+	return id, true
+}
@@ -2,113 +2,450 @@
 package handler
 
 import (
+	"collision_app_go/internal/apperr"
 	"collision_app_go/utils"
 	"fmt"
+	"math"
 	"net/http"
 	"strconv"
+	"time"
 
+	"collision_app_go/internal/export"
+	"collision_app_go/internal/model"
 	"collision_app_go/internal/service"
 	"github.com/gin-gonic/gin"
 )
 
+// MaxLookAhead is the longest trajectory horizon, in seconds, accepted by CollisionPredict.
+const MaxLookAhead = 300
+
+// floatParam names a float query parameter and its default ("" means required).
+type floatParam struct {
+	name string
+	def  string
+}
+
+// parseFloatParams parses the given query parameters, failing the request with
+// INVALID_ARGUMENT and returning false on the first one that is missing, malformed
+// or not finite (NaN and infinities are rejected).
+func parseFloatParams(c *gin.Context, params []floatParam) (map[string]float64, bool) {
+	values := make(map[string]float64, len(params))
+	for _, p := range params {
+		raw := c.DefaultQuery(p.name, p.def)
+		if raw == "" {
+			fail(c, apperr.InvalidArgument(p.name, "required"))
+			return nil, false
+		}
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+			fail(c, apperr.InvalidArgument(p.name, "number"))
+			return nil, false
+		}
+		values[p.name] = v
+	}
+	return values, true
+}
+
+// parseFootprint reads the optional drone size and position uncertainty parameters.
+// h_uncertainty / v_uncertainty take precedence over hdop / vdop, which are scaled by uere.
+func parseFootprint(c *gin.Context) (model.Footprint, bool) {
+	values, ok := parseFloatParams(c, []floatParam{
+		{name: "drone_radius", def: "0"},
+		{name: "h_uncertainty", def: "0"},
+		{name: "v_uncertainty", def: "0"},
+		{name: "hdop", def: "0"},
+		{name: "vdop", def: "0"},
+		{name: "uere", def: "0"},
+	})
+	if !ok {
+		return model.Footprint{}, false
+	}
+	for name, v := range values {
+		if v < 0 {
+			fail(c, apperr.InvalidArgument(name, "not_negative"))
+			return model.Footprint{}, false
+		}
+	}
+
+	footprint := model.Footprint{
+		DroneRadius:           values["drone_radius"],
+		HorizontalUncertainty: values["h_uncertainty"],
+		VerticalUncertainty:   values["v_uncertainty"],
+	}
+	if c.Query("h_uncertainty") == "" {
+		footprint.HorizontalUncertainty = model.UncertaintyFromDOP(values["hdop"], values["uere"])
+	}
+	if c.Query("v_uncertainty") == "" {
+		footprint.VerticalUncertainty = model.UncertaintyFromDOP(values["vdop"], values["uere"])
+	}
+	return footprint, true
+}
+
+// parseMode reads the optional intersection mode ("2.5d" by default, or "3d").
+func parseMode(c *gin.Context) (string, bool) {
+	mode := c.DefaultQuery("mode", model.Mode25D)
+	if mode != model.Mode25D && mode != model.Mode3D {
+		fail(c, apperr.InvalidArgument("mode", "enum", []string{model.Mode25D, model.Mode3D}))
+		return "", false
+	}
+	return mode, true
+}
+
+// collision returns the collision service to use for the request: the live data, or
+// the buildings as they were at the optional as_of time.
+func (h *Handler) collision(c *gin.Context) (*service.CollisionService, bool) {
+	raw := c.Query("as_of")
+	if raw == "" {
+		return h.collisionService, true
+	}
+	asOf, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		fail(c, apperr.InvalidArgument("as_of", "date_time"))
+		return nil, false
+	}
+	return h.collisionService.AsOf(asOf), true
+}
+
+// Defaults holds the configured values used for omitted request parameters.
+type Defaults struct {
+	CollisionDistance float64 // collision_distance, meters
+	LookAhead         float64 // look_ahead, seconds
+	PlanBuffer        float64 // flight plan buffer, meters
+	InvalidGeometry   string  // invalid_geometry, the import policy
+}
+
 type Handler struct {
-	collisionService *service.CollisionService
-	buildingsService *service.BuildingsService
+	collisionService  *service.CollisionService
+	buildingsService  *service.BuildingsService
+	flightPlanService *service.FlightPlanService
+	webhookService    *service.WebhookService // nil when webhooks are disabled
+	defaults          Defaults
 }
 
-func NewHandler(collisionService *service.CollisionService, buildingsService *service.BuildingsService) *Handler {
+func NewHandler(collisionService *service.CollisionService, buildingsService *service.BuildingsService, flightPlanService *service.FlightPlanService, webhookService *service.WebhookService, defaults Defaults) *Handler {
 	return &Handler{
-		collisionService: collisionService,
-		buildingsService: buildingsService,
+		collisionService:  collisionService,
+		buildingsService:  buildingsService,
+		flightPlanService: flightPlanService,
+		webhookService:    webhookService,
+		defaults:          defaults,
 	}
 }
 
+// formatDefault renders a configured default as a query parameter default.
+func formatDefault(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
 // CollisionInfo godoc
 func (h *Handler) CollisionInfo(c *gin.Context) {
-	longitudeStr := c.Query("longitude")
-	latitudeStr := c.Query("latitude")
-	heightStr := c.Query("height")
-	collisionDistanceStr := c.DefaultQuery("collision_distance", "2")
+	values, ok := parseFloatParams(c, []floatParam{
+		{name: "longitude"},
+		{name: "latitude"},
+		{name: "height"},
+		{name: "collision_distance", def: formatDefault(h.defaults.CollisionDistance)},
+	})
+	if !ok {
+		return
+	}
+	longitude, latitude, height, collisionDistance := values["longitude"], values["latitude"], values["height"], values["collision_distance"]
 
-	longitude, err := strconv.ParseFloat(longitudeStr, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Invalid longitude"})
+	footprint, ok := parseFootprint(c)
+	if !ok {
+		return
+	}
+
+	utils.Info(c.Request.Context(), "received collision request",
+		"longitude", longitude, "latitude", latitude, "height", height, "collision_distance", collisionDistance, "footprint", footprint, "as_of", c.Query("as_of"))
+
+	mode, ok := parseMode(c)
+	if !ok {
+		return
+	}
+	collision, ok := h.collision(c)
+	if !ok {
 		return
 	}
-	latitude, err := strconv.ParseFloat(latitudeStr, 64)
+
+	var result any
+	var err error
+	check := "point"
+	if mode == model.Mode3D {
+		check = "point_3d"
+		result, err = collision.CheckCollision3D(c.Request.Context(), longitude, latitude, height, collisionDistance, footprint)
+	} else {
+		result, err = collision.CheckCollision(c.Request.Context(), longitude, latitude, height, collisionDistance, footprint)
+	}
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Invalid latitude"})
+		fail(c, err)
 		return
 	}
-	height, err := strconv.ParseFloat(heightStr, 64)
+
+	pos := model.Point3D{Longitude: longitude, Latitude: latitude, Height: height}
+	// Checks against past data reconstruct an incident; they are not live detections
+	if c.Query("as_of") == "" {
+		h.webhookService.PublishCheck(c.Request.Context(), "api", check, "", &pos, result)
+	}
+	respond(c, result)
+}
+
+// CollisionZones godoc
+func (h *Handler) CollisionZones(c *gin.Context) {
+	values, ok := parseFloatParams(c, []floatParam{
+		{name: "longitude"},
+		{name: "latitude"},
+		{name: "height"},
+	})
+	if !ok {
+		return
+	}
+
+	// 可选参数 zones，例如 "caution:30:20,warning:15:10,critical:5:2"；为空时使用全局配置
+	var zones []model.WarningZone
+	if spec := c.Query("zones"); spec != "" {
+		var err error
+		zones, err = model.ParseWarningZones(spec)
+		if err != nil {
+			fail(c, apperr.InvalidArgument("zones", "format", err))
+			return
+		}
+	}
+
+	footprint, ok := parseFootprint(c)
+	if !ok {
+		return
+	}
+	collision, ok := h.collision(c)
+	if !ok {
+		return
+	}
+
+	utils.Info(c.Request.Context(), "received zones request",
+		"longitude", values["longitude"], "latitude", values["latitude"], "height", values["height"], "zones", c.Query("zones"), "footprint", footprint, "as_of", c.Query("as_of"))
+
+	result, err := collision.CheckCollisionZones(c.Request.Context(), values["longitude"], values["latitude"], values["height"], zones, footprint)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Invalid height"})
+		fail(c, err)
+		return
+	}
+
+	pos := model.Point3D{Longitude: values["longitude"], Latitude: values["latitude"], Height: values["height"]}
+	if c.Query("as_of") == "" {
+		h.webhookService.PublishCheck(c.Request.Context(), "api", "zones", "", &pos, result)
+	}
+	respond(c, result)
+}
+
+// LineOfSight godoc
+func (h *Handler) LineOfSight(c *gin.Context) {
+	values, ok := parseFloatParams(c, []floatParam{
+		{name: "from_longitude"},
+		{name: "from_latitude"},
+		{name: "from_height"},
+		{name: "to_longitude"},
+		{name: "to_latitude"},
+		{name: "to_height"},
+	})
+	if !ok {
+		return
+	}
+
+	from := model.Point3D{Longitude: values["from_longitude"], Latitude: values["from_latitude"], Height: values["from_height"]}
+	to := model.Point3D{Longitude: values["to_longitude"], Latitude: values["to_latitude"], Height: values["to_height"]}
+
+	mode, ok := parseMode(c)
+	if !ok {
 		return
 	}
-	collisionDistance, err := strconv.ParseFloat(collisionDistanceStr, 64)
+	collision, ok := h.collision(c)
+	if !ok {
+		return
+	}
+
+	utils.Info(c.Request.Context(), "received line of sight request", "from", from, "to", to, "mode", mode, "as_of", c.Query("as_of"))
+
+	result, err := collision.CheckLineOfSight(c.Request.Context(), from, to, mode)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "Invalid collision_distance"})
+		fail(c, err)
+		return
+	}
+
+	respond(c, result)
+}
+
+// CollisionPredict godoc
+func (h *Handler) CollisionPredict(c *gin.Context) {
+	values, ok := parseFloatParams(c, []floatParam{
+		{name: "longitude"},
+		{name: "latitude"},
+		{name: "height"},
+		{name: "heading"},
+		{name: "ground_speed"},
+		{name: "vertical_speed", def: "0"},
+		{name: "look_ahead", def: formatDefault(h.defaults.LookAhead)},
+		{name: "collision_distance", def: formatDefault(h.defaults.CollisionDistance)},
+	})
+	if !ok {
+		return
+	}
+
+	if values["heading"] < 0 || values["heading"] >= 360 {
+		fail(c, apperr.InvalidArgument("heading", "range", "[0, 360)"))
+		return
+	}
+	if values["ground_speed"] < 0 {
+		fail(c, apperr.InvalidArgument("ground_speed", "not_negative"))
+		return
+	}
+	if values["look_ahead"] <= 0 || values["look_ahead"] > MaxLookAhead {
+		fail(c, apperr.InvalidArgument("look_ahead", "range", fmt.Sprintf("(0, %d]", MaxLookAhead)))
 		return
 	}
 
-	utils.Infof("Received request: longitude=%f, latitude=%f, height=%f, collision_distance=%f", longitude, latitude, height, collisionDistance)
+	footprint, ok := parseFootprint(c)
+	if !ok {
+		return
+	}
 
-	result, err := h.collisionService.CheckCollision(c.Request.Context(), longitude, latitude, height, collisionDistance)
+	state := service.MotionState{
+		Longitude:     values["longitude"],
+		Latitude:      values["latitude"],
+		Height:        values["height"],
+		Heading:       values["heading"],
+		GroundSpeed:   values["ground_speed"],
+		VerticalSpeed: values["vertical_speed"],
+	}
+
+	collision, ok := h.collision(c)
+	if !ok {
+		return
+	}
+
+	utils.Info(c.Request.Context(), "received predict request",
+		"state", state, "look_ahead", values["look_ahead"], "collision_distance", values["collision_distance"], "footprint", footprint, "as_of", c.Query("as_of"))
+
+	result, err := collision.PredictCollision(c.Request.Context(), state, values["look_ahead"], values["collision_distance"], footprint)
 	if err != nil {
-		utils.Errorf("Service error in CollisionInfo: %v", err)
-		// 如果 Service 返回错误，直接返回 500 和错误信息
-		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": fmt.Sprintf("检测碰撞时发生错误: %v", err)})
+		fail(c, err)
 		return
 	}
 
-	// 如果 Service 成功，result 就是期望的 map[string]interface{}
-	c.JSON(http.StatusOK, result)
+	pos := model.Point3D{Longitude: state.Longitude, Latitude: state.Latitude, Height: state.Height}
+	if c.Query("as_of") == "" {
+		h.webhookService.PublishCheck(c.Request.Context(), "api", "predict", "", &pos, result)
+	}
+	respond(c, result)
 }
 
 // InsertBuildingsInfo godoc
 func (h *Handler) InsertBuildingsInfo(c *gin.Context) {
 	filePath := c.Query("file_path")
 	if filePath == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"success": false, "code": 400, "errorMsg": "文件路径不能为空"})
+		fail(c, apperr.InvalidArgument("file_path", "required"))
+		return
+	}
+
+	opts := model.ImportOptions{InvalidGeometry: c.DefaultQuery("invalid_geometry", h.defaults.InvalidGeometry)}
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		fail(c, apperr.InvalidArgument("dry_run", "boolean"))
 		return
 	}
 
-	utils.Infof("Received request to insert buildings from file: %s", filePath)
+	if dryRun {
+		utils.Info(c.Request.Context(), "received request to dry-run import from file", "file_path", filePath, "invalid_geometry", opts.InvalidGeometry)
+
+		report, err := h.buildingsService.DryRunImport(c.Request.Context(), filePath, opts, nil)
+		if err != nil {
+			fail(c, err)
+			return
+		}
+		c.Header("Content-Disposition", `attachment; filename="import-report.json"`)
+		respond(c, report)
+		return
+	}
 
-	// Service 负责处理文件读取和数据库插入，并返回结果 map 或 error
-	result, err := h.buildingsService.InsertBuildings(c.Request.Context(), filePath)
+	utils.Info(c.Request.Context(), "received request to insert buildings from file", "file_path", filePath, "invalid_geometry", opts.InvalidGeometry)
+
+	// Service 负责处理文件读取和数据库插入，失败时返回带错误码的 error
+	result, err := h.buildingsService.InsertBuildings(c.Request.Context(), filePath, opts, nil)
 	if err != nil {
-		utils.Errorf("Service error in InsertBuildingsInfo: %v", err)
-		// 如果 Service 返回错误，直接返回 500 和错误信息
-		// 注意：这里假设 Service 在出错时返回 error，而不是一个错误的 map
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success":  false,
-			"code":     500, // 或者从 err 中解析出更具体的错误码
-			"errorMsg": fmt.Sprintf("导入建筑物信息发生错误: %v", err),
-		})
+		fail(c, err)
 		return
 	}
 
-	// 如果 Service 成功，result 就是期望的 map[string]interface{} (例如 {"success": true, ...})
-	c.JSON(http.StatusOK, result)
+	h.webhookService.Publish(c.Request.Context(), model.EventImportFinished, model.ImportEvent{Source: "api", FilePath: filePath, Result: *result})
+	respond(c, result)
 }
 
 // UpdateBuildingsInfo godoc
 func (h *Handler) UpdateBuildingsInfo(c *gin.Context) {
-	utils.Info("Received request to update all buildings info...")
+	utils.Info(c.Request.Context(), "received request to update all buildings info")
 
-	// Service 负责处理更新逻辑，并返回结果 map 或 error
 	result, err := h.buildingsService.UpdateBuildings(c.Request.Context())
 	if err != nil {
-		utils.Errorf("Service error in UpdateBuildingsInfo: %v", err)
-		// 如果 Service 返回错误，直接返回 500 和错误信息
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"status":  "error",
-			"message": fmt.Sprintf("更新建筑物时发生错误: %v", err),
-		})
+		fail(c, err)
 		return
 	}
 
-	// 如果 Service 成功，result 就是期望的 map[string]interface{} (例如 {"success": true, ...})
-	c.JSON(http.StatusOK, result)
+	respond(c, result)
+}
+
+// ExportBuildings godoc
+func (h *Handler) ExportBuildings(c *gin.Context) {
+	format := c.DefaultQuery("format", model.ExportGeoJSON)
+	filter := model.BuildingFilter{AreaCode: c.Query("area_code"), Type: c.Query("type")}
+	if raw := c.Query("bbox"); raw != "" {
+		bbox, err := model.ParseBBox(raw)
+		if err != nil {
+			fail(c, apperr.InvalidArgument("bbox", "format", err.Error()))
+			return
+		}
+		filter.BBox = bbox
+	}
+	buildingsService := h.buildingsService
+	if raw := c.Query("as_of"); raw != "" {
+		asOf, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			fail(c, apperr.InvalidArgument("as_of", "date_time"))
+			return
+		}
+		buildingsService = buildingsService.AsOf(asOf)
+	}
+
+	ctx := c.Request.Context()
+	utils.Info(ctx, "received request to export buildings", "format", format)
+
+	w, err := export.New(format, c.Writer)
+	if err != nil {
+		fail(c, apperr.Wrap(apperr.CodeExportFailed, err))
+		return
+	}
+	// Exports outlive the server's write timeout
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+	c.Header("Content-Type", export.ContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, export.FileName(format)))
+
+	count := 0
+	err = buildingsService.ExportBuildings(ctx, filter, func(b model.BuildingRecord) error {
+		count++
+		return w.Write(b)
+	})
+	if err == nil {
+		err = w.Close()
+	} else {
+		w.Abort()
+	}
+	if err == nil {
+		utils.Info(ctx, "buildings exported", "format", format, "count", count)
+		return
+	}
+	if c.Writer.Written() {
+		// The status is already sent; the client sees a truncated download
+		utils.Error(ctx, "export failed after the response started", "error", err, "count", count)
+		return
+	}
+	c.Writer.Header().Del("Content-Type")
+	c.Writer.Header().Del("Content-Disposition")
+	fail(c, apperr.Wrap(apperr.CodeExportFailed, err))
 }
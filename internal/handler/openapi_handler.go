@@ -0,0 +1,62 @@
+// internal/handler/openapi_handler.go
+package handler
+
+import (
+	"collision_app_go/internal/openapi"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files/v2"
+)
+
+// swaggerInitializer points the bundled Swagger UI at our document instead of the demo petstore.
+const swaggerInitializer = `window.onload = function() {
+  window.ui = SwaggerUIBundle({
+    url: "/openapi.json",
+    dom_id: "#swagger-ui",
+    deepLinking: true,
+    persistAuthorization: true,
+    presets: [SwaggerUIBundle.presets.apis, SwaggerUIStandalonePreset],
+    plugins: [SwaggerUIBundle.plugins.DownloadUrl],
+    layout: "StandaloneLayout"
+  });
+};
+`
+
+type OpenAPIHandler struct {
+	spec []byte
+}
+
+// NewOpenAPIHandler renders the document once; it does not change at runtime.
+func NewOpenAPIHandler(doc *openapi.Document) (*OpenAPIHandler, error) {
+	spec, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return &OpenAPIHandler{spec: spec}, nil
+}
+
+// Spec serves the OpenAPI document.
+func (h *OpenAPIHandler) Spec(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", h.spec)
+}
+
+// SwaggerUI serves the bundled Swagger UI under /swagger/*filepath.
+func (h *OpenAPIHandler) SwaggerUI(c *gin.Context) {
+	switch path := c.Param("filepath"); path {
+	case "", "/", "/index.html":
+		// Served directly: http.FileServer redirects /index.html to the directory
+		index, err := fs.ReadFile(swaggerFiles.FS, "index.html")
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.Data(http.StatusOK, "text/html; charset=utf-8", index)
+	case "/swagger-initializer.js":
+		c.Data(http.StatusOK, "application/javascript; charset=utf-8", []byte(swaggerInitializer))
+	default:
+		c.FileFromFS(path, http.FS(swaggerFiles.FS))
+	}
+}
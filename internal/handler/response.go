@@ -0,0 +1,125 @@
+// internal/handler/response.go
+package handler
+
+import (
+	"collision_app_go/internal/apperr"
+	"collision_app_go/internal/model"
+	"collision_app_go/utils"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+func init() {
+	// Report binding errors with JSON field names rather than Go struct field names.
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(f reflect.StructField) string {
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "-" {
+				return ""
+			}
+			return name
+		})
+	}
+}
+
+// respond writes data in a success envelope.
+func respond(c *gin.Context, data any) {
+	c.JSON(http.StatusOK, model.Response{
+		Status:    model.StatusSuccess,
+		Data:      data,
+		RequestID: utils.RequestIDFromContext(c.Request.Context()),
+	})
+}
+
+// fail attaches err to the request; middleware.Errors renders it.
+func fail(c *gin.Context, err error) {
+	_ = c.Error(err)
+}
+
+// bindJSON decodes and validates the JSON body into obj, failing the request with
+// INVALID_ARGUMENT on error.
+func bindJSON(c *gin.Context, obj any) bool {
+	err := c.ShouldBindJSON(obj)
+	if err == nil {
+		return true
+	}
+
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		fail(c, apperr.InvalidArgument("body", "json", err.Error()))
+		return false
+	}
+	lang := apperr.Negotiate(c.GetHeader("Accept-Language"))
+	details := make([]model.FieldError, len(verrs))
+	for i, fe := range verrs {
+		rule, args := bindingRule(fe)
+		details[i] = model.FieldError{In: "body", Name: fieldPath(fe), Message: apperr.Rule(lang, rule, args...)}
+	}
+	rule, args := bindingRule(verrs[0])
+	fail(c, apperr.InvalidArgument(fieldPath(verrs[0]), rule, args...).WithDetails(details))
+	return false
+}
+
+// bindingRule maps a validator tag to the matching apperr rule.
+func bindingRule(fe validator.FieldError) (string, []any) {
+	kind := fe.Kind()
+	switch fe.Tag() {
+	case "required":
+		return "required", nil
+	case "min":
+		switch kind {
+		case reflect.Slice, reflect.Array:
+			return "min_items", []any{fe.Param()}
+		case reflect.String:
+			return "min_length", []any{fe.Param()}
+		}
+		return "min", []any{fe.Param()}
+	case "max":
+		if kind == reflect.String {
+			return "max_length", []any{fe.Param()}
+		}
+		return "max", []any{fe.Param()}
+	case "gte":
+		return "min", []any{fe.Param()}
+	case "lte":
+		return "max", []any{fe.Param()}
+	case "gt":
+		return "exclusive_min", []any{fe.Param()}
+	case "lt":
+		return "exclusive_max", []any{fe.Param()}
+	case "gtfield":
+		return "gt_field", []any{snakeCase(fe.Param())}
+	case "oneof":
+		return "enum", []any{strings.Fields(fe.Param())}
+	}
+	return "invalid", nil
+}
+
+// fieldPath returns the JSON path of a field, without the request struct name.
+func fieldPath(fe validator.FieldError) string {
+	_, path, ok := strings.Cut(fe.Namespace(), ".")
+	if !ok {
+		return fe.Field()
+	}
+	return path
+}
+
+// snakeCase converts a Go field name such as AltitudeMin to altitude_min.
+func snakeCase(name string) string {
+	var sb strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 && unicode.IsLower(runes[i-1]) {
+			sb.WriteByte('_')
+		}
+		sb.WriteRune(unicode.ToLower(r))
+	}
+	return sb.String()
+}
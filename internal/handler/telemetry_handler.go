@@ -0,0 +1,62 @@
+// internal/handler/telemetry_handler.go
+package handler
+
+import (
+	"collision_app_go/internal/telemetry"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// keepAliveInterval is how often an idle alert stream sends a comment so that
+// proxies do not close it.
+const keepAliveInterval = 15 * time.Second
+
+type TelemetryHandler struct {
+	monitor *telemetry.Monitor
+	hub     *telemetry.Hub
+}
+
+func NewTelemetryHandler(monitor *telemetry.Monitor, hub *telemetry.Hub) *TelemetryHandler {
+	return &TelemetryHandler{monitor: monitor, hub: hub}
+}
+
+// Systems lists the MAVLink systems currently tracked.
+func (h *TelemetryHandler) Systems(c *gin.Context) {
+	respond(c, h.monitor.Systems())
+}
+
+// Alerts streams telemetry alerts as server-sent events until the client
+// disconnects or the server shuts down. Each event is named "alert" and carries a
+// model.TelemetryAlert.
+func (h *TelemetryHandler) Alerts(c *gin.Context) {
+	alerts, cancel := h.hub.Subscribe()
+	defer cancel()
+
+	// The stream outlives the server's write timeout
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+	ctx := c.Request.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case alert, ok := <-alerts:
+			if !ok {
+				return
+			}
+			c.SSEvent("alert", alert)
+		case <-keepAlive.C:
+			_, _ = c.Writer.WriteString(": keep-alive\n\n")
+		}
+		c.Writer.Flush()
+	}
+}
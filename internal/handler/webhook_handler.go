@@ -0,0 +1,89 @@
+// internal/handler/webhook_handler.go
+package handler
+
+import (
+	"collision_app_go/internal/model"
+	"collision_app_go/internal/service"
+	"collision_app_go/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type createWebhookRequest struct {
+	URL         string   `json:"url" binding:"required,max=2000"`
+	Events      []string `json:"events" binding:"required,min=1"`
+	Description string   `json:"description" binding:"max=200"`
+}
+
+type WebhookHandler struct {
+	webhooks *service.WebhookService
+}
+
+func NewWebhookHandler(webhooks *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{webhooks: webhooks}
+}
+
+// CreateWebhook godoc
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var req createWebhookRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	sub := model.WebhookSubscription{URL: req.URL, Events: req.Events, Description: req.Description}
+	if p := service.PrincipalFromContext(c.Request.Context()); p != nil {
+		sub.CreatedBy = p.Subject
+	}
+
+	utils.Info(c.Request.Context(), "received webhook subscription", "url", sub.URL, "events", sub.Events)
+
+	created, err := h.webhooks.CreateSubscription(c.Request.Context(), sub)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	respond(c, created)
+}
+
+// ListWebhooks godoc
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	subs, err := h.webhooks.ListSubscriptions(c.Request.Context())
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	respond(c, subs)
+}
+
+// DeleteWebhook godoc
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.webhooks.DeleteSubscription(c.Request.Context(), id); err != nil {
+		fail(c, err)
+		return
+	}
+
+	respond(c, gin.H{"id": id, "active": false})
+}
+
+// ListWebhookDeliveries godoc
+func (h *WebhookHandler) ListWebhookDeliveries(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	deliveries, err := h.webhooks.ListDeliveries(c.Request.Context(), id, c.Query("status"))
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	respond(c, deliveries)
+}
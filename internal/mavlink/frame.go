@@ -0,0 +1,146 @@
+// internal/mavlink/frame.go
+// Package mavlink decodes the subset of MAVLink v2 that the telemetry monitor needs
+// and reads and writes .tlog recordings.
+package mavlink
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Frame markers and sizes of the MAVLink wire format.
+const (
+	MagicV1 = 0xFE
+	MagicV2 = 0xFD
+
+	headerLenV1  = 6  // magic, len, seq, sysid, compid, msgid
+	headerLenV2  = 10 // magic, len, incompat, compat, seq, sysid, compid, msgid[3]
+	checksumLen  = 2
+	signatureLen = 13
+	flagSigned   = 0x01 // incompat_flags bit: the frame carries a signature
+	minFrameLen  = headerLenV1 + checksumLen
+)
+
+var (
+	// ErrTruncated is returned when a frame is cut short.
+	ErrTruncated = errors.New("mavlink: truncated frame")
+	// ErrChecksum is returned when a frame's CRC does not match.
+	ErrChecksum = errors.New("mavlink: bad checksum")
+	// ErrUnknownMessage is returned for messages this package does not decode. The
+	// frame is still skipped correctly, but its checksum cannot be verified.
+	ErrUnknownMessage = errors.New("mavlink: unknown message")
+	// ErrVersion1 is returned for MAVLink v1 frames, which are not supported.
+	ErrVersion1 = errors.New("mavlink: v1 frames are not supported")
+)
+
+// Frame is one decoded MAVLink v2 packet. Payload is zero-extended to the full
+// length of known messages, undoing the v2 trailing-zero truncation.
+type Frame struct {
+	Seq         uint8
+	SystemID    uint8
+	ComponentID uint8
+	MessageID   uint32
+	Payload     []byte
+	Signed      bool
+}
+
+// Parse decodes the first frame in data and returns the number of bytes it used.
+// Bytes before the first start marker are skipped and counted. When err is not nil
+// and n > 0, the caller should skip n bytes and continue with the rest of data.
+func Parse(data []byte) (frame Frame, n int, err error) {
+	start := 0
+	for start < len(data) && data[start] != MagicV2 && data[start] != MagicV1 {
+		start++
+	}
+	if start == len(data) {
+		return Frame{}, len(data), ErrTruncated
+	}
+	data = data[start:]
+
+	size, err := FrameLen(data)
+	if err != nil {
+		return Frame{}, start + len(data), err
+	}
+	if data[0] == MagicV1 {
+		return Frame{}, start + size, ErrVersion1
+	}
+
+	payloadLen := int(data[1])
+	frame = Frame{
+		Seq:         data[4],
+		SystemID:    data[5],
+		ComponentID: data[6],
+		MessageID:   uint32(data[7]) | uint32(data[8])<<8 | uint32(data[9])<<16,
+		Signed:      data[2]&flagSigned != 0,
+	}
+	spec, ok := messages[frame.MessageID]
+	if !ok {
+		return frame, start + size, fmt.Errorf("%w: id %d", ErrUnknownMessage, frame.MessageID)
+	}
+
+	body := data[1 : headerLenV2+payloadLen]
+	want := binary.LittleEndian.Uint16(data[headerLenV2+payloadLen:])
+	if crc(body, spec.crcExtra) != want {
+		return frame, start + size, fmt.Errorf("%w: message %d from system %d", ErrChecksum, frame.MessageID, frame.SystemID)
+	}
+
+	frame.Payload = make([]byte, max(payloadLen, spec.length))
+	copy(frame.Payload, data[headerLenV2:headerLenV2+payloadLen])
+	return frame, start + size, nil
+}
+
+// FrameLen returns the total length of the frame starting at data[0], including the
+// checksum and any signature.
+func FrameLen(data []byte) (int, error) {
+	if len(data) < minFrameLen {
+		return 0, ErrTruncated
+	}
+	var size int
+	switch data[0] {
+	case MagicV2:
+		size = headerLenV2 + int(data[1]) + checksumLen
+		if data[2]&flagSigned != 0 {
+			size += signatureLen
+		}
+	case MagicV1:
+		size = headerLenV1 + int(data[1]) + checksumLen
+	default:
+		return 0, fmt.Errorf("mavlink: unexpected start byte 0x%02x", data[0])
+	}
+	if len(data) < size {
+		return 0, ErrTruncated
+	}
+	return size, nil
+}
+
+// Marshal encodes msg as an unsigned MAVLink v2 frame, truncating trailing zero
+// bytes of the payload as the protocol requires.
+func Marshal(seq, systemID, componentID uint8, msg Message) []byte {
+	payload := msg.marshal()
+	for len(payload) > 1 && payload[len(payload)-1] == 0 {
+		payload = payload[:len(payload)-1]
+	}
+
+	id := msg.MessageID()
+	buf := make([]byte, 0, headerLenV2+len(payload)+checksumLen)
+	buf = append(buf, MagicV2, byte(len(payload)), 0, 0, seq, systemID, componentID, byte(id), byte(id>>8), byte(id>>16))
+	buf = append(buf, payload...)
+	return binary.LittleEndian.AppendUint16(buf, crc(buf[1:], messages[id].crcExtra))
+}
+
+// crc computes the CRC-16/MCRF4XX (X.25) checksum MAVLink uses, seeded with the
+// message's CRC_EXTRA byte.
+func crc(data []byte, extra byte) uint16 {
+	sum := uint16(0xFFFF)
+	accumulate := func(b byte) {
+		tmp := b ^ byte(sum)
+		tmp ^= tmp << 4
+		sum = sum>>8 ^ uint16(tmp)<<8 ^ uint16(tmp)<<3 ^ uint16(tmp)>>4
+	}
+	for _, b := range data {
+		accumulate(b)
+	}
+	accumulate(extra)
+	return sum
+}
@@ -0,0 +1,157 @@
+package mavlink
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCRC(t *testing.T) {
+	// CRC_EXTRA is accumulated after the data, so the standard CRC-16/MCRF4XX check
+	// value of "123456789" is that of "12345678" with extra '9'.
+	tests := []struct {
+		name  string
+		data  string
+		extra byte
+		want  uint16
+	}{
+		{"check value", "12345678", '9', 0x6F91},
+		{"extra only", "", 0x00, 0x0F87},
+	}
+	for _, tt := range tests {
+		if got := crc([]byte(tt.data), tt.extra); got != tt.want {
+			t.Errorf("%s: crc() = %#04x, want %#04x", tt.name, got, tt.want)
+		}
+	}
+
+	if crc([]byte("frame"), messages[MsgHeartbeat].crcExtra) == crc([]byte("frame"), messages[MsgGlobalPositionInt].crcExtra) {
+		t.Errorf("crc() ignores CRC_EXTRA")
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  Message
+	}{
+		{"heartbeat", Heartbeat{CustomMode: 4, Type: 2, Autopilot: 3, BaseMode: ModeFlagSafetyArmed | 0x01, SystemStatus: 4, MavlinkVersion: 3}},
+		{"zero heartbeat", Heartbeat{}},
+		{"position", GlobalPositionInt{TimeBootMs: 123456, Lat: 302745123, Lon: 1201553987, Alt: 52000, RelativeAlt: 45250,
+			Vx: -120, Vy: 340, Vz: -50, Hdg: 27015}},
+		// Trailing zero bytes are truncated on the wire and restored by Parse
+		{"position with truncated payload", GlobalPositionInt{Lat: -1, Lon: -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := Marshal(7, 42, 1, tt.msg)
+			frame, n, err := Parse(data)
+			if err != nil {
+				t.Fatalf("Parse() error: %v", err)
+			}
+			if n != len(data) {
+				t.Errorf("Parse() used %d of %d bytes", n, len(data))
+			}
+			if frame.Seq != 7 || frame.SystemID != 42 || frame.ComponentID != 1 || frame.MessageID != tt.msg.MessageID() || frame.Signed {
+				t.Errorf("Parse() header = %+v", frame)
+			}
+			if got, want := len(frame.Payload), messages[tt.msg.MessageID()].length; got != want {
+				t.Errorf("payload length = %d, want %d", got, want)
+			}
+
+			msg, err := Decode(frame)
+			if err != nil {
+				t.Fatalf("Decode() error: %v", err)
+			}
+			if !reflect.DeepEqual(msg, tt.msg) {
+				t.Errorf("Decode() = %+v, want %+v", msg, tt.msg)
+			}
+		})
+	}
+}
+
+func TestMarshalTruncatesPayload(t *testing.T) {
+	data := Marshal(0, 1, 1, Heartbeat{CustomMode: 5})
+	if got := int(data[1]); got != 1 {
+		t.Errorf("payload length = %d, want 1", got)
+	}
+	if got, want := len(data), headerLenV2+1+checksumLen; got != want {
+		t.Errorf("frame length = %d, want %d", got, want)
+	}
+}
+
+func TestParseStream(t *testing.T) {
+	hb := Heartbeat{Type: 2, Autopilot: 3}
+	pos := GlobalPositionInt{Lat: 1, Lon: 2, Hdg: 0xFFFF}
+	data := append([]byte{0x00, 0x55}, Marshal(1, 1, 1, hb)...)
+	data = append(data, Marshal(2, 1, 1, pos)...)
+
+	var got []Message
+	for len(data) > 0 {
+		frame, n, err := Parse(data)
+		data = data[n:]
+		if err != nil {
+			t.Fatalf("Parse() error: %v", err)
+		}
+		msg, err := Decode(frame)
+		if err != nil {
+			t.Fatalf("Decode() error: %v", err)
+		}
+		got = append(got, msg)
+	}
+	if want := []Message{hb, pos}; !reflect.DeepEqual(got, want) {
+		t.Errorf("messages = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	valid := Marshal(0, 1, 1, GlobalPositionInt{Lat: 1, Lon: 2})
+
+	corrupt := bytes.Clone(valid)
+	corrupt[headerLenV2] ^= 0xFF
+
+	// A valid frame sealed with another message's CRC_EXTRA
+	wrongExtra := bytes.Clone(valid)
+	sum := crc(wrongExtra[1:len(wrongExtra)-checksumLen], messages[MsgHeartbeat].crcExtra)
+	wrongExtra[len(wrongExtra)-2], wrongExtra[len(wrongExtra)-1] = byte(sum), byte(sum>>8)
+
+	unknown := bytes.Clone(valid)
+	unknown[7] = 0xFF
+
+	signed := bytes.Clone(valid)
+	signed[2] |= flagSigned
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+		n    int // bytes to skip
+	}{
+		{"no start marker", []byte{0x00, 0x01, 0x02}, ErrTruncated, 3},
+		{"short header", valid[:5], ErrTruncated, 5},
+		{"short payload", valid[:len(valid)-1], ErrTruncated, len(valid) - 1},
+		{"missing signature", signed, ErrTruncated, len(signed)},
+		{"corrupt payload", corrupt, ErrChecksum, len(valid)},
+		{"wrong CRC_EXTRA", wrongExtra, ErrChecksum, len(valid)},
+		{"unknown message", unknown, ErrUnknownMessage, len(valid)},
+		{"version 1", []byte{MagicV1, 1, 0, 1, 1, 0, 0, 0xAA, 0xBB}, ErrVersion1, 9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, n, err := Parse(tt.data)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Parse() error = %v, want %v", err, tt.want)
+			}
+			if n != tt.n {
+				t.Errorf("Parse() used %d bytes, want %d", n, tt.n)
+			}
+		})
+	}
+}
+
+func TestDecodeTruncated(t *testing.T) {
+	frame := Frame{MessageID: MsgGlobalPositionInt, Payload: make([]byte, 10)}
+	if _, err := Decode(frame); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Decode() error = %v, want ErrTruncated", err)
+	}
+}
@@ -0,0 +1,165 @@
+// internal/mavlink/messages.go
+package mavlink
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Message IDs decoded by this package.
+const (
+	MsgHeartbeat         = 0
+	MsgGlobalPositionInt = 33
+)
+
+// MAV_TYPE values of components that are not vehicles.
+const (
+	TypeGCS               = 6
+	TypeOnboardController = 18
+	TypeADSB              = 27
+)
+
+// AutopilotInvalid is the MAV_AUTOPILOT of components that are not flight controllers.
+const AutopilotInvalid = 8
+
+// ModeFlagSafetyArmed is the base_mode bit set while the motors are armed.
+const ModeFlagSafetyArmed = 0x80
+
+// messageSpec is the payload length (without extensions) and CRC_EXTRA of a message.
+type messageSpec struct {
+	length   int
+	crcExtra byte
+}
+
+var messages = map[uint32]messageSpec{
+	MsgHeartbeat:         {length: 9, crcExtra: 50},
+	MsgGlobalPositionInt: {length: 28, crcExtra: 104},
+}
+
+// Message is a MAVLink message that can be encoded with Marshal.
+type Message interface {
+	MessageID() uint32
+	marshal() []byte
+}
+
+// Heartbeat is HEARTBEAT (#0), sent by every component about once a second.
+type Heartbeat struct {
+	CustomMode     uint32
+	Type           uint8 // MAV_TYPE
+	Autopilot      uint8 // MAV_AUTOPILOT
+	BaseMode       uint8 // MAV_MODE_FLAG bits
+	SystemStatus   uint8 // MAV_STATE
+	MavlinkVersion uint8
+}
+
+func (Heartbeat) MessageID() uint32 { return MsgHeartbeat }
+
+// Armed reports whether the vehicle's motors are armed.
+func (h Heartbeat) Armed() bool {
+	return h.BaseMode&ModeFlagSafetyArmed != 0
+}
+
+// IsVehicle reports whether the sender is a vehicle's flight controller rather than a
+// ground station, companion computer, camera or other component.
+func (h Heartbeat) IsVehicle() bool {
+	switch h.Type {
+	case TypeGCS, TypeOnboardController, TypeADSB:
+		return false
+	}
+	return h.Autopilot != AutopilotInvalid
+}
+
+func (h Heartbeat) marshal() []byte {
+	b := make([]byte, 9)
+	binary.LittleEndian.PutUint32(b[0:], h.CustomMode)
+	b[4], b[5], b[6], b[7], b[8] = h.Type, h.Autopilot, h.BaseMode, h.SystemStatus, h.MavlinkVersion
+	return b
+}
+
+// GlobalPositionInt is GLOBAL_POSITION_INT (#33), the filtered global position.
+// Fields keep their wire units; use the methods for SI values.
+type GlobalPositionInt struct {
+	TimeBootMs  uint32
+	Lat         int32  // degE7
+	Lon         int32  // degE7
+	Alt         int32  // mm above mean sea level
+	RelativeAlt int32  // mm above home
+	Vx          int16  // cm/s, north
+	Vy          int16  // cm/s, east
+	Vz          int16  // cm/s, down
+	Hdg         uint16 // cdeg, 0..35999; UINT16_MAX when unknown
+}
+
+func (GlobalPositionInt) MessageID() uint32 { return MsgGlobalPositionInt }
+
+// Latitude returns the latitude in degrees.
+func (p GlobalPositionInt) Latitude() float64 { return float64(p.Lat) / 1e7 }
+
+// Longitude returns the longitude in degrees.
+func (p GlobalPositionInt) Longitude() float64 { return float64(p.Lon) / 1e7 }
+
+// RelativeAltitude returns the altitude above the home position in meters.
+func (p GlobalPositionInt) RelativeAltitude() float64 { return float64(p.RelativeAlt) / 1000 }
+
+// Heading returns the heading in degrees and false when the vehicle does not know it.
+func (p GlobalPositionInt) Heading() (float64, bool) {
+	if p.Hdg == 0xFFFF {
+		return 0, false
+	}
+	return float64(p.Hdg) / 100, true
+}
+
+// Velocity returns the north, east and up speeds in m/s.
+func (p GlobalPositionInt) Velocity() (north, east, up float64) {
+	return float64(p.Vx) / 100, float64(p.Vy) / 100, float64(-int32(p.Vz)) / 100
+}
+
+func (p GlobalPositionInt) marshal() []byte {
+	b := make([]byte, 28)
+	binary.LittleEndian.PutUint32(b[0:], p.TimeBootMs)
+	binary.LittleEndian.PutUint32(b[4:], uint32(p.Lat))
+	binary.LittleEndian.PutUint32(b[8:], uint32(p.Lon))
+	binary.LittleEndian.PutUint32(b[12:], uint32(p.Alt))
+	binary.LittleEndian.PutUint32(b[16:], uint32(p.RelativeAlt))
+	binary.LittleEndian.PutUint16(b[20:], uint16(p.Vx))
+	binary.LittleEndian.PutUint16(b[22:], uint16(p.Vy))
+	binary.LittleEndian.PutUint16(b[24:], uint16(p.Vz))
+	binary.LittleEndian.PutUint16(b[26:], p.Hdg)
+	return b
+}
+
+// Decode returns the typed message carried by frame.
+func Decode(frame Frame) (Message, error) {
+	spec, ok := messages[frame.MessageID]
+	if !ok {
+		return nil, fmt.Errorf("%w: id %d", ErrUnknownMessage, frame.MessageID)
+	}
+	b := frame.Payload
+	if len(b) < spec.length {
+		return nil, ErrTruncated
+	}
+
+	switch frame.MessageID {
+	case MsgHeartbeat:
+		return Heartbeat{
+			CustomMode:     binary.LittleEndian.Uint32(b[0:]),
+			Type:           b[4],
+			Autopilot:      b[5],
+			BaseMode:       b[6],
+			SystemStatus:   b[7],
+			MavlinkVersion: b[8],
+		}, nil
+	default: // MsgGlobalPositionInt
+		return GlobalPositionInt{
+			TimeBootMs:  binary.LittleEndian.Uint32(b[0:]),
+			Lat:         int32(binary.LittleEndian.Uint32(b[4:])),
+			Lon:         int32(binary.LittleEndian.Uint32(b[8:])),
+			Alt:         int32(binary.LittleEndian.Uint32(b[12:])),
+			RelativeAlt: int32(binary.LittleEndian.Uint32(b[16:])),
+			Vx:          int16(binary.LittleEndian.Uint16(b[20:])),
+			Vy:          int16(binary.LittleEndian.Uint16(b[22:])),
+			Vz:          int16(binary.LittleEndian.Uint16(b[24:])),
+			Hdg:         binary.LittleEndian.Uint16(b[26:]),
+		}, nil
+	}
+}
@@ -0,0 +1,100 @@
+// internal/mavlink/tlog.go
+package mavlink
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// A .tlog file, as written by QGroundControl and MAVProxy, is a sequence of raw
+// frames each preceded by its receive time as big-endian Unix microseconds.
+const tlogTimestampLen = 8
+
+// TlogReader reads frames from a .tlog recording.
+type TlogReader struct {
+	r *bufio.Reader
+}
+
+// NewTlogReader returns a reader over the recording in r.
+func NewTlogReader(r io.Reader) *TlogReader {
+	return &TlogReader{r: bufio.NewReader(r)}
+}
+
+// Next returns the receive time and raw bytes of the next frame. It returns io.EOF at
+// the end of the recording.
+func (t *TlogReader) Next() (time.Time, []byte, error) {
+	var stamp [tlogTimestampLen]byte
+	if _, err := io.ReadFull(t.r, stamp[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return time.Time{}, nil, ErrTruncated
+		}
+		return time.Time{}, nil, err
+	}
+	at := time.UnixMicro(int64(binary.BigEndian.Uint64(stamp[:])))
+
+	header, err := t.r.Peek(3)
+	if err != nil {
+		return time.Time{}, nil, ErrTruncated
+	}
+	if header[0] != MagicV2 && header[0] != MagicV1 {
+		return time.Time{}, nil, fmt.Errorf("mavlink: tlog frame at %s starts with 0x%02x", at.Format(time.RFC3339Nano), header[0])
+	}
+	size := headerLenV1 + int(header[1]) + checksumLen
+	if header[0] == MagicV2 {
+		size = headerLenV2 + int(header[1]) + checksumLen
+		if header[2]&flagSigned != 0 {
+			size += signatureLen
+		}
+	}
+	frame := make([]byte, size)
+	if _, err := io.ReadFull(t.r, frame); err != nil {
+		return time.Time{}, nil, ErrTruncated
+	}
+	return at, frame, nil
+}
+
+// TlogWriter appends frames to a .tlog recording. It is safe for concurrent use.
+type TlogWriter struct {
+	mu sync.Mutex
+	w  *bufio.Writer
+}
+
+// NewTlogWriter returns a writer that records to w. Call Flush before closing w.
+func NewTlogWriter(w io.Writer) *TlogWriter {
+	return &TlogWriter{w: bufio.NewWriter(w)}
+}
+
+// Write records every frame in data, a received datagram, with receive time at.
+func (t *TlogWriter) Write(at time.Time, data []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var stamp [tlogTimestampLen]byte
+	binary.BigEndian.PutUint64(stamp[:], uint64(at.UnixMicro()))
+	for len(data) > 0 {
+		size, err := FrameLen(data)
+		if err != nil {
+			return err
+		}
+		if _, err := t.w.Write(stamp[:]); err != nil {
+			return err
+		}
+		if _, err := t.w.Write(data[:size]); err != nil {
+			return err
+		}
+		data = data[size:]
+	}
+	return nil
+}
+
+// Flush writes buffered frames to the underlying writer.
+func (t *TlogWriter) Flush() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.w.Flush()
+}
@@ -0,0 +1,126 @@
+// internal/metrics/metrics.go
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "collision"
+
+var (
+	// HTTPRequestDuration tracks request latency per route, method and status code.
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route, method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	// GRPCRequestDuration tracks gRPC call latency per method and status code. For
+	// streaming methods it covers the whole stream.
+	GRPCRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "grpc_request_duration_seconds",
+		Help:      "gRPC call latency by method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "code"})
+
+	// CollisionChecks counts collision checks by kind (point, point_3d, zones, predict, line_of_sight, route).
+	CollisionChecks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "checks_total",
+		Help:      "Collision checks performed, by kind.",
+	}, []string{"kind"})
+
+	// CollisionPositives counts collision checks that found an obstacle, by kind.
+	CollisionPositives = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "checks_positive_total",
+		Help:      "Collision checks with a positive result, by kind.",
+	}, []string{"kind"})
+
+	// QueryDuration tracks database query latency by query name.
+	QueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Database query latency by query name.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+	}, []string{"query"})
+
+	// ImportLines counts processed import lines by result (success, repaired,
+	// quarantined or failure).
+	ImportLines = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "import_lines_total",
+		Help:      "Building import lines processed, by result.",
+	}, []string{"result"})
+
+	// RateLimited counts requests rejected with 429, by limiter (default, heavy or quota).
+	RateLimited = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limited_total",
+		Help:      "Requests rejected by rate limiting or quotas, by limiter.",
+	}, []string{"limiter"})
+
+	// TelemetryFrames counts received MAVLink frames by message (heartbeat,
+	// global_position_int, other or invalid) and datagrams from senders that are
+	// not allowed (rejected).
+	TelemetryFrames = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "telemetry_frames_total",
+		Help:      "MAVLink frames received by the telemetry listener, by message.",
+	}, []string{"message"})
+
+	// TelemetrySystems is the number of MAVLink systems currently tracked.
+	TelemetrySystems = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "telemetry_systems",
+		Help:      "MAVLink systems currently tracked by the telemetry listener.",
+	})
+
+	// TelemetryAlerts counts telemetry alerts by new level (caution, warning, critical
+	// or clear), or geofence_breach / geofence_clear for geofence alerts.
+	TelemetryAlerts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "telemetry_alerts_total",
+		Help:      "Telemetry warning level and geofence changes, by new level.",
+	}, []string{"level"})
+
+	// WebhookDeliveries counts webhook delivery attempts by event type and result
+	// (delivered, retry or failed).
+	WebhookDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "webhook_deliveries_total",
+		Help:      "Webhook delivery attempts, by event type and result.",
+	}, []string{"event", "result"})
+
+	// AuditEntries counts collision check audit entries by result (written, dropped
+	// or failed).
+	AuditEntries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "audit_entries_total",
+		Help:      "Collision check audit entries, by result.",
+	}, []string{"result"})
+)
+
+// ObserveCollisionCheck records one collision check and whether it was positive.
+func ObserveCollisionCheck(kind string, positive bool) {
+	CollisionChecks.WithLabelValues(kind).Inc()
+	if positive {
+		CollisionPositives.WithLabelValues(kind).Inc()
+	}
+}
+
+// ObserveQuery records the duration of a database query started at start.
+// Typical use: defer metrics.ObserveQuery("name", time.Now()).
+func ObserveQuery(query string, start time.Time) {
+	QueryDuration.WithLabelValues(query).Observe(time.Since(start).Seconds())
+}
+
+// ObserveImportLine records the result of one import line.
+func ObserveImportLine(result string) {
+	ImportLines.WithLabelValues(result).Inc()
+}
@@ -0,0 +1,66 @@
+// internal/metrics/pool.go
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// poolCollector exports pgxpool statistics at scrape time.
+type poolCollector struct {
+	pool *pgxpool.Pool
+
+	acquired      *prometheus.Desc
+	idle          *prometheus.Desc
+	total         *prometheus.Desc
+	max           *prometheus.Desc
+	constructing  *prometheus.Desc
+	acquires      *prometheus.Desc
+	emptyAcquires *prometheus.Desc
+	emptyWait     *prometheus.Desc
+	canceled      *prometheus.Desc
+}
+
+// RegisterPoolCollector registers a collector exporting the pool's connection statistics.
+func RegisterPoolCollector(pool *pgxpool.Pool) error {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil)
+	}
+	return prometheus.Register(&poolCollector{
+		pool:          pool,
+		acquired:      desc("acquired_conns", "Connections currently checked out of the pool."),
+		idle:          desc("idle_conns", "Idle connections in the pool."),
+		total:         desc("total_conns", "Total connections in the pool."),
+		max:           desc("max_conns", "Maximum size of the pool."),
+		constructing:  desc("constructing_conns", "Connections currently being established."),
+		acquires:      desc("acquires_total", "Successful connection acquires."),
+		emptyAcquires: desc("waited_acquires_total", "Acquires that had to wait because no idle connection was available."),
+		emptyWait:     desc("wait_seconds_total", "Total time spent waiting for a connection."),
+		canceled:      desc("canceled_acquires_total", "Acquires canceled by their context."),
+	})
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.acquired
+	ch <- c.idle
+	ch <- c.total
+	ch <- c.max
+	ch <- c.constructing
+	ch <- c.acquires
+	ch <- c.emptyAcquires
+	ch <- c.emptyWait
+	ch <- c.canceled
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.pool.Stat()
+	ch <- prometheus.MustNewConstMetric(c.acquired, prometheus.GaugeValue, float64(s.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(s.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.total, prometheus.GaugeValue, float64(s.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.max, prometheus.GaugeValue, float64(s.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.constructing, prometheus.GaugeValue, float64(s.ConstructingConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquires, prometheus.CounterValue, float64(s.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquires, prometheus.CounterValue, float64(s.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.emptyWait, prometheus.CounterValue, s.EmptyAcquireWaitTime().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.canceled, prometheus.CounterValue, float64(s.CanceledAcquireCount()))
+}
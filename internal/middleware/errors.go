@@ -0,0 +1,70 @@
+// internal/middleware/errors.go
+package middleware
+
+import (
+	"collision_app_go/internal/apperr"
+	"collision_app_go/internal/model"
+	"collision_app_go/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Errors renders the last error attached with c.Error as an error envelope once the
+// handler chain returns. The HTTP status and message come from the error's code; the
+// underlying cause is logged but never sent to the client.
+func Errors() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		writeError(c, c.Errors.Last().Err)
+	}
+}
+
+// Recovery turns a panic into an INTERNAL error envelope.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecovery(func(c *gin.Context, recovered any) {
+		utils.Error(c.Request.Context(), "panic while handling request", "panic", recovered)
+		writeError(c, apperr.New(apperr.CodeInternal))
+	})
+}
+
+// NoRoute answers requests for unknown paths with ROUTE_NOT_FOUND.
+func NoRoute(c *gin.Context) {
+	_ = c.Error(apperr.New(apperr.CodeRouteNotFound))
+}
+
+// Language returns the response language negotiated from Accept-Language.
+func Language(c *gin.Context) apperr.Lang {
+	return apperr.Negotiate(c.GetHeader("Accept-Language"))
+}
+
+// abortWithError stops the chain and leaves err for Errors to render.
+func abortWithError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
+
+func writeError(c *gin.Context, err error) {
+	ctx := c.Request.Context()
+	e := apperr.From(err)
+	status := e.Status()
+	switch {
+	case status >= http.StatusInternalServerError:
+		utils.Error(ctx, "request failed", "code", e.Code, "error", err)
+	case e.Err != nil:
+		utils.Warn(ctx, "request rejected", "code", e.Code, "error", err)
+	}
+
+	c.AbortWithStatusJSON(status, model.Response{
+		Status: model.StatusError,
+		Error: &model.ErrorBody{
+			Code:    string(e.Code),
+			Message: e.Message(Language(c)),
+			Details: e.Details,
+		},
+		RequestID: utils.RequestIDFromContext(ctx),
+	})
+}
@@ -0,0 +1,40 @@
+// internal/middleware/logger.go
+package middleware
+
+import (
+	"collision_app_go/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AccessLog writes one structured log line per request. It must run after RequestID
+// so the line carries the request ID.
+func AccessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		args := []any{
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"route", c.FullPath(),
+			"status", c.Writer.Status(),
+			"latency_ms", float64(time.Since(start).Microseconds()) / 1000,
+			"client_ip", c.ClientIP(),
+			"size", c.Writer.Size(),
+		}
+		if p := Principal(c); p != nil {
+			args = append(args, "subject", p.Subject, "auth", p.Method)
+		}
+		if len(c.Errors) > 0 {
+			args = append(args, "errors", c.Errors.String())
+		}
+
+		if c.Writer.Status() >= 500 {
+			utils.Error(c.Request.Context(), "request completed", args...)
+		} else {
+			utils.Info(c.Request.Context(), "request completed", args...)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+// internal/middleware/ratelimit.go
+package middleware
+
+import (
+	"collision_app_go/internal/apperr"
+	"collision_app_go/internal/metrics"
+	"collision_app_go/internal/model"
+	"collision_app_go/internal/ratelimit"
+	"collision_app_go/utils"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit rejects requests with 429 and Retry-After once the client's token bucket
+// in limiter is empty. name labels the limiter in logs and metrics. It must run after
+// Authenticate so that clients are identified by credential rather than IP.
+func RateLimit(limiter *ratelimit.Limiter, name string) gin.HandlerFunc {
+	return rateLimit(limiter, name, clientKey)
+}
+
+// RouteRateLimit is RateLimit with one bucket per client and route, so that routes
+// sharing the limiter do not drain each other's budget.
+func RouteRateLimit(limiter *ratelimit.Limiter, name string) gin.HandlerFunc {
+	return rateLimit(limiter, name, func(c *gin.Context) string {
+		return clientKey(c) + " " + c.Request.Method + " " + c.FullPath()
+	})
+}
+
+// IPRateLimit limits requests per client IP. It runs before Authenticate, so that
+// requests with missing or invalid credentials are limited too and cannot force a
+// credential lookup each.
+func IPRateLimit(limiter *ratelimit.Limiter, name string) gin.HandlerFunc {
+	return rateLimit(limiter, name, func(c *gin.Context) string {
+		return "ip:" + c.ClientIP()
+	})
+}
+
+func rateLimit(limiter *ratelimit.Limiter, name string, keyOf func(*gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := keyOf(c)
+		ok, remaining, retryAfter := limiter.Allow(key)
+		c.Header("X-RateLimit-Limit", strconv.Itoa(limiter.Limit().Burst))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		if !ok {
+			rejectTooManyRequests(c, name, key, retryAfter, apperr.CodeRateLimited)
+			return
+		}
+		c.Next()
+	}
+}
+
+// DailyQuota rejects requests with 429 once the client has used its daily quota.
+// Retry-After points at the next UTC midnight, when the quota resets.
+func DailyQuota(quota *ratelimit.Quota) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := clientKey(c)
+		ok, remaining, retryAfter := quota.Take(key)
+		c.Header("X-Quota-Limit", strconv.FormatInt(quota.Limit(), 10))
+		c.Header("X-Quota-Remaining", strconv.FormatInt(remaining, 10))
+		if !ok {
+			rejectTooManyRequests(c, "quota", key, retryAfter, apperr.CodeQuotaExceeded)
+			return
+		}
+		c.Next()
+	}
+}
+
+// retryDetails is returned as the error details of a rate limited request.
+type retryDetails struct {
+	RetryAfter int `json:"retry_after"` // seconds
+}
+
+func rejectTooManyRequests(c *gin.Context, limiter, key string, retryAfter time.Duration, code apperr.Code) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	metrics.RateLimited.WithLabelValues(limiter).Inc()
+	utils.Warn(c.Request.Context(), "request rate limited", "limiter", limiter, "client", key, "retry_after_s", seconds)
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	err := apperr.New(code)
+	if code == apperr.CodeRateLimited {
+		err = apperr.New(code, seconds)
+	}
+	abortWithError(c, err.WithDetails(retryDetails{RetryAfter: seconds}))
+}
+
+// clientKey identifies the caller: API key ID, JWT subject, or client IP when the
+// request is anonymous.
+func clientKey(c *gin.Context) string {
+	if p := Principal(c); p != nil {
+		switch p.Method {
+		case model.AuthMethodAPIKey:
+			return "key:" + strconv.FormatInt(p.KeyID, 10)
+		case model.AuthMethodJWT:
+			return "sub:" + p.Subject
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
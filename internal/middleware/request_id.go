@@ -0,0 +1,44 @@
+// internal/middleware/request_id.go
+package middleware
+
+import (
+	"collision_app_go/internal/model"
+	"collision_app_go/internal/service"
+	"collision_app_go/utils"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader is the header used to receive and echo request IDs.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID assigns every request an ID, taken from the X-Request-ID header when the
+// caller provides one, and stores it in the request context for logging.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if id == "" || len(id) > 128 {
+			id = newRequestID()
+		}
+		c.Header(RequestIDHeader, id)
+		c.Request = c.Request.WithContext(utils.WithRequestID(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+// Origin marks the request as coming from the HTTP API and records the client
+// address, for the audit log.
+func Origin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(service.WithOrigin(c.Request.Context(), model.SourceAPI, c.ClientIP()))
+		c.Next()
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
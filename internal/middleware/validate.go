@@ -0,0 +1,70 @@
+// internal/middleware/validate.go
+package middleware
+
+import (
+	"bytes"
+	"collision_app_go/internal/apperr"
+	"collision_app_go/internal/model"
+	"collision_app_go/internal/openapi"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxValidatedBody bounds the JSON bodies read for validation.
+const maxValidatedBody = 1 << 20
+
+// ValidateRequest checks query, path and JSON body parameters against the operation
+// documented for the matched route and rejects mismatches with INVALID_ARGUMENT, listing
+// every invalid field in the error details. Routes missing from the document pass
+// through unchanged.
+func ValidateRequest(doc *openapi.Document) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		op := doc.Operation(strings.ToLower(c.Request.Method), openAPIPath(c.FullPath()))
+		if op == nil {
+			c.Next()
+			return
+		}
+
+		pathParams := make(map[string]string, len(c.Params))
+		for _, p := range c.Params {
+			pathParams[p.Key] = p.Value
+		}
+		errs := doc.ValidateParameters(op, c.Request.URL.Query(), pathParams)
+
+		if op.RequestBody != nil {
+			body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxValidatedBody))
+			if err != nil {
+				abortWithError(c, apperr.New(apperr.CodePayloadTooLarge))
+				return
+			}
+			// Restore the body for the handler's own binding
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+			errs = append(errs, doc.ValidateBody(op, body)...)
+		}
+
+		if len(errs) > 0 {
+			lang := Language(c)
+			details := make([]model.FieldError, len(errs))
+			for i, e := range errs {
+				details[i] = model.FieldError{In: e.In, Name: e.Name, Message: apperr.Rule(lang, e.Rule, e.Args...)}
+			}
+			abortWithError(c, apperr.InvalidArgument(errs[0].Name, errs[0].Rule, errs[0].Args...).WithDetails(details))
+			return
+		}
+		c.Next()
+	}
+}
+
+// openAPIPath converts a gin route template (/plans/:id) to OpenAPI form (/plans/{id}).
+func openAPIPath(route string) string {
+	parts := strings.Split(route, "/")
+	for i, p := range parts {
+		if strings.HasPrefix(p, ":") || strings.HasPrefix(p, "*") {
+			parts[i] = "{" + p[1:] + "}"
+		}
+	}
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS hzdk_buildings;
+DROP FUNCTION IF EXISTS update_hzdk_buildings_modtime();
@@ -1,4 +1,4 @@
-DROP TABLE IF EXISTS hzdk_buildings;
+CREATE EXTENSION IF NOT EXISTS postgis;
 
 CREATE TABLE IF NOT EXISTS hzdk_buildings
 (
@@ -17,7 +17,7 @@ CREATE TABLE IF NOT EXISTS hzdk_buildings
 -- 表注释
 COMMENT ON TABLE hzdk_buildings IS '余杭区建筑物信息表';
 
--- 字段注释（更标准方式，兼容所有客户端工具）
+-- 字段注释
 COMMENT ON COLUMN hzdk_buildings.building_id IS '建筑物id';
 COMMENT ON COLUMN hzdk_buildings.building_type IS '建筑物类型';
 COMMENT ON COLUMN hzdk_buildings.building_name IS '建筑物名称';
@@ -31,7 +31,10 @@ COMMENT ON COLUMN hzdk_buildings.update_time IS '更新时间';
 -- 创建 GIST 索引
 CREATE INDEX IF NOT EXISTS hzdk_buildings_geom_idx
     ON hzdk_buildings USING gist (geom);
-CREATE INDEX idx_hzdk_buildings_geom_geog ON hzdk_buildings USING GIST (geography(geom));
+CREATE INDEX IF NOT EXISTS idx_hzdk_buildings_geom_geog
+    ON hzdk_buildings USING gist (geography(geom));
+CREATE INDEX IF NOT EXISTS idx_hzdk_buildings_building_id
+    ON hzdk_buildings (building_id);
 
 -- 创建触发器函数：自动更新 update_time
 CREATE OR REPLACE FUNCTION update_hzdk_buildings_modtime()
@@ -49,5 +52,3 @@ CREATE TRIGGER trg_update_time
 BEFORE UPDATE ON hzdk_buildings
 FOR EACH ROW
 EXECUTE FUNCTION update_hzdk_buildings_modtime();
-
-
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS flight_plan_history;
+DROP TABLE IF EXISTS flight_plans;
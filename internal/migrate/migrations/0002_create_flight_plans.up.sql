@@ -0,0 +1,52 @@
+CREATE TABLE IF NOT EXISTS flight_plans
+(
+    id bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    drone_id character varying(80) NOT NULL,
+    route jsonb NOT NULL,
+    geom geometry(LineString, 4326) NOT NULL,
+    altitude_min numeric(10,2) NOT NULL,
+    altitude_max numeric(10,2) NOT NULL,
+    start_time timestamptz NOT NULL,
+    end_time timestamptz NOT NULL,
+    status character varying(20) NOT NULL,
+    reasons jsonb NOT NULL DEFAULT '[]'::jsonb,
+    create_time timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    update_time timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE flight_plans IS '飞行计划表';
+COMMENT ON COLUMN flight_plans.drone_id IS '无人机id';
+COMMENT ON COLUMN flight_plans.route IS '航路点列表';
+COMMENT ON COLUMN flight_plans.geom IS '航线';
+COMMENT ON COLUMN flight_plans.altitude_min IS '最低飞行高度';
+COMMENT ON COLUMN flight_plans.altitude_max IS '最高飞行高度';
+COMMENT ON COLUMN flight_plans.start_time IS '计划开始时间';
+COMMENT ON COLUMN flight_plans.end_time IS '计划结束时间';
+COMMENT ON COLUMN flight_plans.status IS '审批状态: approved/rejected/needs-review';
+COMMENT ON COLUMN flight_plans.reasons IS '审批原因';
+
+CREATE INDEX IF NOT EXISTS flight_plans_geom_idx ON flight_plans USING gist (geom);
+CREATE INDEX IF NOT EXISTS flight_plans_time_idx ON flight_plans (start_time, end_time);
+CREATE INDEX IF NOT EXISTS flight_plans_drone_idx ON flight_plans (drone_id);
+
+CREATE TABLE IF NOT EXISTS flight_plan_history
+(
+    id bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    plan_id bigint NOT NULL REFERENCES flight_plans (id) ON DELETE CASCADE,
+    status character varying(20) NOT NULL,
+    reasons jsonb NOT NULL DEFAULT '[]'::jsonb,
+    actor character varying(80) NOT NULL,
+    comment text,
+    create_time timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE flight_plan_history IS '飞行计划审批历史表';
+
+CREATE INDEX IF NOT EXISTS flight_plan_history_plan_idx ON flight_plan_history (plan_id);
+
+DROP TRIGGER IF EXISTS trg_update_time ON flight_plans;
+
+CREATE TRIGGER trg_update_time
+BEFORE UPDATE ON flight_plans
+FOR EACH ROW
+EXECUTE FUNCTION update_hzdk_buildings_modtime();
@@ -0,0 +1,3 @@
+DROP VIEW IF EXISTS hzdk_building_solids;
+DROP TABLE IF EXISTS hzdk_building_parts;
+ALTER TABLE hzdk_buildings DROP COLUMN IF EXISTS roof_height;
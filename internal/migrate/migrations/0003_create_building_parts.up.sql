@@ -0,0 +1,46 @@
+-- 屋顶高度：building_height 为檐口高度时，可选记录屋顶最高点
+ALTER TABLE hzdk_buildings ADD COLUMN IF NOT EXISTS roof_height numeric(10,2);
+COMMENT ON COLUMN hzdk_buildings.roof_height IS '屋顶高度';
+
+CREATE TABLE IF NOT EXISTS hzdk_building_parts
+(
+    gid integer GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    building_id bigint NOT NULL,
+    part_id integer NOT NULL,
+    geom geometry(MultiPolygon, 4326) NOT NULL,
+    base_height numeric(10,2) NOT NULL DEFAULT 0,
+    top_height numeric(10,2) NOT NULL,
+    create_time timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    update_time timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (building_id, part_id),
+    CHECK (top_height > base_height)
+);
+
+COMMENT ON TABLE hzdk_building_parts IS '建筑物分段高度表（裙楼、塔楼、退台等）';
+COMMENT ON COLUMN hzdk_building_parts.building_id IS '建筑物id';
+COMMENT ON COLUMN hzdk_building_parts.part_id IS '分段编号';
+COMMENT ON COLUMN hzdk_building_parts.geom IS '分段平面轮廓';
+COMMENT ON COLUMN hzdk_building_parts.base_height IS '分段底部高度';
+COMMENT ON COLUMN hzdk_building_parts.top_height IS '分段顶部高度';
+
+CREATE INDEX IF NOT EXISTS hzdk_building_parts_geom_idx
+    ON hzdk_building_parts USING gist (geom);
+CREATE INDEX IF NOT EXISTS idx_hzdk_building_parts_geom_geog
+    ON hzdk_building_parts USING gist (geography(geom));
+
+DROP TRIGGER IF EXISTS trg_update_time ON hzdk_building_parts;
+
+CREATE TRIGGER trg_update_time
+BEFORE UPDATE ON hzdk_building_parts
+FOR EACH ROW
+EXECUTE FUNCTION update_hzdk_buildings_modtime();
+
+-- 三维实体视图：有分段数据的建筑使用分段，否则将整个轮廓拉伸到屋顶高度（或建筑高度）
+CREATE OR REPLACE VIEW hzdk_building_solids AS
+SELECT p.building_id, p.part_id, p.geom, p.base_height, p.top_height
+FROM hzdk_building_parts p
+UNION ALL
+SELECT b.building_id, 0 AS part_id, b.geom, 0::numeric(10,2) AS base_height,
+       COALESCE(b.roof_height, b.building_height) AS top_height
+FROM hzdk_buildings b
+WHERE NOT EXISTS (SELECT 1 FROM hzdk_building_parts p WHERE p.building_id = b.building_id);
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS api_keys
+(
+    id bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    name character varying(80) NOT NULL,
+    key_hash character(64) NOT NULL UNIQUE,
+    role character varying(20) NOT NULL CHECK (role IN ('viewer', 'operator', 'admin')),
+    revoked boolean NOT NULL DEFAULT false,
+    create_time timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_used_time timestamp
+);
+
+COMMENT ON TABLE api_keys IS 'API密钥表';
+COMMENT ON COLUMN api_keys.name IS '密钥名称/调用方';
+COMMENT ON COLUMN api_keys.key_hash IS '密钥的SHA-256摘要 (十六进制), 不保存明文';
+COMMENT ON COLUMN api_keys.role IS '角色: viewer/operator/admin';
+COMMENT ON COLUMN api_keys.revoked IS '是否已吊销';
+COMMENT ON COLUMN api_keys.last_used_time IS '最近使用时间';
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS client_quota_usage
+(
+    day date NOT NULL,
+    client character varying(160) NOT NULL,
+    requests bigint NOT NULL DEFAULT 0,
+    update_time timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (day, client)
+);
+
+COMMENT ON TABLE client_quota_usage IS '客户端每日请求配额用量表';
+COMMENT ON COLUMN client_quota_usage.day IS '日期 (UTC)';
+COMMENT ON COLUMN client_quota_usage.client IS '客户端标识: key:<id> / sub:<subject> / ip:<address>';
+COMMENT ON COLUMN client_quota_usage.requests IS '当日请求数';
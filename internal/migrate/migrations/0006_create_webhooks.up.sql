@@ -0,0 +1,46 @@
+CREATE TABLE IF NOT EXISTS webhook_subscriptions
+(
+    id bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    url text NOT NULL,
+    secret character varying(80) NOT NULL,
+    events text[] NOT NULL,
+    description character varying(200) NOT NULL DEFAULT '',
+    active boolean NOT NULL DEFAULT true,
+    created_by character varying(80) NOT NULL,
+    create_time timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE webhook_subscriptions IS 'Webhook订阅表';
+COMMENT ON COLUMN webhook_subscriptions.url IS '接收事件的地址';
+COMMENT ON COLUMN webhook_subscriptions.secret IS 'HMAC-SHA256签名密钥';
+COMMENT ON COLUMN webhook_subscriptions.events IS '订阅的事件类型: collision/warning/geofence_breach/import_finished';
+COMMENT ON COLUMN webhook_subscriptions.active IS '是否启用, 删除订阅时置为false';
+COMMENT ON COLUMN webhook_subscriptions.created_by IS '创建者';
+
+CREATE TABLE IF NOT EXISTS webhook_outbox
+(
+    id bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    subscription_id bigint NOT NULL REFERENCES webhook_subscriptions (id) ON DELETE CASCADE,
+    event_id character(32) NOT NULL,
+    event_type character varying(40) NOT NULL,
+    payload jsonb NOT NULL,
+    status character varying(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'failed')),
+    attempts integer NOT NULL DEFAULT 0,
+    next_attempt_time timestamptz NOT NULL DEFAULT now(),
+    last_status integer,
+    last_error text,
+    create_time timestamptz NOT NULL DEFAULT now(),
+    delivered_time timestamptz
+);
+
+COMMENT ON TABLE webhook_outbox IS 'Webhook投递发件箱, 每个事件对每个匹配的订阅一行';
+COMMENT ON COLUMN webhook_outbox.event_id IS '事件id, 同一事件投递到多个订阅时相同';
+COMMENT ON COLUMN webhook_outbox.payload IS '投递的请求体, 签名基于该内容';
+COMMENT ON COLUMN webhook_outbox.status IS '投递状态: pending/delivered/failed (重试次数用尽)';
+COMMENT ON COLUMN webhook_outbox.attempts IS '已尝试次数';
+COMMENT ON COLUMN webhook_outbox.next_attempt_time IS '下次尝试时间; 投递中时为租约到期时间';
+COMMENT ON COLUMN webhook_outbox.last_status IS '最近一次响应的HTTP状态码';
+COMMENT ON COLUMN webhook_outbox.last_error IS '最近一次失败原因';
+
+CREATE INDEX IF NOT EXISTS webhook_outbox_due_idx ON webhook_outbox (next_attempt_time) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS webhook_outbox_subscription_idx ON webhook_outbox (subscription_id, id);
@@ -0,0 +1,40 @@
+-- 碰撞检测审计日志, 按月分区; 分区由服务启动时及每日自动创建, 超过保留期的分区自动删除
+CREATE TABLE IF NOT EXISTS collision_audit
+(
+    id bigint GENERATED ALWAYS AS IDENTITY,
+    create_time timestamptz NOT NULL,
+    request_id character varying(128) NOT NULL DEFAULT '',
+    source character varying(20) NOT NULL,
+    caller character varying(160) NOT NULL DEFAULT '',
+    auth_method character varying(20) NOT NULL DEFAULT '',
+    client_addr character varying(64) NOT NULL DEFAULT '',
+    check_kind character varying(20) NOT NULL,
+    input jsonb NOT NULL,
+    is_collision boolean NOT NULL,
+    level character varying(20) NOT NULL DEFAULT '',
+    building_ids bigint[] NOT NULL DEFAULT '{}',
+    error_code character varying(40) NOT NULL DEFAULT '',
+    latency_ms double precision NOT NULL,
+    result jsonb,
+    PRIMARY KEY (id, create_time)
+) PARTITION BY RANGE (create_time);
+
+COMMENT ON TABLE collision_audit IS '碰撞检测审计日志';
+COMMENT ON COLUMN collision_audit.create_time IS '检测时间';
+COMMENT ON COLUMN collision_audit.source IS '来源: api/grpc/telemetry/cli';
+COMMENT ON COLUMN collision_audit.caller IS '调用方 (API key 名称或 JWT sub)';
+COMMENT ON COLUMN collision_audit.client_addr IS '客户端地址';
+COMMENT ON COLUMN collision_audit.check_kind IS '检测类型: point/point_3d/zones/line_of_sight/route/predict';
+COMMENT ON COLUMN collision_audit.input IS '检测输入参数, 可用于重放';
+COMMENT ON COLUMN collision_audit.is_collision IS '是否碰撞 (通视检测为是否被遮挡)';
+COMMENT ON COLUMN collision_audit.level IS '告警区域检测的最高级别';
+COMMENT ON COLUMN collision_audit.building_ids IS '命中的建筑物id';
+COMMENT ON COLUMN collision_audit.error_code IS '检测失败时的错误码';
+COMMENT ON COLUMN collision_audit.latency_ms IS '检测耗时 (毫秒)';
+COMMENT ON COLUMN collision_audit.result IS '返回给调用方的完整结果';
+
+-- 兜底分区: 尚未创建月分区的时间段写入此处
+CREATE TABLE IF NOT EXISTS collision_audit_default PARTITION OF collision_audit DEFAULT;
+
+CREATE INDEX IF NOT EXISTS collision_audit_time_idx ON collision_audit (create_time);
+CREATE INDEX IF NOT EXISTS collision_audit_request_idx ON collision_audit (request_id);
@@ -0,0 +1,5 @@
+DROP FUNCTION IF EXISTS hzdk_building_solids_as_of(timestamptz);
+DROP FUNCTION IF EXISTS hzdk_buildings_as_of(timestamptz);
+DROP TRIGGER IF EXISTS trg_archive ON hzdk_buildings;
+DROP FUNCTION IF EXISTS archive_hzdk_buildings();
+DROP TABLE IF EXISTS hzdk_buildings_history;
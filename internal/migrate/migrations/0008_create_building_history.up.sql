@@ -0,0 +1,69 @@
+-- 建筑物历史版本表: 每次更新或删除前的旧版本连同其有效期写入此表, 用于按时间点 (as_of) 重建障碍物
+-- 列与 hzdk_buildings 一一对应, 之后给 hzdk_buildings 加列时需同时加到本表
+CREATE TABLE IF NOT EXISTS hzdk_buildings_history (LIKE hzdk_buildings);
+
+ALTER TABLE hzdk_buildings_history
+    ADD COLUMN IF NOT EXISTS valid_from timestamptz NOT NULL,
+    ADD COLUMN IF NOT EXISTS valid_to timestamptz NOT NULL,
+    ADD COLUMN IF NOT EXISTS operation character varying(10) NOT NULL;
+
+COMMENT ON TABLE hzdk_buildings_history IS '建筑物历史版本表';
+COMMENT ON COLUMN hzdk_buildings_history.valid_from IS '该版本生效时间 (即当时的 update_time)';
+COMMENT ON COLUMN hzdk_buildings_history.valid_to IS '该版本失效时间 (被更新或删除的时间)';
+COMMENT ON COLUMN hzdk_buildings_history.operation IS '使该版本失效的操作: UPDATE/DELETE';
+
+CREATE INDEX IF NOT EXISTS hzdk_buildings_history_geom_idx
+    ON hzdk_buildings_history USING gist (geom);
+CREATE INDEX IF NOT EXISTS idx_hzdk_buildings_history_geom_geog
+    ON hzdk_buildings_history USING gist (geography(geom));
+CREATE INDEX IF NOT EXISTS idx_hzdk_buildings_history_building_id
+    ON hzdk_buildings_history (building_id, valid_from);
+CREATE INDEX IF NOT EXISTS idx_hzdk_buildings_history_valid
+    ON hzdk_buildings_history (valid_from, valid_to);
+
+-- 触发器函数: 将被更新或删除的旧版本写入历史表
+-- 新版本的 update_time 由 trg_update_time 设为 CURRENT_TIMESTAMP, 与旧版本的 valid_to 相同, 有效期首尾相接
+CREATE OR REPLACE FUNCTION archive_hzdk_buildings()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO hzdk_buildings_history
+    SELECT (OLD).*, OLD.update_time, CURRENT_TIMESTAMP, TG_OP;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_archive ON hzdk_buildings;
+
+CREATE TRIGGER trg_archive
+AFTER UPDATE OR DELETE ON hzdk_buildings
+FOR EACH ROW
+EXECUTE FUNCTION archive_hzdk_buildings();
+
+-- 某一时间点的建筑物: 当时已生效且未被替换的当前版本, 加上当时有效的历史版本
+-- 迁移前已发生的更新没有历史记录, 这些建筑在其最后一次更新之前的时间点查询不到
+CREATE OR REPLACE FUNCTION hzdk_buildings_as_of(ts timestamptz)
+RETURNS SETOF hzdk_buildings AS $$
+    SELECT b.gid, b.building_id, b.building_type, b.building_name, b.building_addr, b.area_code,
+           b.geom, b.building_height, b.create_time, b.update_time, b.roof_height
+    FROM hzdk_buildings b
+    WHERE b.update_time <= ts
+    UNION ALL
+    SELECT h.gid, h.building_id, h.building_type, h.building_name, h.building_addr, h.area_code,
+           h.geom, h.building_height, h.create_time, h.update_time, h.roof_height
+    FROM hzdk_buildings_history h
+    WHERE h.valid_from <= ts AND ts < h.valid_to
+$$ LANGUAGE sql STABLE;
+
+-- 某一时间点的三维实体, 与 hzdk_building_solids 相同; 分段数据不保留历史, 使用当时已创建的分段
+CREATE OR REPLACE FUNCTION hzdk_building_solids_as_of(ts timestamptz)
+RETURNS TABLE (building_id bigint, part_id integer, geom geometry, base_height numeric, top_height numeric) AS $$
+    SELECT p.building_id, p.part_id, p.geom, p.base_height, p.top_height
+    FROM hzdk_building_parts p
+    WHERE p.create_time <= ts
+        AND EXISTS (SELECT 1 FROM hzdk_buildings_as_of(ts) b WHERE b.building_id = p.building_id)
+    UNION ALL
+    SELECT b.building_id, 0 AS part_id, b.geom, 0::numeric(10,2) AS base_height,
+           COALESCE(b.roof_height, b.building_height) AS top_height
+    FROM hzdk_buildings_as_of(ts) b
+    WHERE NOT EXISTS (SELECT 1 FROM hzdk_building_parts p WHERE p.building_id = b.building_id AND p.create_time <= ts)
+$$ LANGUAGE sql STABLE;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS hzdk_buildings_quarantine;
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS hzdk_buildings_quarantine
+(
+    id bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    file_path text NOT NULL,
+    line_number integer NOT NULL,
+    line text NOT NULL,
+    reason character varying(40) NOT NULL,
+    detail text NOT NULL DEFAULT '',
+    create_time timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE hzdk_buildings_quarantine IS '导入时未通过几何校验的建筑物行 (invalid_geometry=quarantine)';
+COMMENT ON COLUMN hzdk_buildings_quarantine.file_path IS '导入文件路径';
+COMMENT ON COLUMN hzdk_buildings_quarantine.line_number IS '文件中的行号, 从1开始';
+COMMENT ON COLUMN hzdk_buildings_quarantine.line IS '原始行内容';
+COMMENT ON COLUMN hzdk_buildings_quarantine.reason IS '原因代码: unclosed_ring/self_intersection/coordinate_out_of_range 等';
+COMMENT ON COLUMN hzdk_buildings_quarantine.detail IS '问题位置或 PostGIS 给出的说明';
+
+CREATE INDEX IF NOT EXISTS idx_hzdk_buildings_quarantine_file
+    ON hzdk_buildings_quarantine (file_path, line_number);
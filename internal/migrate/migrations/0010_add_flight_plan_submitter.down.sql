@@ -0,0 +1 @@
+ALTER TABLE flight_plans DROP COLUMN IF EXISTS submitted_by;
@@ -0,0 +1,3 @@
+ALTER TABLE flight_plans ADD COLUMN IF NOT EXISTS submitted_by character varying(80) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN flight_plans.submitted_by IS '提交人 (API Key 名称或 JWT sub), 审批人不能与提交人相同; 未启用认证时为空';
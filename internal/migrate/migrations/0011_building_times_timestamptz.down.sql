@@ -0,0 +1,7 @@
+ALTER TABLE hzdk_buildings_history
+    ALTER COLUMN create_time TYPE timestamp,
+    ALTER COLUMN update_time TYPE timestamp;
+
+ALTER TABLE hzdk_buildings
+    ALTER COLUMN create_time TYPE timestamp,
+    ALTER COLUMN update_time TYPE timestamp;
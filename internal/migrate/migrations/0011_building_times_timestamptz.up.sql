@@ -0,0 +1,9 @@
+-- 建筑物的 create_time/update_time 改为 timestamptz, 使历史版本的有效期 (valid_from/valid_to) 与 as_of 查询不受会话时区影响
+-- 已有数据按执行迁移时会话的 TimeZone 解释, 请在与应用写入时相同的时区设置下执行
+ALTER TABLE hzdk_buildings
+    ALTER COLUMN create_time TYPE timestamptz,
+    ALTER COLUMN update_time TYPE timestamptz;
+
+ALTER TABLE hzdk_buildings_history
+    ALTER COLUMN create_time TYPE timestamptz,
+    ALTER COLUMN update_time TYPE timestamptz;
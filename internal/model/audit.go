@@ -0,0 +1,93 @@
+// internal/model/audit.go
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Collision check kinds, as recorded in the audit log and the checks_total metric.
+const (
+	CheckPoint       = "point"
+	CheckPoint3D     = "point_3d"
+	CheckZones       = "zones"
+	CheckLineOfSight = "line_of_sight"
+	CheckRoute       = "route"
+	CheckPredict     = "predict"
+)
+
+// Sources of recorded checks.
+const (
+	SourceAPI       = "api"
+	SourceGRPC      = "grpc"
+	SourceTelemetry = "telemetry"
+	SourceCLI       = "cli"
+)
+
+// AuditInput holds the inputs of a recorded check. Only the fields used by the
+// check's kind are set; together they are enough to run the check again.
+type AuditInput struct {
+	Position          *Point3D      `json:"position,omitempty"` // point, point_3d, zones, predict
+	From              *Point3D      `json:"from,omitempty"`     // line_of_sight
+	To                *Point3D      `json:"to,omitempty"`       // line_of_sight
+	Route             []Point3D     `json:"route,omitempty"`    // route
+	Mode              string        `json:"mode,omitempty"`     // line_of_sight
+	CollisionDistance *float64      `json:"collision_distance,omitempty"`
+	Footprint         *Footprint    `json:"footprint,omitempty"`
+	Zones             []WarningZone `json:"zones,omitempty"` // the zones in effect, before inflation
+	Heading           *float64      `json:"heading,omitempty"`
+	GroundSpeed       *float64      `json:"ground_speed,omitempty"`
+	VerticalSpeed     *float64      `json:"vertical_speed,omitempty"`
+	LookAhead         *float64      `json:"look_ahead,omitempty"`
+	AsOf              *time.Time    `json:"as_of,omitempty"` // checked against the buildings at this time
+}
+
+// AuditOutcome is the comparable part of a check's answer.
+type AuditOutcome struct {
+	// IsCollision is set for a positive result; for line_of_sight it means blocked.
+	IsCollision bool   `json:"is_collision"`
+	Level       string `json:"level,omitempty"` // zones only
+	// BuildingIDs are the matched buildings, sorted and without duplicates.
+	BuildingIDs []int64 `json:"building_ids"`
+	// ErrorCode is set when the check failed.
+	ErrorCode string `json:"error_code,omitempty"`
+}
+
+// AuditEntry is one recorded collision check.
+type AuditEntry struct {
+	ID         int64      `json:"id"`
+	Time       time.Time  `json:"time"`
+	RequestID  string     `json:"request_id"`
+	Source     string     `json:"source"`      // see Source*
+	Caller     string     `json:"caller"`      // authenticated subject, empty when unknown
+	AuthMethod string     `json:"auth_method"` // see AuthMethod*
+	ClientAddr string     `json:"client_addr"`
+	Check      string     `json:"check"`
+	Input      AuditInput `json:"input"`
+	AuditOutcome
+	LatencyMs float64 `json:"latency_ms"`
+	// Result is the full answer as returned to the caller; it is not stored when
+	// result storage is disabled, or for failed checks.
+	Result json.RawMessage `json:"result,omitempty"`
+}
+
+// AuditReplay compares a recorded answer with the answer the current data gives.
+type AuditReplay struct {
+	EntryID   int64        `json:"entry_id"`
+	Time      time.Time    `json:"time"`
+	RequestID string       `json:"request_id"`
+	Check     string       `json:"check"`
+	Recorded  AuditOutcome `json:"recorded"`
+	Replayed  AuditOutcome `json:"replayed"`
+	// Added and Removed list the buildings matched only now, or only then.
+	Added   []int64 `json:"added"`
+	Removed []int64 `json:"removed"`
+	Differs bool    `json:"differs"`
+}
+
+// AuditReplaySummary counts the results of a replay.
+type AuditReplaySummary struct {
+	Replayed  int `json:"replayed"`
+	Identical int `json:"identical"`
+	Differ    int `json:"differ"`
+}
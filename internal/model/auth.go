@@ -0,0 +1,60 @@
+// internal/model/auth.go
+package model
+
+import "time"
+
+// Roles, from least to most privileged. Each role can do everything the roles below it can.
+const (
+	RoleViewer   = "viewer"   // collision queries
+	RoleOperator = "operator" // flight plans
+	RoleAdmin    = "admin"    // building imports and updates, flight plan review
+)
+
+// Authentication methods recorded on a Principal.
+const (
+	AuthMethodAPIKey = "api_key"
+	AuthMethodJWT    = "jwt"
+	AuthMethodNone   = "none" // authentication disabled
+)
+
+var roleRank = map[string]int{RoleViewer: 1, RoleOperator: 2, RoleAdmin: 3}
+
+// ValidRole reports whether role is one of the known roles.
+func ValidRole(role string) bool {
+	_, ok := roleRank[role]
+	return ok
+}
+
+// RoleAllows reports whether role grants the permissions of required.
+func RoleAllows(role, required string) bool {
+	return roleRank[role] >= roleRank[required] && roleRank[role] > 0
+}
+
+// HighestRole returns the most privileged known role in roles, or "" if there is none.
+func HighestRole(roles []string) string {
+	best := ""
+	for _, r := range roles {
+		if roleRank[r] > roleRank[best] {
+			best = r
+		}
+	}
+	return best
+}
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	Subject string `json:"subject"` // API key name or JWT sub claim
+	Role    string `json:"role"`
+	Method  string `json:"method"`
+	KeyID   int64  `json:"key_id,omitempty"` // API key ID, for api_key principals
+}
+
+// APIKey is a stored API key. Only the SHA-256 hash of the key is persisted.
+type APIKey struct {
+	ID           int64      `json:"id"`
+	Name         string     `json:"name"`
+	Role         string     `json:"role"`
+	Revoked      bool       `json:"revoked"`
+	CreateTime   time.Time  `json:"create_time"`
+	LastUsedTime *time.Time `json:"last_used_time,omitempty"`
+}
@@ -1,6 +1,16 @@
 // internal/model/building.go
 package model
 
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
 // Building represents a building record from the database.
 // Using pointers for fields that can be NULL in the database.
 type Building struct {
@@ -17,3 +27,275 @@ type Building struct {
 	// BuildingHeight could potentially be NULL, use *float64.
 	BuildingHeight *float64 `json:"building_height,omitempty" db:"building_height"`
 }
+
+// BuildingRecord is a building with every attribute, as exported.
+type BuildingRecord struct {
+	BuildingID     int64     `json:"building_id"`
+	BuildingType   *string   `json:"building_type"`
+	BuildingName   *string   `json:"building_name"`
+	BuildingAddr   *string   `json:"building_addr"`
+	AreaCode       *string   `json:"area_code"`
+	BuildingHeight *float64  `json:"building_height"`
+	RoofHeight     *float64  `json:"roof_height"`
+	CreateTime     time.Time `json:"create_time"`
+	UpdateTime     time.Time `json:"update_time"`
+	// WKB is the footprint in WKB, nil when the row has no geometry.
+	WKB []byte `json:"-"`
+}
+
+// BuildingFilter selects the buildings to export. Zero values match every building.
+type BuildingFilter struct {
+	// BBox holds min longitude, min latitude, max longitude and max latitude; nil
+	// matches everywhere. Buildings intersecting the box are selected.
+	BBox     *[4]float64
+	AreaCode string
+	Type     string
+}
+
+// ParseBBox parses "minLon,minLat,maxLon,maxLat".
+func ParseBBox(s string) (*[4]float64, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return nil, errors.New("expected minLon,minLat,maxLon,maxLat")
+	}
+	var bbox [4]float64
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", part)
+		}
+		bbox[i] = v
+	}
+	if bbox[0] > bbox[2] || bbox[1] > bbox[3] {
+		return nil, errors.New("min must not exceed max")
+	}
+	return &bbox, nil
+}
+
+// Building export formats.
+const (
+	ExportWKT        = "wkt"     // WKT,height lines, the format import reads
+	ExportGeoJSON    = "geojson" // a GeoJSON FeatureCollection
+	ExportNDJSON     = "ndjson"  // one GeoJSON Feature per line
+	ExportCSV        = "csv"     // attributes with the footprint as WKT
+	ExportShapefile  = "shp"     // a zipped ESRI Shapefile
+	ExportGeoPackage = "gpkg"    // an OGC GeoPackage
+)
+
+// ExportFormats lists the supported export formats.
+var ExportFormats = []string{ExportWKT, ExportGeoJSON, ExportNDJSON, ExportCSV, ExportShapefile, ExportGeoPackage}
+
+// Collision check modes.
+const (
+	// Mode25D treats each building as its footprint extruded from the ground to building_height.
+	Mode25D = "2.5d"
+	// Mode3D uses the per-part solids (base to top height) and true 3D distances.
+	Mode3D = "3d"
+)
+
+// SolidHit is a building part (an extruded solid) near a query point.
+type SolidHit struct {
+	Building   Building `json:"building"`
+	PartID     int      `json:"part_id"`
+	BaseHeight float64  `json:"base_height"`
+	TopHeight  float64  `json:"top_height"`
+	// Distance is the 3D distance in meters from the query point to the solid.
+	Distance float64 `json:"distance"`
+}
+
+// Policies for import lines with an invalid footprint.
+const (
+	// InvalidGeometryReject skips the line.
+	InvalidGeometryReject = "reject"
+	// InvalidGeometryRepair closes open rings and imports the result of ST_MakeValid;
+	// lines that cannot be repaired are skipped.
+	InvalidGeometryRepair = "repair"
+	// InvalidGeometryQuarantine stores the line in hzdk_buildings_quarantine instead.
+	InvalidGeometryQuarantine = "quarantine"
+)
+
+// InvalidGeometryPolicies lists the accepted invalid geometry policies.
+var InvalidGeometryPolicies = []string{InvalidGeometryReject, InvalidGeometryRepair, InvalidGeometryQuarantine}
+
+// ImportOptions controls a building import.
+type ImportOptions struct {
+	// InvalidGeometry is the policy for lines that fail validation: one of the
+	// InvalidGeometry constants.
+	InvalidGeometry string
+}
+
+// What happened to an import line with an issue.
+const (
+	ImportRejected    = "rejected"
+	ImportRepaired    = "repaired"
+	ImportQuarantined = "quarantined"
+)
+
+// Reasons for import issues, besides the footprint problems found by geom.Validate
+// (empty, unclosed_ring, too_few_points, coordinate_out_of_range, zero_area and
+// self_intersection).
+const (
+	ReasonMalformedLine   = "malformed_line"   // not "WKT,height"
+	ReasonInvalidHeight   = "invalid_height"   // not a finite, non-negative number
+	ReasonInvalidWKT      = "invalid_wkt"      // not a 2D POLYGON or MULTIPOLYGON
+	ReasonInvalidGeometry = "invalid_geometry" // rejected by PostGIS ST_IsValid
+	ReasonRepairFailed    = "repair_failed"    // ST_MakeValid left no polygon
+	ReasonInsertFailed    = "insert_failed"    // the database rejected the row
+)
+
+// MaxImportIssues bounds ImportResult.Issues; Reasons still counts every issue.
+const MaxImportIssues = 1000
+
+// ImportIssue is an import line that was rejected, repaired or quarantined.
+type ImportIssue struct {
+	Line   int    `json:"line"`
+	Action string `json:"action"`
+	Reason string `json:"reason"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// ImportResult summarizes a building import.
+type ImportResult struct {
+	// SuccessCount is the number of buildings imported, including repaired ones.
+	SuccessCount int `json:"success_count"`
+	// ErrorCount is the number of lines not imported, including quarantined ones.
+	ErrorCount int `json:"error_count"`
+	TotalCount int `json:"total_count"`
+	// SuccessRate is the percentage of lines imported successfully.
+	SuccessRate float64 `json:"success_rate"`
+
+	RepairedCount    int `json:"repaired_count"`
+	QuarantinedCount int `json:"quarantined_count"`
+	// ReorientedCount is the number of imported footprints whose rings were reversed
+	// to make exterior rings counter-clockwise and holes clockwise.
+	ReorientedCount int `json:"reoriented_count"`
+	// Reasons counts the issues by reason.
+	Reasons map[string]int `json:"reasons,omitempty"`
+	// Issues lists the first MaxImportIssues issues, in line order.
+	Issues          []ImportIssue `json:"issues,omitempty"`
+	IssuesTruncated bool          `json:"issues_truncated,omitempty"`
+}
+
+// AddIssue records an issue in the result.
+func (r *ImportResult) AddIssue(issue ImportIssue) {
+	if r.Reasons == nil {
+		r.Reasons = map[string]int{}
+	}
+	r.Reasons[issue.Reason]++
+	if len(r.Issues) < MaxImportIssues {
+		r.Issues = append(r.Issues, issue)
+	} else {
+		r.IssuesTruncated = true
+	}
+}
+
+// UnassignedArea is the ImportReport.Areas key of footprints with no existing
+// building that has an area code nearby.
+const UnassignedArea = "unassigned"
+
+// ImportReport is the result of a dry-run import: what importing the file would do,
+// without writing anything. The embedded counts and issues are those the import
+// would report, so a "quarantined" issue would be quarantined.
+type ImportReport struct {
+	ImportResult
+	FilePath        string `json:"file_path"`
+	InvalidGeometry string `json:"invalid_geometry"`
+
+	// DuplicateCount is the number of importable lines whose building_id already
+	// exists in hzdk_buildings or appears on an earlier line. The import would add
+	// them again.
+	DuplicateCount      int               `json:"duplicate_count"`
+	Duplicates          []ImportDuplicate `json:"duplicates,omitempty"`
+	DuplicatesTruncated bool              `json:"duplicates_truncated,omitempty"`
+
+	// Heights describes the heights of the importable lines.
+	Heights HeightStats `json:"heights"`
+	// BBox holds min longitude, min latitude, max longitude and max latitude of the
+	// importable footprints; nil when there are none.
+	BBox *[4]float64 `json:"bbox"`
+	// Areas counts the importable footprints by area. Import lines carry no area
+	// code, so a footprint takes that of the nearest existing building nearby,
+	// preferring one it intersects, or UnassignedArea.
+	Areas map[string]int `json:"areas"`
+}
+
+// ImportDuplicate is an import line whose building_id is already taken.
+type ImportDuplicate struct {
+	Line       int   `json:"line"`
+	BuildingID int64 `json:"building_id"`
+	// Existing is true when hzdk_buildings holds the building; otherwise FirstLine
+	// is the earlier line with the same footprint.
+	Existing  bool `json:"existing"`
+	FirstLine int  `json:"first_line,omitempty"`
+}
+
+// AddDuplicate records a duplicate in the report.
+func (r *ImportReport) AddDuplicate(d ImportDuplicate) {
+	r.DuplicateCount++
+	if len(r.Duplicates) < MaxImportIssues {
+		r.Duplicates = append(r.Duplicates, d)
+	} else {
+		r.DuplicatesTruncated = true
+	}
+}
+
+// HeightBucket counts the heights in [From, To); To is nil for the last bucket.
+type HeightBucket struct {
+	From  float64  `json:"from"`
+	To    *float64 `json:"to"`
+	Count int      `json:"count"`
+}
+
+// HeightStats summarizes building heights, in meters.
+type HeightStats struct {
+	Count     int            `json:"count"`
+	Min       float64        `json:"min"`
+	Max       float64        `json:"max"`
+	Mean      float64        `json:"mean"`
+	P50       float64        `json:"p50"`
+	P90       float64        `json:"p90"`
+	P99       float64        `json:"p99"`
+	Histogram []HeightBucket `json:"histogram"`
+}
+
+// heightBucketEdges are the lower bounds of the HeightStats histogram buckets.
+var heightBucketEdges = []float64{0, 10, 20, 30, 50, 100, 200}
+
+// NewHeightStats summarizes heights, which it sorts.
+func NewHeightStats(heights []float64) HeightStats {
+	stats := HeightStats{Count: len(heights), Histogram: make([]HeightBucket, len(heightBucketEdges))}
+	for i, from := range heightBucketEdges {
+		stats.Histogram[i].From = from
+		if i+1 < len(heightBucketEdges) {
+			to := heightBucketEdges[i+1]
+			stats.Histogram[i].To = &to
+		}
+	}
+	if len(heights) == 0 {
+		return stats
+	}
+
+	sort.Float64s(heights)
+	sum := 0.0
+	for _, h := range heights {
+		sum += h
+		i := sort.SearchFloat64s(heightBucketEdges, h)
+		if i == len(heightBucketEdges) || heightBucketEdges[i] != h {
+			i-- // h lies above edge i-1
+		}
+		stats.Histogram[i].Count++
+	}
+	// Nearest-rank percentiles
+	percentile := func(p float64) float64 {
+		return heights[int(math.Ceil(p/100*float64(len(heights))))-1]
+	}
+	stats.Min, stats.Max = heights[0], heights[len(heights)-1]
+	stats.Mean = sum / float64(len(heights))
+	stats.P50, stats.P90, stats.P99 = percentile(50), percentile(90), percentile(99)
+	return stats
+}
+
+// UpdateResult summarizes a batch update of building information.
+type UpdateResult struct {
+	UpdatedCount int `json:"updated_count"`
+}
@@ -0,0 +1,67 @@
+// internal/model/collision.go
+package model
+
+// CollisionResult is the result of a 2.5D point collision check.
+type CollisionResult struct {
+	Mode        string          `json:"mode"`
+	IsCollision bool            `json:"is_collision"`
+	Margin      MarginBreakdown `json:"margin"`
+	// BuildingInfos lists the colliding buildings; it is omitted when there is no collision.
+	BuildingInfos []Building `json:"building_infos,omitempty"`
+}
+
+// SolidCollisionResult is the result of a 3D point collision check against building parts.
+type SolidCollisionResult struct {
+	Mode        string          `json:"mode"`
+	IsCollision bool            `json:"is_collision"`
+	Margin      MarginBreakdown `json:"margin"`
+	// BuildingInfos lists the colliding parts; it is omitted when there is no collision.
+	BuildingInfos []SolidHit `json:"building_infos,omitempty"`
+}
+
+// ZonesResult classifies the buildings around a point into warning zones.
+type ZonesResult struct {
+	// IsCollision is set when a building lies in the critical zone.
+	IsCollision bool `json:"is_collision"`
+	// Level is the most severe zone containing a building, or "" when all are clear.
+	Level     string                        `json:"level"`
+	Zones     []WarningZone                 `json:"zones"`
+	Margins   map[string]MarginBreakdown    `json:"margins"`
+	Buildings map[string][]BuildingDistance `json:"buildings"`
+}
+
+// LineOfSightResult reports whether the segment between two points is unobstructed.
+type LineOfSightResult struct {
+	Mode              string             `json:"mode"`
+	IsVisible         bool               `json:"is_visible"`
+	Distance          float64            `json:"distance"`
+	BlockingBuildings []LineOfSightBlock `json:"blocking_buildings"`
+}
+
+// PredictionResult is the outcome of projecting a drone's trajectory forward. The
+// fields after LookAhead are only set when a collision is predicted.
+type PredictionResult struct {
+	IsCollision              bool              `json:"is_collision"`
+	LookAhead                float64           `json:"look_ahead"`
+	Margin                   MarginBreakdown   `json:"margin"`
+	TimeToCollision          *float64          `json:"time_to_collision,omitempty"`
+	FirstObstacle            *Building         `json:"first_obstacle,omitempty"`
+	CollisionPoint           *TrajectorySample `json:"collision_point,omitempty"`
+	Obstacles                []TrajectoryHit   `json:"obstacles,omitempty"`
+	RecommendedClimbAltitude *float64          `json:"recommended_climb_altitude,omitempty"`
+}
+
+// RouteLeg lists the buildings too close to leg Index of a route, which runs from
+// waypoint Index to waypoint Index+1.
+type RouteLeg struct {
+	Index     int                `json:"index"`
+	Buildings []BuildingDistance `json:"buildings"`
+}
+
+// RouteResult is the result of checking every leg of a 3D route.
+type RouteResult struct {
+	IsCollision bool            `json:"is_collision"`
+	Margin      MarginBreakdown `json:"margin"`
+	// Legs lists only the legs that come too close to a building.
+	Legs []RouteLeg `json:"legs"`
+}
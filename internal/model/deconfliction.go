@@ -0,0 +1,34 @@
+// internal/model/deconfliction.go
+package model
+
+import "time"
+
+// SpaceTimeVolume is a slice of a flight plan: the route segment flown between
+// T0 and T1, extruded over the plan's altitude band.
+type SpaceTimeVolume struct {
+	T0          time.Time `json:"t0"`
+	T1          time.Time `json:"t1"`
+	From        Waypoint  `json:"from"`
+	To          Waypoint  `json:"to"`
+	AltitudeMin float64   `json:"altitude_min"`
+	AltitudeMax float64   `json:"altitude_max"`
+}
+
+// Shift returns a copy of the volume moved in time by d.
+func (v SpaceTimeVolume) Shift(d time.Duration) SpaceTimeVolume {
+	v.T0 = v.T0.Add(d)
+	v.T1 = v.T1.Add(d)
+	return v
+}
+
+// PlanConflict describes a loss of separation between two flight plans.
+type PlanConflict struct {
+	PlanID               int64     `json:"plan_id"`
+	DroneID              string    `json:"drone_id"`
+	PlanStatus           string    `json:"plan_status"` // approved or needs-review
+	Start                time.Time `json:"start"`
+	End                  time.Time `json:"end"`
+	Location             Waypoint  `json:"location"`
+	HorizontalSeparation float64   `json:"horizontal_separation"`
+	VerticalSeparation   float64   `json:"vertical_separation"`
+}
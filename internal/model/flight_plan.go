@@ -0,0 +1,90 @@
+// internal/model/flight_plan.go
+package model
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Flight plan statuses.
+const (
+	PlanApproved    = "approved"
+	PlanRejected    = "rejected"
+	PlanNeedsReview = "needs-review"
+)
+
+// MaxRouteWaypoints is the hard cap on the waypoints of a submitted flight plan
+// route. The configured limit may only lower it.
+const MaxRouteWaypoints = 1000
+
+// Waypoint is a 2D point of a flight plan route.
+type Waypoint struct {
+	Longitude float64 `json:"longitude" binding:"gte=-180,lte=180"`
+	Latitude  float64 `json:"latitude" binding:"gte=-90,lte=90"`
+}
+
+// FlightPlan is a route submitted by an operator for approval.
+type FlightPlan struct {
+	ID          int64      `json:"id" db:"id"`
+	DroneID     string     `json:"drone_id" db:"drone_id"`
+	Route       []Waypoint `json:"route" db:"route"`
+	AltitudeMin float64    `json:"altitude_min" db:"altitude_min"`
+	AltitudeMax float64    `json:"altitude_max" db:"altitude_max"`
+	StartTime   time.Time  `json:"start_time" db:"start_time"`
+	EndTime     time.Time  `json:"end_time" db:"end_time"`
+	Status      string     `json:"status" db:"status"`
+	Reasons     []string   `json:"reasons" db:"reasons"`
+	SubmittedBy string     `json:"submitted_by" db:"submitted_by"` // empty when authentication is disabled
+	CreateTime  time.Time  `json:"create_time" db:"create_time"`
+	UpdateTime  time.Time  `json:"update_time" db:"update_time"`
+}
+
+// FlightPlanEvent is one entry of a flight plan's status history.
+type FlightPlanEvent struct {
+	ID         int64     `json:"id" db:"id"`
+	PlanID     int64     `json:"plan_id" db:"plan_id"`
+	Status     string    `json:"status" db:"status"`
+	Reasons    []string  `json:"reasons" db:"reasons"`
+	Actor      string    `json:"actor" db:"actor"`
+	Comment    *string   `json:"comment,omitempty" db:"comment"`
+	CreateTime time.Time `json:"create_time" db:"create_time"`
+}
+
+// LineStringWKT returns the route as a WKT LINESTRING.
+func LineStringWKT(route []Waypoint) string {
+	var sb strings.Builder
+	sb.WriteString("LINESTRING(")
+	for i, p := range route {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		sb.WriteString(strconv.FormatFloat(p.Longitude, 'f', -1, 64))
+		sb.WriteString(" ")
+		sb.WriteString(strconv.FormatFloat(p.Latitude, 'f', -1, 64))
+	}
+	sb.WriteString(")")
+	return sb.String()
+}
+
+// FlightPlanSubmission is the assessed plan returned on submission.
+type FlightPlanSubmission struct {
+	Plan      FlightPlan     `json:"plan"`
+	Conflicts []PlanConflict `json:"conflicts"`
+	// SuggestedTimeShift, in seconds, clears every conflict when applied to the departure.
+	SuggestedTimeShift *float64 `json:"suggested_time_shift,omitempty"`
+}
+
+// FlightPlanDetail is a plan together with its status history.
+type FlightPlanDetail struct {
+	Plan    FlightPlan        `json:"plan"`
+	History []FlightPlanEvent `json:"history"`
+}
+
+// FlightPlanConflicts is the result of deconflicting a stored plan.
+type FlightPlanConflicts struct {
+	PlanID             int64          `json:"plan_id"`
+	IsConflict         bool           `json:"is_conflict"`
+	Conflicts          []PlanConflict `json:"conflicts"`
+	SuggestedTimeShift *float64       `json:"suggested_time_shift,omitempty"`
+}
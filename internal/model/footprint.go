@@ -0,0 +1,67 @@
+// internal/model/footprint.go
+package model
+
+// DefaultUERE is the user equivalent range error, in meters, used to turn a
+// dilution-of-precision value into a position uncertainty.
+const DefaultUERE = 5.0
+
+// Footprint describes the physical size of a drone and the uncertainty of its
+// reported position. Collision checks inflate their query volume by these margins.
+type Footprint struct {
+	// DroneRadius is the radius of the airframe in meters.
+	DroneRadius float64 `json:"drone_radius"`
+	// HorizontalUncertainty is the radius of the horizontal position error in meters.
+	HorizontalUncertainty float64 `json:"horizontal_uncertainty"`
+	// VerticalUncertainty is the vertical position error in meters.
+	VerticalUncertainty float64 `json:"vertical_uncertainty"`
+}
+
+// UncertaintyFromDOP converts a dilution-of-precision value (HDOP or VDOP) into a
+// position uncertainty in meters. A non-positive uere falls back to DefaultUERE.
+func UncertaintyFromDOP(dop, uere float64) float64 {
+	if uere <= 0 {
+		uere = DefaultUERE
+	}
+	return dop * uere
+}
+
+// HorizontalMargin is the extra horizontal distance the query volume is inflated by.
+func (f Footprint) HorizontalMargin() float64 {
+	return f.DroneRadius + f.HorizontalUncertainty
+}
+
+// VerticalMargin is the extra vertical distance the query volume is inflated by.
+func (f Footprint) VerticalMargin() float64 {
+	return f.DroneRadius + f.VerticalUncertainty
+}
+
+// Inflate returns a copy of the zone with its buffers grown by the footprint margins.
+func (z WarningZone) Inflate(f Footprint) WarningZone {
+	z.Horizontal += f.HorizontalMargin()
+	z.Vertical += f.VerticalMargin()
+	return z
+}
+
+// MarginBreakdown reports how the total buffer of a check is made up.
+type MarginBreakdown struct {
+	ConfiguredHorizontal  float64 `json:"configured_horizontal"`
+	ConfiguredVertical    float64 `json:"configured_vertical"`
+	DroneRadius           float64 `json:"drone_radius"`
+	HorizontalUncertainty float64 `json:"horizontal_uncertainty"`
+	VerticalUncertainty   float64 `json:"vertical_uncertainty"`
+	TotalHorizontal       float64 `json:"total_horizontal"`
+	TotalVertical         float64 `json:"total_vertical"`
+}
+
+// Breakdown combines the configured buffers with the footprint margins.
+func (f Footprint) Breakdown(horizontal, vertical float64) MarginBreakdown {
+	return MarginBreakdown{
+		ConfiguredHorizontal:  horizontal,
+		ConfiguredVertical:    vertical,
+		DroneRadius:           f.DroneRadius,
+		HorizontalUncertainty: f.HorizontalUncertainty,
+		VerticalUncertainty:   f.VerticalUncertainty,
+		TotalHorizontal:       horizontal + f.HorizontalMargin(),
+		TotalVertical:         vertical + f.VerticalMargin(),
+	}
+}
@@ -0,0 +1,24 @@
+// internal/model/health.go
+package model
+
+// Readiness statuses.
+const (
+	ReadinessReady    = "ready"
+	ReadinessNotReady = "not_ready"
+)
+
+// Readiness is the result of a readiness probe. Checks maps each dependency to "ok"
+// or the reason it failed; "pool" holds the connection pool statistics.
+type Readiness struct {
+	Status string         `json:"status"`
+	Checks map[string]any `json:"checks"`
+}
+
+// PoolStats is a snapshot of the database connection pool.
+type PoolStats struct {
+	Acquired   int32   `json:"acquired"`
+	Idle       int32   `json:"idle"`
+	Total      int32   `json:"total"`
+	Max        int32   `json:"max"`
+	Saturation float64 `json:"saturation"`
+}
@@ -0,0 +1,41 @@
+// internal/model/line_of_sight.go
+package model
+
+// Point3D is a geographic position with a height in meters.
+type Point3D struct {
+	Longitude float64 `json:"longitude"`
+	Latitude  float64 `json:"latitude"`
+	Height    float64 `json:"height"`
+}
+
+// Lerp returns the point at fraction f of the straight segment from p to q.
+func (p Point3D) Lerp(q Point3D, f float64) Point3D {
+	return Point3D{
+		Longitude: p.Longitude + (q.Longitude-p.Longitude)*f,
+		Latitude:  p.Latitude + (q.Latitude-p.Latitude)*f,
+		Height:    p.Height + (q.Height-p.Height)*f,
+	}
+}
+
+// RaySpan is the part of a ray's ground track that crosses a building footprint,
+// expressed as fractions of the ray length. Base and Top bound the extruded volume;
+// in 2.5D mode Base is negative infinity so the solid reaches down indefinitely.
+type RaySpan struct {
+	Building
+	PartID int     `json:"part_id"`
+	Base   float64 `json:"base"`
+	Top    float64 `json:"top"`
+	From   float64 `json:"from"`
+	To     float64 `json:"to"`
+}
+
+// LineOfSightBlock is a building that blocks a line of sight, with the points where
+// the ray enters and leaves the extruded building volume.
+type LineOfSightBlock struct {
+	Building      Building `json:"building"`
+	PartID        int      `json:"part_id"`
+	Entry         Point3D  `json:"entry"`
+	Exit          Point3D  `json:"exit"`
+	EntryDistance float64  `json:"entry_distance"`
+	ExitDistance  float64  `json:"exit_distance"`
+}
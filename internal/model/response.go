@@ -0,0 +1,32 @@
+// internal/model/response.go
+package model
+
+// Response statuses.
+const (
+	StatusSuccess = "success"
+	StatusError   = "error"
+)
+
+// Response is the envelope of every API response. Data is set on success and Error on
+// failure; RequestID echoes the X-Request-ID of the request.
+type Response struct {
+	Status    string     `json:"status"`
+	Data      any        `json:"data,omitempty"`
+	Error     *ErrorBody `json:"error,omitempty"`
+	RequestID string     `json:"request_id,omitempty"`
+}
+
+// ErrorBody describes a failed request. Code is a stable machine-readable code from
+// the apperr catalogue; Message is localized from Accept-Language.
+type ErrorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details any    `json:"details,omitempty"`
+}
+
+// FieldError is one invalid request field, returned in ErrorBody.Details.
+type FieldError struct {
+	In      string `json:"in"`   // query, path or body
+	Name    string `json:"name"` // parameter name or JSON path of the body field
+	Message string `json:"message"`
+}
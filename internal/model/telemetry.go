@@ -0,0 +1,58 @@
+// internal/model/telemetry.go
+package model
+
+import "time"
+
+// TrackedSystem is the last known state of a MAVLink system (one vehicle) seen by
+// the telemetry listener.
+type TrackedSystem struct {
+	SystemID    uint8  `json:"system_id"`
+	ComponentID uint8  `json:"component_id"`
+	Addr        string `json:"addr"`
+	// VehicleType and Autopilot are the MAV_TYPE and MAV_AUTOPILOT of the last heartbeat.
+	VehicleType   uint8      `json:"vehicle_type"`
+	Autopilot     uint8      `json:"autopilot"`
+	Armed         bool       `json:"armed"`
+	LastHeartbeat *time.Time `json:"last_heartbeat,omitempty"`
+	// Position uses the altitude above home as height.
+	Position      *Point3D   `json:"position,omitempty"`
+	Heading       *float64   `json:"heading,omitempty"`
+	GroundSpeed   float64    `json:"ground_speed"`
+	VerticalSpeed float64    `json:"vertical_speed"`
+	LastPosition  *time.Time `json:"last_position,omitempty"`
+	// Level is the most severe warning zone of the last check, or "" when clear.
+	Level     string     `json:"level"`
+	LastCheck *time.Time `json:"last_check,omitempty"`
+	// Geofence is the result of the last geofence check; it is omitted when the
+	// vehicle has no active approved flight plan.
+	Geofence *GeofenceStatus `json:"geofence,omitempty"`
+}
+
+// Telemetry alert types.
+const (
+	AlertZone     = "zone"     // the warning level changed
+	AlertGeofence = "geofence" // the vehicle left or re-entered its flight plan corridor
+)
+
+// TelemetryAlert is emitted when the warning level of a tracked system changes, or
+// when it breaches or returns into its geofence. Level "" means the system is
+// outside every warning zone.
+type TelemetryAlert struct {
+	Type          string             `json:"type"`
+	SystemID      uint8              `json:"system_id"`
+	Addr          string             `json:"addr"`
+	Level         string             `json:"level"`
+	PreviousLevel string             `json:"previous_level"`
+	IsCollision   bool               `json:"is_collision"`
+	Position      Point3D            `json:"position"`
+	Buildings     []BuildingDistance `json:"buildings"`
+	// Geofence is set on geofence alerts.
+	Geofence *GeofenceStatus `json:"geofence,omitempty"`
+	Time     time.Time       `json:"time"`
+}
+
+// Breached reports whether a geofence alert signals a breach rather than a return
+// into the corridor.
+func (a TelemetryAlert) Breached() bool {
+	return a.Geofence != nil && !a.Geofence.Inside
+}
@@ -0,0 +1,18 @@
+// internal/model/trajectory.go
+package model
+
+// TrajectorySample is a single projected position of a drone along its predicted path.
+type TrajectorySample struct {
+	// T is the time offset in seconds from the current position.
+	T         float64 `json:"t"`
+	Longitude float64 `json:"longitude"`
+	Latitude  float64 `json:"latitude"`
+	Height    float64 `json:"height"`
+}
+
+// TrajectoryHit is a building that the projected trajectory runs into,
+// together with the point of the trajectory where the collision is first found.
+type TrajectoryHit struct {
+	Building Building         `json:"building"`
+	At       TrajectorySample `json:"at"`
+}
@@ -0,0 +1,118 @@
+// internal/model/webhook.go
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook event types.
+const (
+	EventCollision      = "collision"       // a check or monitored vehicle found a collision
+	EventWarning        = "warning"         // a building entered a non-critical warning zone
+	EventGeofenceBreach = "geofence_breach" // a monitored vehicle left its approved flight plan
+	EventImportFinished = "import_finished" // a building import completed
+)
+
+// EventTypes lists every webhook event type.
+var EventTypes = []string{EventCollision, EventWarning, EventGeofenceBreach, EventImportFinished}
+
+// ValidEventType reports whether t is a known webhook event type.
+func ValidEventType(t string) bool {
+	for _, known := range EventTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Webhook delivery statuses.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed" // retries exhausted
+)
+
+// WebhookSubscription is a URL registered to receive events of the given types.
+// The signing secret is only returned when the subscription is created.
+type WebhookSubscription struct {
+	ID          int64     `json:"id"`
+	URL         string    `json:"url"`
+	Events      []string  `json:"events"`
+	Description string    `json:"description"`
+	Active      bool      `json:"active"`
+	CreatedBy   string    `json:"created_by"`
+	CreateTime  time.Time `json:"create_time"`
+	Secret      string    `json:"secret,omitempty"`
+}
+
+// WebhookEvent is the JSON body POSTed to subscribers.
+type WebhookEvent struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	CreateTime time.Time `json:"create_time"`
+	Data       any       `json:"data"`
+}
+
+// WebhookDelivery is one outbox entry: an event queued for one subscription.
+type WebhookDelivery struct {
+	ID              int64      `json:"id"`
+	SubscriptionID  int64      `json:"subscription_id"`
+	EventID         string     `json:"event_id"`
+	EventType       string     `json:"event_type"`
+	Status          string     `json:"status"`
+	Attempts        int        `json:"attempts"`
+	NextAttemptTime time.Time  `json:"next_attempt_time"`
+	LastStatus      *int       `json:"last_status,omitempty"`
+	LastError       *string    `json:"last_error,omitempty"`
+	CreateTime      time.Time  `json:"create_time"`
+	DeliveredTime   *time.Time `json:"delivered_time,omitempty"`
+	// Payload is the stored request body; it is not listed in API responses.
+	Payload json.RawMessage `json:"-"`
+	// URL and Secret come from the subscription when a delivery is claimed for sending.
+	URL    string `json:"-"`
+	Secret string `json:"-"`
+}
+
+// CollisionEvent is the data of collision and warning events.
+type CollisionEvent struct {
+	// Source is where the check came from: api, grpc or telemetry.
+	Source string `json:"source"`
+	// Check is the kind of check, as in the checks_total metric (point, point_3d,
+	// zones, route, predict, stream or telemetry).
+	Check    string   `json:"check"`
+	DroneID  string   `json:"drone_id,omitempty"`
+	Position *Point3D `json:"position,omitempty"`
+	Level    string   `json:"level,omitempty"`
+	Result   any      `json:"result"`
+}
+
+// GeofenceStatus compares a position with the corridor of the drone's active
+// approved flight plan.
+type GeofenceStatus struct {
+	PlanID      int64   `json:"plan_id"`
+	Inside      bool    `json:"inside"`
+	Distance    float64 `json:"distance"` // horizontal distance to the route in meters
+	Corridor    float64 `json:"corridor"` // allowed horizontal distance in meters
+	Height      float64 `json:"height"`
+	AltitudeMin float64 `json:"altitude_min"`
+	AltitudeMax float64 `json:"altitude_max"`
+	// Reasons explains a breach; it is empty when Inside is set.
+	Reasons []string `json:"reasons"`
+}
+
+// GeofenceEvent is the data of geofence_breach events.
+type GeofenceEvent struct {
+	Source   string         `json:"source"`
+	DroneID  string         `json:"drone_id"`
+	Position Point3D        `json:"position"`
+	Geofence GeofenceStatus `json:"geofence"`
+}
+
+// ImportEvent is the data of import_finished events.
+type ImportEvent struct {
+	Source   string       `json:"source"` // api or cli
+	FilePath string       `json:"file_path"`
+	Result   ImportResult `json:"result"`
+}
@@ -0,0 +1,90 @@
+// internal/model/zone.go
+package model
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Warning zone levels, from least to most severe.
+const (
+	ZoneCaution  = "caution"
+	ZoneWarning  = "warning"
+	ZoneCritical = "critical"
+)
+
+// zoneSeverity ranks the known zone levels; higher is more severe.
+var zoneSeverity = map[string]int{
+	ZoneCaution:  1,
+	ZoneWarning:  2,
+	ZoneCritical: 3,
+}
+
+// WarningZone is one tier of the safety envelope around a drone.
+// A building is inside the zone when its footprint lies within Horizontal meters
+// of the drone and its roof is less than Vertical meters below the drone.
+type WarningZone struct {
+	Level      string  `json:"level"`
+	Horizontal float64 `json:"horizontal"`
+	Vertical   float64 `json:"vertical"`
+}
+
+// Severity returns the rank of the zone level; higher is more severe.
+func (z WarningZone) Severity() int {
+	return zoneSeverity[z.Level]
+}
+
+// Contains reports whether a building at the given horizontal distance and of the
+// given height lies inside the zone for a drone flying at droneHeight.
+func (z WarningZone) Contains(distance, droneHeight, buildingHeight float64) bool {
+	return distance <= z.Horizontal && droneHeight < buildingHeight+z.Vertical
+}
+
+// BuildingDistance is a building together with its horizontal distance to a query point.
+type BuildingDistance struct {
+	Building
+	Distance float64 `json:"distance"`
+}
+
+// ParseWarningZones parses a zone list of the form
+// "caution:30:20,warning:15:10,critical:5:2" (level:horizontal:vertical).
+// The result is sorted from most to least severe.
+func ParseWarningZones(spec string) ([]WarningZone, error) {
+	var zones []WarningZone
+	seen := make(map[string]bool)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.Split(item, ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid zone %q: expected level:horizontal:vertical", item)
+		}
+		level := strings.ToLower(strings.TrimSpace(parts[0]))
+		if _, ok := zoneSeverity[level]; !ok {
+			return nil, fmt.Errorf("invalid zone %q: unknown level %q", item, level)
+		}
+		if seen[level] {
+			return nil, fmt.Errorf("invalid zone %q: level %q defined twice", item, level)
+		}
+		horizontal, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if err != nil || math.IsNaN(horizontal) || math.IsInf(horizontal, 0) || horizontal < 0 {
+			return nil, fmt.Errorf("invalid zone %q: bad horizontal buffer", item)
+		}
+		vertical, err := strconv.ParseFloat(strings.TrimSpace(parts[2]), 64)
+		if err != nil || math.IsNaN(vertical) || math.IsInf(vertical, 0) || vertical < 0 {
+			return nil, fmt.Errorf("invalid zone %q: bad vertical buffer", item)
+		}
+		seen[level] = true
+		zones = append(zones, WarningZone{Level: level, Horizontal: horizontal, Vertical: vertical})
+	}
+	if len(zones) == 0 {
+		return nil, fmt.Errorf("no warning zones defined")
+	}
+	sort.Slice(zones, func(i, j int) bool { return zones[i].Severity() > zones[j].Severity() })
+	return zones, nil
+}
@@ -0,0 +1,379 @@
+// internal/openapi/spec.go
+package openapi
+
+import (
+	"collision_app_go/internal/apperr"
+	"collision_app_go/internal/export"
+	"collision_app_go/internal/model"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Options carries the configured defaults and limits that appear in the document.
+type Options struct {
+	Version                  string
+	DefaultCollisionDistance float64
+	MaxCollisionDistance     float64
+	DefaultLookAhead         float64
+	MaxLookAhead             float64
+	DefaultPlanBuffer        float64
+	MaxPlanDuration          time.Duration
+	MaxPlanWaypoints         int
+	DefaultInvalidGeometry   string
+}
+
+// Build returns the OpenAPI document for every /api/v1 route.
+func Build(opts Options) *Document {
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info: Info{
+			Title: "Collision API",
+			Description: "无人机与建筑物碰撞检测、告警区域、通视分析、飞行计划审批、遥测告警及 Webhook 订阅接口。\n\n" +
+				"所有响应使用统一信封 {status, data | error, request_id}; 错误信息按 Accept-Language (zh 或 en, 默认 zh) 本地化。",
+			Version: opts.Version,
+		},
+		Paths: map[string]map[string]*Operation{},
+		Components: Components{
+			Schemas: map[string]*Schema{
+				"Error": {
+					Type: "object",
+					Properties: map[string]*Schema{
+						"status": {Type: "string", Enum: []string{model.StatusError}},
+						"error": {
+							Type: "object",
+							Properties: map[string]*Schema{
+								"code":    {Type: "string", Enum: apperr.Codes(), Description: "稳定的机器可读错误码"},
+								"message": {Type: "string", Description: "按 Accept-Language 本地化的错误信息"},
+								"details": {Description: "参数错误时为 FieldError 数组; 限流时为 {retry_after}"},
+							},
+							Required: []string{"code", "message"},
+						},
+						"request_id": {Type: "string"},
+					},
+					Required: []string{"status", "error"},
+				},
+				"FieldError": {
+					Type: "object",
+					Properties: map[string]*Schema{
+						"in":      {Type: "string", Enum: []string{"query", "path", "body"}},
+						"name":    {Type: "string"},
+						"message": {Type: "string"},
+					},
+				},
+				"Result": {
+					Type: "object",
+					Properties: map[string]*Schema{
+						"status":     {Type: "string", Enum: []string{model.StatusSuccess}},
+						"data":       {Description: "接口的结果对象"},
+						"request_id": {Type: "string"},
+					},
+					Required: []string{"status", "data"},
+				},
+				"Waypoint": {
+					Type: "object",
+					Properties: map[string]*Schema{
+						"longitude": longitude(),
+						"latitude":  latitude(),
+					},
+					Required: []string{"longitude", "latitude"},
+				},
+				"SubmitFlightPlanRequest": {
+					Type: "object",
+					Properties: map[string]*Schema{
+						"drone_id":     {Type: "string", MinLength: intPtr(1), MaxLength: intPtr(80)},
+						"route":        {Type: "array", MinItems: intPtr(2), MaxItems: intPtr(opts.MaxPlanWaypoints), Items: ref("Waypoint")},
+						"altitude_min": {Type: "number", Minimum: floatPtr(0), Description: "meters; must be below altitude_max"},
+						"altitude_max": {Type: "number", Minimum: floatPtr(0), Description: "meters"},
+						"start_time":   {Type: "string", Format: "date-time"},
+						"end_time":     {Type: "string", Format: "date-time", Description: "must be after start_time, by at most " + opts.MaxPlanDuration.String()},
+						"buffer":       {Type: "number", Minimum: floatPtr(0), Default: opts.DefaultPlanBuffer, Description: "obstacle clearance in meters"},
+					},
+					Required: []string{"drone_id", "route", "altitude_max", "start_time", "end_time"},
+				},
+				"ReviewFlightPlanRequest": {
+					Type: "object",
+					Properties: map[string]*Schema{
+						"decision": {Type: "string", Enum: []string{model.PlanApproved, model.PlanRejected}},
+						"reviewer": {Type: "string", MaxLength: intPtr(80), Description: "ignored when authenticated; the caller is recorded instead"},
+						"comment":  {Type: "string"},
+					},
+					Required: []string{"decision"},
+				},
+				"CreateWebhookRequest": {
+					Type: "object",
+					Properties: map[string]*Schema{
+						"url":         {Type: "string", MinLength: intPtr(1), MaxLength: intPtr(2000), Description: "http(s) URL receiving event POSTs"},
+						"events":      {Type: "array", MinItems: intPtr(1), Items: &Schema{Type: "string", Enum: model.EventTypes}},
+						"description": {Type: "string", MaxLength: intPtr(200)},
+					},
+					Required: []string{"url", "events"},
+				},
+			},
+			SecuritySchemes: map[string]*SecurityScheme{
+				"ApiKeyAuth": {Type: "apiKey", In: "header", Name: "X-API-Key"},
+				"BearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+		Security: []map[string][]string{{"ApiKeyAuth": {}}, {"BearerAuth": {}}},
+	}
+
+	distance := func() *Parameter {
+		return query("collision_distance", false, "检测距离 (米)",
+			&Schema{Type: "number", Minimum: floatPtr(0), Maximum: floatPtr(opts.MaxCollisionDistance), Default: opts.DefaultCollisionDistance})
+	}
+	mode := query("mode", false, "相交模式", &Schema{Type: "string", Enum: []string{model.Mode25D, model.Mode3D}, Default: model.Mode25D})
+	asOf := query("as_of", false, "按该时间点的建筑物数据检测 (RFC 3339), 用于事后还原当时的障碍物; 为空时使用当前数据. 分段数据不保留历史, 不能与 mode=3d 同时使用", &Schema{Type: "string", Format: "date-time"})
+	position := []*Parameter{
+		query("longitude", true, "经度", longitude()),
+		query("latitude", true, "纬度", latitude()),
+		query("height", true, "高度 (米)", height()),
+	}
+	footprint := []*Parameter{
+		query("drone_radius", false, "无人机半径 (米)", nonNegative()),
+		query("h_uncertainty", false, "水平定位不确定度 (米), 优先于 hdop", nonNegative()),
+		query("v_uncertainty", false, "垂直定位不确定度 (米), 优先于 vdop", nonNegative()),
+		query("hdop", false, "水平精度因子", nonNegative()),
+		query("vdop", false, "垂直精度因子", nonNegative()),
+		query("uere", false, fmt.Sprintf("用户等效测距误差 (米), 默认 %g", model.DefaultUERE), nonNegative()),
+	}
+	planID := &Parameter{Name: "id", In: "path", Required: true, Description: "飞行计划id", Schema: &Schema{Type: "integer", Minimum: floatPtr(1)}}
+	webhookID := &Parameter{Name: "id", In: "path", Required: true, Description: "Webhook 订阅id", Schema: &Schema{Type: "integer", Minimum: floatPtr(1)}}
+
+	doc.add("GET", "/api/v1/collision_info", model.RoleViewer, &Operation{
+		OperationID: "collisionInfo",
+		Summary:     "检测指定位置附近的建筑物碰撞",
+		Tags:        []string{"collision"},
+		Parameters:  concat(position, []*Parameter{distance()}, footprint, []*Parameter{mode, asOf}),
+	})
+	doc.add("GET", "/api/v1/collision_predict", model.RoleViewer, &Operation{
+		OperationID: "collisionPredict",
+		Summary:     "按当前运动状态预测未来轨迹上的碰撞",
+		Tags:        []string{"collision"},
+		Parameters: concat(position, []*Parameter{
+			query("heading", true, "航向 (度, 正北为0顺时针)", &Schema{Type: "number", Minimum: floatPtr(0), Maximum: floatPtr(360), ExclusiveMaximum: true}),
+			query("ground_speed", true, "地速 (米/秒)", nonNegative()),
+			query("vertical_speed", false, "垂直速度 (米/秒, 向上为正)", &Schema{Type: "number", Default: 0}),
+			query("look_ahead", false, "预测时长 (秒)", &Schema{Type: "number", Minimum: floatPtr(0), ExclusiveMinimum: true, Maximum: floatPtr(opts.MaxLookAhead), Default: opts.DefaultLookAhead}),
+			distance(),
+		}, footprint, []*Parameter{asOf}),
+	})
+	doc.add("GET", "/api/v1/collision_zones", model.RoleViewer, &Operation{
+		OperationID: "collisionZones",
+		Summary:     "按分级告警区域检测建筑物",
+		Tags:        []string{"collision"},
+		Parameters: concat(position, []*Parameter{
+			query("zones", false, "告警区域, 如 caution:30:20,warning:15:10,critical:5:2; 为空时使用全局配置", &Schema{Type: "string"}),
+		}, footprint, []*Parameter{asOf}),
+	})
+	doc.add("GET", "/api/v1/line_of_sight", model.RoleViewer, &Operation{
+		OperationID: "lineOfSight",
+		Summary:     "检测两点之间的通视情况",
+		Tags:        []string{"collision"},
+		Parameters: []*Parameter{
+			query("from_longitude", true, "起点经度", longitude()),
+			query("from_latitude", true, "起点纬度", latitude()),
+			query("from_height", true, "起点高度 (米)", height()),
+			query("to_longitude", true, "终点经度", longitude()),
+			query("to_latitude", true, "终点纬度", latitude()),
+			query("to_height", true, "终点高度 (米)", height()),
+			mode,
+			asOf,
+		},
+	})
+	doc.add("POST", "/api/v1/insert_buildings_info", model.RoleAdmin, &Operation{
+		OperationID: "insertBuildingsInfo",
+		Summary:     "从服务器上的 WKT,height 文件导入建筑物",
+		Description: "每行的轮廓在写入前解析并校验: 环闭合、至少4个点、坐标范围、自相交, 最后由 PostGIS ST_IsValid 确认。" +
+			"未通过的行按 invalid_geometry 处理, 原因列在结果的 issues 与 reasons 中; 外环统一为逆时针, 内环为顺时针。" +
+			"dry_run=true 时不写入任何表, 响应以附件 import-report.json 返回。",
+		Tags: []string{"buildings"},
+		Parameters: []*Parameter{
+			query("file_path", true, "服务器本地文件路径", &Schema{Type: "string", MinLength: intPtr(1)}),
+			query("invalid_geometry", false, "几何无效的行: reject 跳过, repair 用 ST_MakeValid 修复, quarantine 存入 hzdk_buildings_quarantine",
+				&Schema{Type: "string", Enum: model.InvalidGeometryPolicies, Default: opts.DefaultInvalidGeometry}),
+			query("dry_run", false, "只校验不写入: 返回可下载的报告, 含将导入/修复/拒绝的行数及原因、与现有 building_id 重复的行、高度分布、范围和按区域统计 (区域取附近已有建筑的 area_code)",
+				&Schema{Type: "boolean", Default: false}),
+		},
+	})
+	doc.Paths["/api/v1/insert_buildings_info"]["post"].Responses["422"] = errorResponse("Import file not found or unreadable")
+	doc.add("POST", "/api/v1/update_buildings_info", model.RoleAdmin, &Operation{
+		OperationID: "updateBuildingsInfo",
+		Summary:     "批量更新建筑物信息",
+		Tags:        []string{"buildings"},
+	})
+	doc.add("GET", "/api/v1/buildings/export", model.RoleOperator, &Operation{
+		OperationID: "exportBuildings",
+		Summary:     "导出建筑物",
+		Description: "以附件形式流式返回符合条件的建筑物: wkt 为导入所用的 WKT,height 行, geojson/ndjson 为 GeoJSON, csv 含全部属性及 WKT 轮廓, " +
+			"shp 为 zip 打包的 Shapefile, gpkg 为 GeoPackage。坐标系均为 EPSG:4326。",
+		Tags: []string{"buildings"},
+		Parameters: []*Parameter{
+			query("format", false, "导出格式", &Schema{Type: "string", Enum: model.ExportFormats, Default: model.ExportGeoJSON}),
+			query("bbox", false, "范围 minLon,minLat,maxLon,maxLat, 返回与之相交的建筑物", &Schema{Type: "string"}),
+			query("area_code", false, "行政区划代码", &Schema{Type: "string"}),
+			query("type", false, "建筑物类型", &Schema{Type: "string"}),
+			query("as_of", false, "导出该时间点的建筑物数据 (RFC 3339); 为空时导出当前数据", &Schema{Type: "string", Format: "date-time"}),
+		},
+	})
+	exportContent := map[string]*MediaType{}
+	for _, format := range model.ExportFormats {
+		exportContent[export.ContentType(format)] = &MediaType{Schema: &Schema{Type: "string", Format: "binary"}}
+	}
+	doc.Paths["/api/v1/buildings/export"]["get"].Responses["200"] = &Response{Description: "导出文件", Content: exportContent}
+	doc.add("POST", "/api/v1/flight_plans", model.RoleOperator, &Operation{
+		OperationID: "submitFlightPlan",
+		Summary:     "提交飞行计划并进行障碍与冲突评估",
+		Tags:        []string{"flight plans"},
+		RequestBody: jsonBody("SubmitFlightPlanRequest"),
+	})
+	doc.add("GET", "/api/v1/flight_plans", model.RoleOperator, &Operation{
+		OperationID: "listFlightPlans",
+		Summary:     "查询飞行计划",
+		Tags:        []string{"flight plans"},
+		Parameters: []*Parameter{
+			query("drone_id", false, "无人机id", &Schema{Type: "string", MaxLength: intPtr(80)}),
+			query("status", false, "审批状态", &Schema{Type: "string", Enum: []string{model.PlanApproved, model.PlanRejected, model.PlanNeedsReview}}),
+		},
+	})
+	doc.add("GET", "/api/v1/flight_plans/{id}", model.RoleOperator, &Operation{
+		OperationID: "getFlightPlan",
+		Summary:     "查询飞行计划及审批历史",
+		Tags:        []string{"flight plans"},
+		Parameters:  []*Parameter{planID},
+	})
+	doc.add("GET", "/api/v1/flight_plans/{id}/conflicts", model.RoleOperator, &Operation{
+		OperationID: "flightPlanConflicts",
+		Summary:     "检测飞行计划与其他已批准计划的时空冲突",
+		Tags:        []string{"flight plans"},
+		Parameters: []*Parameter{planID,
+			query("suggest_shift", false, "是否计算可消除冲突的起飞时间平移", &Schema{Type: "boolean", Default: false}),
+		},
+	})
+	doc.add("POST", "/api/v1/flight_plans/{id}/review", model.RoleAdmin, &Operation{
+		OperationID: "reviewFlightPlan",
+		Summary:     "人工审批飞行计划",
+		Description: "只能审批待审核 (needs-review) 的计划, 审批人不能是计划的提交人; 批准前会重新检测与已批准计划的冲突。",
+		Tags:        []string{"flight plans"},
+		Parameters:  []*Parameter{planID},
+		RequestBody: jsonBody("ReviewFlightPlanRequest"),
+	})
+	doc.add("GET", "/api/v1/telemetry/systems", model.RoleViewer, &Operation{
+		OperationID: "telemetrySystems",
+		Summary:     "查询 MAVLink 遥测正在跟踪的无人机",
+		Description: "仅在启用 telemetry 时可用。",
+		Tags:        []string{"telemetry"},
+	})
+	doc.add("GET", "/api/v1/telemetry/alerts", model.RoleViewer, &Operation{
+		OperationID: "telemetryAlerts",
+		Summary:     "以 Server-Sent Events 推送遥测告警",
+		Description: "仅在启用 telemetry 时可用。每当无人机所在告警区域变化 (type=zone) 或越出/返回飞行计划走廊 (type=geofence) 时推送一个 alert 事件, level 为空表示已离开所有区域。",
+		Tags:        []string{"telemetry"},
+	})
+	doc.Paths["/api/v1/telemetry/alerts"]["get"].Responses["200"] = &Response{
+		Description: "alert 事件流",
+		Content:     map[string]*MediaType{"text/event-stream": {Schema: &Schema{Type: "string"}}},
+	}
+	webhookDoc := "仅在启用 webhooks 时可用。事件以 JSON POST 到订阅地址, 请求头 X-Webhook-Signature: t=<unix 秒>,v1=<hex>, " +
+		"其中 v1 为以订阅密钥对 \"<t>.<请求体>\" 计算的 HMAC-SHA256。失败的投递按指数退避重试。"
+	doc.add("POST", "/api/v1/webhooks", model.RoleAdmin, &Operation{
+		OperationID: "createWebhook",
+		Summary:     "创建 Webhook 订阅",
+		Description: webhookDoc + "\n\n响应中的 secret 仅返回这一次。",
+		Tags:        []string{"webhooks"},
+		RequestBody: jsonBody("CreateWebhookRequest"),
+	})
+	doc.add("GET", "/api/v1/webhooks", model.RoleAdmin, &Operation{
+		OperationID: "listWebhooks",
+		Summary:     "查询 Webhook 订阅",
+		Description: webhookDoc,
+		Tags:        []string{"webhooks"},
+	})
+	doc.add("DELETE", "/api/v1/webhooks/{id}", model.RoleAdmin, &Operation{
+		OperationID: "deleteWebhook",
+		Summary:     "停用 Webhook 订阅",
+		Description: "停用后不再投递, 投递记录保留。",
+		Tags:        []string{"webhooks"},
+		Parameters:  []*Parameter{webhookID},
+	})
+	doc.add("GET", "/api/v1/webhooks/{id}/deliveries", model.RoleAdmin, &Operation{
+		OperationID: "listWebhookDeliveries",
+		Summary:     "查询 Webhook 订阅最近的投递记录",
+		Tags:        []string{"webhooks"},
+		Parameters: []*Parameter{webhookID,
+			query("status", false, "投递状态", &Schema{Type: "string", Enum: []string{model.DeliveryPending, model.DeliveryDelivered, model.DeliveryFailed}}),
+		},
+	})
+	return doc
+}
+
+// add registers an operation with the standard responses for its role.
+func (d *Document) add(method, path, role string, op *Operation) {
+	op.Description = strings.TrimSpace(op.Description + "\n\nRequires role: " + role)
+	op.Responses = map[string]*Response{
+		"200": {Description: "OK", Content: map[string]*MediaType{"application/json": {Schema: ref("Result")}}},
+		"400": errorResponse("Invalid request parameters"),
+		"401": errorResponse("Missing or invalid credentials"),
+		"403": errorResponse("Role " + role + " required"),
+		"429": errorResponse("Rate limit or daily quota exceeded; see Retry-After"),
+		"500": errorResponse("Internal error"),
+		"504": errorResponse("Request timed out"),
+	}
+	switch {
+	case strings.HasPrefix(path, "/api/v1/flight_plans/{id}"):
+		op.Responses["404"] = errorResponse("Flight plan not found")
+	case strings.HasPrefix(path, "/api/v1/webhooks/{id}"):
+		op.Responses["404"] = errorResponse("Webhook subscription not found")
+	}
+	if op.RequestBody != nil {
+		op.Responses["413"] = errorResponse("Request body too large")
+	}
+	if d.Paths[path] == nil {
+		d.Paths[path] = map[string]*Operation{}
+	}
+	d.Paths[path][strings.ToLower(method)] = op
+}
+
+func errorResponse(description string) *Response {
+	return &Response{Description: description, Content: map[string]*MediaType{"application/json": {Schema: ref("Error")}}}
+}
+
+func jsonBody(schema string) *RequestBody {
+	return &RequestBody{Required: true, Content: map[string]*MediaType{"application/json": {Schema: ref(schema)}}}
+}
+
+func query(name string, required bool, description string, schema *Schema) *Parameter {
+	return &Parameter{Name: name, In: "query", Required: required, Description: description, Schema: schema}
+}
+
+func ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func longitude() *Schema {
+	return &Schema{Type: "number", Minimum: floatPtr(-180), Maximum: floatPtr(180)}
+}
+
+func latitude() *Schema {
+	return &Schema{Type: "number", Minimum: floatPtr(-90), Maximum: floatPtr(90)}
+}
+
+func height() *Schema {
+	return &Schema{Type: "number", Minimum: floatPtr(0)}
+}
+
+func nonNegative() *Schema {
+	return &Schema{Type: "number", Minimum: floatPtr(0), Default: 0}
+}
+
+func concat(groups ...[]*Parameter) []*Parameter {
+	var out []*Parameter
+	for _, g := range groups {
+		out = append(out, g...)
+	}
+	return out
+}
+
+func floatPtr(f float64) *float64 { return &f }
+
+func intPtr(n int) *int { return &n }
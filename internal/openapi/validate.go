@@ -0,0 +1,269 @@
+// internal/openapi/validate.go
+package openapi
+
+import (
+	"bytes"
+	"collision_app_go/internal/apperr"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// ValidationError describes one parameter or body field that does not match the schema.
+// Rule and Args identify the violated rule for localization (see apperr.Rule); Message
+// is its English rendering.
+type ValidationError struct {
+	In      string `json:"in"`   // query, path or body
+	Name    string `json:"name"` // parameter name or JSON path of the body field
+	Rule    string `json:"rule"`
+	Args    []any  `json:"-"`
+	Message string `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("Invalid %s: %s", e.Name, e.Message)
+}
+
+// violation is a failed rule; the zero value means the value is valid.
+type violation struct {
+	rule string
+	args []any
+}
+
+func violated(rule string, args ...any) violation {
+	return violation{rule: rule, args: args}
+}
+
+func (v violation) at(in, name string) ValidationError {
+	return ValidationError{In: in, Name: name, Rule: v.rule, Args: v.args, Message: apperr.Rule(apperr.LangEN, v.rule, v.args...)}
+}
+
+// ValidateParameters checks query and path parameters against op. pathParams maps
+// path parameter names to their raw values.
+func (d *Document) ValidateParameters(op *Operation, query url.Values, pathParams map[string]string) []ValidationError {
+	var errs []ValidationError
+	for _, p := range op.Parameters {
+		var raw string
+		var present bool
+		switch p.In {
+		case "query":
+			present = query.Has(p.Name) && query.Get(p.Name) != ""
+			raw = query.Get(p.Name)
+		case "path":
+			raw, present = pathParams[p.Name]
+		}
+		if !present {
+			if p.Required {
+				errs = append(errs, violated("required").at(p.In, p.Name))
+			}
+			continue
+		}
+		if v := d.checkRaw(d.resolve(p.Schema), raw); v.rule != "" {
+			errs = append(errs, v.at(p.In, p.Name))
+		}
+	}
+	return errs
+}
+
+// ValidateBody checks a JSON request body against op's application/json schema.
+func (d *Document) ValidateBody(op *Operation, body []byte) []ValidationError {
+	if op.RequestBody == nil {
+		return nil
+	}
+	media := op.RequestBody.Content["application/json"]
+	if media == nil {
+		return nil
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		if op.RequestBody.Required {
+			return []ValidationError{violated("required").at("body", "body")}
+		}
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return []ValidationError{violated("json", err.Error()).at("body", "body")}
+	}
+	var errs []ValidationError
+	d.checkValue(media.Schema, v, "body", &errs)
+	return errs
+}
+
+// checkRaw validates a parameter's string form and returns the violated rule, if any.
+func (d *Document) checkRaw(s *Schema, raw string) violation {
+	if s == nil {
+		return violation{}
+	}
+	switch s.Type {
+	case "number", "integer":
+		var f float64
+		if s.Type == "integer" {
+			n, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				return violated("integer")
+			}
+			f = float64(n)
+		} else {
+			var err error
+			f, err = strconv.ParseFloat(raw, 64)
+			if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+				return violated("number")
+			}
+		}
+		return checkRange(s, f)
+	case "boolean":
+		if _, err := strconv.ParseBool(raw); err != nil {
+			return violated("boolean")
+		}
+	case "string":
+		return checkString(s, raw)
+	}
+	return violation{}
+}
+
+// checkValue validates a decoded JSON value, appending errors under path.
+func (d *Document) checkValue(s *Schema, v any, path string, errs *[]ValidationError) {
+	s = d.resolve(s)
+	if s == nil {
+		return
+	}
+	fail := func(v violation) {
+		*errs = append(*errs, v.at("body", path))
+	}
+
+	switch s.Type {
+	case "object":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			fail(violated("object"))
+			return
+		}
+		for _, name := range s.Required {
+			if val, ok := obj[name]; !ok || val == nil {
+				*errs = append(*errs, violated("required").at("body", joinPath(path, name)))
+			}
+		}
+		names := make([]string, 0, len(obj))
+		for name := range obj {
+			names = append(names, name)
+		}
+		sort.Strings(names) // stable error order
+		for _, name := range names {
+			prop, ok := s.Properties[name]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					*errs = append(*errs, violated("unknown_field").at("body", joinPath(path, name)))
+				}
+				continue
+			}
+			if obj[name] != nil {
+				d.checkValue(prop, obj[name], joinPath(path, name), errs)
+			}
+		}
+	case "array":
+		arr, ok := v.([]any)
+		if !ok {
+			fail(violated("array"))
+			return
+		}
+		if s.MinItems != nil && len(arr) < *s.MinItems {
+			fail(violated("min_items", *s.MinItems))
+		}
+		if s.MaxItems != nil && len(arr) > *s.MaxItems {
+			fail(violated("max_items", *s.MaxItems))
+		}
+		for i, item := range arr {
+			d.checkValue(s.Items, item, fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	case "number", "integer":
+		n, ok := v.(json.Number)
+		if !ok {
+			fail(violated("number"))
+			return
+		}
+		f, err := n.Float64()
+		if err != nil {
+			fail(violated("number"))
+			return
+		}
+		if s.Type == "integer" && f != math.Trunc(f) {
+			fail(violated("integer"))
+			return
+		}
+		if r := checkRange(s, f); r.rule != "" {
+			fail(r)
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			fail(violated("boolean"))
+		}
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			fail(violated("string"))
+			return
+		}
+		if r := checkString(s, str); r.rule != "" {
+			fail(r)
+		}
+	}
+}
+
+func checkRange(s *Schema, f float64) violation {
+	if s.Minimum != nil {
+		if s.ExclusiveMinimum && f <= *s.Minimum {
+			return violated("exclusive_min", formatNumber(*s.Minimum))
+		}
+		if f < *s.Minimum {
+			return violated("min", formatNumber(*s.Minimum))
+		}
+	}
+	if s.Maximum != nil {
+		if s.ExclusiveMaximum && f >= *s.Maximum {
+			return violated("exclusive_max", formatNumber(*s.Maximum))
+		}
+		if f > *s.Maximum {
+			return violated("max", formatNumber(*s.Maximum))
+		}
+	}
+	return violation{}
+}
+
+func checkString(s *Schema, str string) violation {
+	n := utf8.RuneCountInString(str)
+	if s.MinLength != nil && n < *s.MinLength {
+		return violated("min_length", *s.MinLength)
+	}
+	if s.MaxLength != nil && n > *s.MaxLength {
+		return violated("max_length", *s.MaxLength)
+	}
+	if len(s.Enum) > 0 && !slices.Contains(s.Enum, str) {
+		return violated("enum", s.Enum)
+	}
+	if s.Format == "date-time" {
+		if _, err := time.Parse(time.RFC3339, str); err != nil {
+			return violated("date_time")
+		}
+	}
+	return violation{}
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func joinPath(path, name string) string {
+	if path == "body" {
+		return name
+	}
+	return path + "." + name
+}
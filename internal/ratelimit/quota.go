@@ -0,0 +1,138 @@
+// internal/ratelimit/quota.go
+package ratelimit
+
+import (
+	"collision_app_go/utils"
+	"context"
+	"sync"
+	"time"
+)
+
+// QuotaStore persists daily usage so that counters survive restarts and are shared
+// between instances. day is formatted as 2006-01-02 (UTC).
+type QuotaStore interface {
+	LoadQuotaUsage(ctx context.Context, day string) (map[string]int64, error)
+	AddQuotaUsage(ctx context.Context, day string, deltas map[string]int64) error
+}
+
+// Quota counts requests per client per UTC day against a daily limit.
+// Counts are kept in memory and flushed to the store periodically, so instances
+// sharing a store may briefly overshoot the limit by their unflushed requests.
+type Quota struct {
+	limit int64
+
+	mu      sync.Mutex
+	day     string
+	counts  map[string]int64 // total usage known to this instance
+	pending map[string]int64 // usage not yet flushed to the store
+	now     func() time.Time
+}
+
+// NewQuota returns a quota of limit requests per client per day.
+func NewQuota(limit int64) *Quota {
+	q := &Quota{limit: limit, now: time.Now}
+	q.reset(q.today())
+	return q
+}
+
+func (q *Quota) today() string {
+	return q.now().UTC().Format(time.DateOnly)
+}
+
+func (q *Quota) reset(day string) {
+	q.day = day
+	q.counts = map[string]int64{}
+	q.pending = map[string]int64{}
+}
+
+// Limit returns the daily limit.
+func (q *Quota) Limit() int64 {
+	return q.limit
+}
+
+// Take counts one request for key. When the quota is exhausted it returns false and
+// the time until the quota resets at the next UTC midnight.
+func (q *Quota) Take(key string) (ok bool, remaining int64, retryAfter time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now().UTC()
+	if day := now.Format(time.DateOnly); day != q.day {
+		q.reset(day)
+	}
+	if q.counts[key] >= q.limit {
+		midnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+		return false, 0, midnight.Sub(now)
+	}
+	q.counts[key]++
+	q.pending[key]++
+	return true, q.limit - q.counts[key], 0
+}
+
+// Load merges today's persisted usage into the in-memory counters.
+func (q *Quota) Load(ctx context.Context, store QuotaStore) error {
+	day := q.today()
+	usage, err := store.LoadQuotaUsage(ctx, day)
+	if err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if day != q.day {
+		q.reset(day)
+	}
+	for key, n := range usage {
+		q.counts[key] = n + q.pending[key]
+	}
+	return nil
+}
+
+// Flush writes unflushed usage to the store. On failure the usage is kept for the next flush.
+func (q *Quota) Flush(ctx context.Context, store QuotaStore) error {
+	q.mu.Lock()
+	day, pending := q.day, q.pending
+	q.pending = map[string]int64{}
+	q.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+	if err := store.AddQuotaUsage(ctx, day, pending); err != nil {
+		q.mu.Lock()
+		if q.day == day {
+			for key, n := range pending {
+				q.pending[key] += n
+			}
+		}
+		q.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Run flushes usage and reloads the shared counters every interval until ctx is done,
+// then flushes one last time.
+func (q *Quota) Run(ctx context.Context, store QuotaStore, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := q.Flush(flushCtx, store); err != nil {
+				utils.Error(flushCtx, "failed to flush quota usage", "error", err)
+			}
+			cancel()
+			return
+		case <-ticker.C:
+			if err := q.Flush(ctx, store); err != nil {
+				utils.Warn(ctx, "failed to flush quota usage", "error", err)
+				continue
+			}
+			if err := q.Load(ctx, store); err != nil {
+				utils.Warn(ctx, "failed to reload quota usage", "error", err)
+			}
+		}
+	}
+}
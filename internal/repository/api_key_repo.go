@@ -0,0 +1,107 @@
+// internal/repository/api_key_repo.go
+package repository
+
+import (
+	"collision_app_go/internal/metrics"
+	"collision_app_go/internal/model"
+	"collision_app_go/utils"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// lastUsedResolution limits how often last_used_time is written for a busy key.
+const lastUsedResolution = time.Minute
+
+const apiKeyColumns = `id, name, role, revoked, create_time, last_used_time`
+
+type APIKeyRepository struct {
+	dbpool *pgxpool.Pool
+}
+
+func NewAPIKeyRepository(dbpool *pgxpool.Pool) *APIKeyRepository {
+	return &APIKeyRepository{dbpool: dbpool}
+}
+
+// CreateAPIKey stores a new key by its hash.
+func (r *APIKeyRepository) CreateAPIKey(ctx context.Context, name, role, keyHash string) (*model.APIKey, error) {
+	query := `
+        INSERT INTO api_keys (name, role, key_hash)
+        VALUES ($1, $2, $3)
+        RETURNING ` + apiKeyColumns
+	key, err := scanAPIKey(r.dbpool.QueryRow(ctx, query, name, role, keyHash))
+	if err != nil {
+		utils.Error(ctx, "failed to insert api key", "error", err)
+		return nil, fmt.Errorf("failed to insert api key: %w", err)
+	}
+	return key, nil
+}
+
+// GetAPIKeyByHash returns the non-revoked key with the given hash, or ErrNotFound.
+// It also refreshes last_used_time when it is older than lastUsedResolution.
+func (r *APIKeyRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*model.APIKey, error) {
+	defer metrics.ObserveQuery("get_api_key", time.Now())
+
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = $1 AND NOT revoked`
+	key, err := scanAPIKey(r.dbpool.QueryRow(ctx, query, keyHash))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		utils.Error(ctx, "database query failed", "error", err)
+		return nil, fmt.Errorf("failed to query api key: %w", err)
+	}
+
+	if key.LastUsedTime == nil || time.Since(*key.LastUsedTime) > lastUsedResolution {
+		if _, err := r.dbpool.Exec(ctx, `UPDATE api_keys SET last_used_time = CURRENT_TIMESTAMP WHERE id = $1`, key.ID); err != nil {
+			// Not fatal: the key is still valid
+			utils.Warn(ctx, "failed to update api key last_used_time", "key_id", key.ID, "error", err)
+		}
+	}
+	return key, nil
+}
+
+// ListAPIKeys returns every key, newest first.
+func (r *APIKeyRepository) ListAPIKeys(ctx context.Context) ([]model.APIKey, error) {
+	rows, err := r.dbpool.Query(ctx, `SELECT `+apiKeyColumns+` FROM api_keys ORDER BY id DESC`)
+	if err != nil {
+		utils.Error(ctx, "database query failed", "error", err)
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []model.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan api key: %w", err)
+		}
+		keys = append(keys, *key)
+	}
+	return keys, rows.Err()
+}
+
+// RevokeAPIKey marks a key as revoked. It returns ErrNotFound for an unknown ID.
+func (r *APIKeyRepository) RevokeAPIKey(ctx context.Context, id int64) error {
+	tag, err := r.dbpool.Exec(ctx, `UPDATE api_keys SET revoked = true WHERE id = $1`, id)
+	if err != nil {
+		utils.Error(ctx, "failed to revoke api key", "error", err)
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func scanAPIKey(row pgx.Row) (*model.APIKey, error) {
+	var key model.APIKey
+	if err := row.Scan(&key.ID, &key.Name, &key.Role, &key.Revoked, &key.CreateTime, &key.LastUsedTime); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
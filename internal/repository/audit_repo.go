@@ -0,0 +1,209 @@
+// internal/repository/audit_repo.go
+package repository
+
+import (
+	"collision_app_go/internal/metrics"
+	"collision_app_go/internal/model"
+	"collision_app_go/utils"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// auditPartitionPrefix names the monthly partitions of collision_audit, followed by
+// the month as YYYYMM.
+const auditPartitionPrefix = "collision_audit_p"
+
+// AuditFilter selects recorded checks. Zero values match every entry.
+type AuditFilter struct {
+	From   time.Time
+	To     time.Time
+	Check  string
+	Source string
+	Limit  int
+}
+
+type AuditRepository struct {
+	dbpool *pgxpool.Pool
+}
+
+func NewAuditRepository(dbpool *pgxpool.Pool) *AuditRepository {
+	return &AuditRepository{dbpool: dbpool}
+}
+
+// InsertAuditEntries writes a batch of entries with COPY.
+func (r *AuditRepository) InsertAuditEntries(ctx context.Context, entries []model.AuditEntry) error {
+	defer metrics.ObserveQuery("insert_audit_entries", time.Now())
+
+	columns := []string{
+		"create_time", "request_id", "source", "caller", "auth_method", "client_addr", "check_kind",
+		"input", "is_collision", "level", "building_ids", "error_code", "latency_ms", "result",
+	}
+	rows := make([][]any, len(entries))
+	for i, e := range entries {
+		input, err := json.Marshal(e.Input)
+		if err != nil {
+			return fmt.Errorf("failed to encode audit input: %w", err)
+		}
+		var result any
+		if len(e.Result) > 0 {
+			result = string(e.Result)
+		}
+		rows[i] = []any{
+			e.Time, e.RequestID, e.Source, e.Caller, e.AuthMethod, e.ClientAddr, e.Check,
+			string(input), e.IsCollision, e.Level, e.BuildingIDs, e.ErrorCode, e.LatencyMs, result,
+		}
+	}
+
+	_, err := r.dbpool.CopyFrom(ctx, pgx.Identifier{"collision_audit"}, columns, pgx.CopyFromRows(rows))
+	if err != nil {
+		utils.Error(ctx, "failed to insert audit entries", "count", len(entries), "error", err)
+		return fmt.Errorf("failed to insert audit entries: %w", err)
+	}
+	return nil
+}
+
+// EnsureAuditPartitions creates the monthly partitions from the month of from
+// through the following months. Existing partitions are left alone. Entries of a
+// new partition's month that landed in the default partition are moved into it,
+// since PostgreSQL refuses to create the partition otherwise.
+func (r *AuditRepository) EnsureAuditPartitions(ctx context.Context, from time.Time, months int) error {
+	start := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i <= months; i++ {
+		lower, upper := start.AddDate(0, i, 0), start.AddDate(0, i+1, 0)
+		name := auditPartitionPrefix + lower.Format("200601")
+		if err := r.createAuditPartition(ctx, name, lower, upper); err != nil {
+			utils.Error(ctx, "failed to create audit partition", "partition", name, "error", err)
+			return fmt.Errorf("failed to create audit partition %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (r *AuditRepository) createAuditPartition(ctx context.Context, name string, lower, upper time.Time) error {
+	var exists bool
+	if err := r.dbpool.QueryRow(ctx, `SELECT to_regclass($1) IS NOT NULL`, name).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	tx, err := r.dbpool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Take the month's entries out of the default partition, create the partition
+	// and put them back, keeping their IDs
+	if _, err := tx.Exec(ctx, `CREATE TEMPORARY TABLE collision_audit_moved (LIKE collision_audit_default) ON COMMIT DROP`); err != nil {
+		return err
+	}
+	tag, err := tx.Exec(ctx, `
+        WITH moved AS (
+            DELETE FROM collision_audit_default WHERE create_time >= $1 AND create_time < $2 RETURNING *
+        )
+        INSERT INTO collision_audit_moved SELECT * FROM moved
+    `, lower, upper)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s PARTITION OF collision_audit FOR VALUES FROM ('%s') TO ('%s')`,
+		pgx.Identifier{name}.Sanitize(), lower.Format(time.RFC3339), upper.Format(time.RFC3339))
+	if _, err := tx.Exec(ctx, query); err != nil {
+		return err
+	}
+	if moved := tag.RowsAffected(); moved > 0 {
+		if _, err := tx.Exec(ctx, `INSERT INTO collision_audit OVERRIDING SYSTEM VALUE SELECT * FROM collision_audit_moved`); err != nil {
+			return err
+		}
+		utils.Warn(ctx, "moved audit entries from the default partition", "partition", name, "entries", moved)
+	}
+	return tx.Commit(ctx)
+}
+
+// DropAuditPartitionsBefore drops the monthly partitions that end on or before
+// cutoff and returns their names.
+func (r *AuditRepository) DropAuditPartitionsBefore(ctx context.Context, cutoff time.Time) ([]string, error) {
+	query := `
+        SELECT c.relname
+        FROM pg_inherits i
+        JOIN pg_class c ON c.oid = i.inhrelid
+        WHERE i.inhparent = 'collision_audit'::regclass AND c.relname LIKE $1
+        ORDER BY c.relname
+    `
+	rows, err := r.dbpool.Query(ctx, query, auditPartitionPrefix+"%")
+	if err != nil {
+		utils.Error(ctx, "failed to list audit partitions", "error", err)
+		return nil, fmt.Errorf("failed to list audit partitions: %w", err)
+	}
+	names, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit partitions: %w", err)
+	}
+
+	var dropped []string
+	for _, name := range names {
+		month, err := time.Parse("200601", name[len(auditPartitionPrefix):])
+		if err != nil || month.AddDate(0, 1, 0).After(cutoff) {
+			continue
+		}
+		if _, err := r.dbpool.Exec(ctx, `DROP TABLE IF EXISTS `+pgx.Identifier{name}.Sanitize()); err != nil {
+			utils.Error(ctx, "failed to drop audit partition", "partition", name, "error", err)
+			return dropped, fmt.Errorf("failed to drop audit partition %s: %w", name, err)
+		}
+		dropped = append(dropped, name)
+	}
+	return dropped, nil
+}
+
+// ScanAuditEntries calls fn for each entry matching the filter, oldest first,
+// without loading them all into memory.
+func (r *AuditRepository) ScanAuditEntries(ctx context.Context, filter AuditFilter, fn func(model.AuditEntry) error) error {
+	query := `
+        SELECT id, create_time, request_id, source, caller, auth_method, client_addr, check_kind,
+            input, is_collision, level, building_ids, error_code, latency_ms, result
+        FROM collision_audit
+        WHERE ($1::timestamptz IS NULL OR create_time >= $1)
+            AND ($2::timestamptz IS NULL OR create_time < $2)
+            AND ($3 = '' OR check_kind = $3)
+            AND ($4 = '' OR source = $4)
+        ORDER BY create_time, id
+        LIMIT NULLIF($5, 0)
+    `
+	var from, to *time.Time
+	if !filter.From.IsZero() {
+		from = &filter.From
+	}
+	if !filter.To.IsZero() {
+		to = &filter.To
+	}
+	rows, err := r.dbpool.Query(ctx, query, from, to, filter.Check, filter.Source, filter.Limit)
+	if err != nil {
+		utils.Error(ctx, "database query failed", "error", err)
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e model.AuditEntry
+		var input []byte
+		err := rows.Scan(&e.ID, &e.Time, &e.RequestID, &e.Source, &e.Caller, &e.AuthMethod, &e.ClientAddr, &e.Check,
+			&input, &e.IsCollision, &e.Level, &e.BuildingIDs, &e.ErrorCode, &e.LatencyMs, &e.Result)
+		if err != nil {
+			return fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		if err := json.Unmarshal(input, &e.Input); err != nil {
+			return fmt.Errorf("audit entry %d: invalid input: %w", e.ID, err)
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -3,46 +3,78 @@ package repository
 
 import (
 	"bufio"
+	"collision_app_go/internal/geom"
+	"collision_app_go/internal/metrics"
 	"collision_app_go/internal/model"
 	"collision_app_go/utils"
 	"context"
+	"errors"
 	"fmt"
 	"hash/fnv"
+	"math"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// ErrUnreadableFile is returned when an import file cannot be opened or read.
+var ErrUnreadableFile = errors.New("import file cannot be read")
+
+// ProgressFunc reports how many of total items have been processed.
+type ProgressFunc func(done, total int)
+
 type BuildingRepository struct {
 	dbpool *pgxpool.Pool
+	asOf   *time.Time // read the buildings as they were at this time, nil for the live tables
 }
 
 func NewBuildingRepository(dbpool *pgxpool.Pool) *BuildingRepository {
 	return &BuildingRepository{dbpool: dbpool}
 }
 
+// AsOf returns a repository whose collision and export queries read the buildings as
+// they were at t, reconstructed from hzdk_buildings_history. Imports and updates
+// always write the live table.
+func (r *BuildingRepository) AsOf(t time.Time) *BuildingRepository {
+	return &BuildingRepository{dbpool: r.dbpool, asOf: &t}
+}
+
+// relations returns the relations to read buildings and solids from: the live
+// table and view, or their snapshots at the as-of time, which is appended to args.
+func (r *BuildingRepository) relations(args []any) (buildings, solids string, _ []any) {
+	if r.asOf == nil {
+		return "hzdk_buildings", "hzdk_building_solids", args
+	}
+	args = append(args, *r.asOf)
+	return fmt.Sprintf("hzdk_buildings_as_of($%d)", len(args)), fmt.Sprintf("hzdk_building_solids_as_of($%d)", len(args)), args
+}
+
 // GetCollisionBuildingsInfo finds buildings colliding with a point.
 func (r *BuildingRepository) GetCollisionBuildingsInfo(ctx context.Context, longitude, latitude, height, collisionDistance float64) ([]model.Building, error) {
-	query := `
+	defer metrics.ObserveQuery("collision_buildings", time.Now())
+
+	table, _, args := r.relations([]any{longitude, latitude, collisionDistance, height})
+	query := fmt.Sprintf(`
         SELECT 
             building_id, building_name, ST_AsText(geom) AS geom, building_height
         FROM 
-            hzdk_buildings
+            %s
         WHERE 
             ST_DWithin(
                 geom::geography, 
                 ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography,
                 $3)
             AND $4 < building_height
-    `
+    `, table)
 
-	utils.Debug("Executing SQL: %s with args: lon=%f, lat=%f, dist=%f, height=%f", query, longitude, latitude, collisionDistance, height)
+	utils.Debug(ctx, "executing collision query", "sql", query, "lon", longitude, "lat", latitude, "dist", collisionDistance, "height", height, "as_of", r.asOf)
 
-	rows, err := r.dbpool.Query(ctx, query, longitude, latitude, collisionDistance, height)
+	rows, err := r.dbpool.Query(ctx, query, args...)
 	if err != nil {
-		utils.Errorf("Database query failed: %v", err)
+		utils.Error(ctx, "database query failed", "error", err)
 		return nil, fmt.Errorf("failed to execute query: %w", err)
 	}
 	defer rows.Close()
@@ -52,59 +84,364 @@ func (r *BuildingRepository) GetCollisionBuildingsInfo(ctx context.Context, long
 		var b model.Building
 		err := rows.Scan(&b.BuildingID, &b.BuildingName, &b.Geom, &b.BuildingHeight)
 		if err != nil {
-			utils.Errorf("Failed to scan row: %v", err)
+			utils.Error(ctx, "failed to scan row", "error", err)
 			return nil, fmt.Errorf("failed to scan row: %w", err)
 		}
 		buildings = append(buildings, b)
 	}
 
 	if err = rows.Err(); err != nil {
-		utils.Errorf("Row iteration error: %v", err)
+		utils.Error(ctx, "row iteration error", "error", err)
 		return nil, fmt.Errorf("row iteration error: %w", err)
 	}
 
 	return buildings, nil
 }
 
-// Placeholder for insert logic - you need to implement file reading/parsing
-func (r *BuildingRepository) InsertBuildingsFromFile(ctx context.Context, filePath string) (map[string]interface{}, error) {
-	utils.Infof("Inserting buildings from file: %s", filePath)
+// GetCollisionSolids finds building parts whose extruded solid lies within
+// collisionDistance meters (3D distance) of a point. The point is treated as a
+// vertical interval [minHeight, maxHeight] to account for vertical uncertainty.
+func (r *BuildingRepository) GetCollisionSolids(ctx context.Context, longitude, latitude, minHeight, maxHeight, collisionDistance float64) ([]model.SolidHit, error) {
+	defer metrics.ObserveQuery("collision_solids", time.Now())
+
+	table, solids, args := r.relations([]any{longitude, latitude, minHeight, maxHeight, collisionDistance})
+	query := fmt.Sprintf(`
+        WITH p AS (
+            SELECT ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography AS geog
+        ),
+        candidates AS (
+            SELECT
+                s.building_id, s.part_id, s.base_height::float8 AS base_height, s.top_height::float8 AS top_height,
+                ST_Distance(s.geom::geography, p.geog) AS dh,
+                GREATEST(0, s.base_height - $4, $3 - s.top_height)::float8 AS dv
+            FROM
+                %[2]s s, p
+            WHERE
+                ST_DWithin(s.geom::geography, p.geog, $5)
+        )
+        SELECT
+            b.building_id, b.building_name, ST_AsText(b.geom) AS geom, b.building_height,
+            c.part_id, c.base_height, c.top_height, sqrt(c.dh * c.dh + c.dv * c.dv) AS distance
+        FROM
+            candidates c
+            JOIN %[1]s b ON b.building_id = c.building_id
+        WHERE
+            sqrt(c.dh * c.dh + c.dv * c.dv) <= $5
+        ORDER BY distance
+    `, table, solids)
+
+	utils.Debug(ctx, "executing solid query", "lon", longitude, "lat", latitude, "min_height", minHeight, "max_height", maxHeight, "dist", collisionDistance, "as_of", r.asOf)
+
+	rows, err := r.dbpool.Query(ctx, query, args...)
+	if err != nil {
+		utils.Error(ctx, "database query failed", "error", err)
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
 
-	// Open the file
-	file, err := os.Open(filePath)
+	var hits []model.SolidHit
+	for rows.Next() {
+		var h model.SolidHit
+		err := rows.Scan(&h.Building.BuildingID, &h.Building.BuildingName, &h.Building.Geom, &h.Building.BuildingHeight,
+			&h.PartID, &h.BaseHeight, &h.TopHeight, &h.Distance)
+		if err != nil {
+			utils.Error(ctx, "failed to scan row", "error", err)
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		hits = append(hits, h)
+	}
+
+	if err = rows.Err(); err != nil {
+		utils.Error(ctx, "row iteration error", "error", err)
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return hits, nil
+}
+
+// GetBuildingsWithinDistance finds buildings within maxDistance meters of a point whose
+// roof is above minHeight, together with their horizontal distance to the point.
+func (r *BuildingRepository) GetBuildingsWithinDistance(ctx context.Context, longitude, latitude, minHeight, maxDistance float64) ([]model.BuildingDistance, error) {
+	defer metrics.ObserveQuery("buildings_within_distance", time.Now())
+
+	table, _, args := r.relations([]any{longitude, latitude, maxDistance, minHeight})
+	query := fmt.Sprintf(`
+        WITH p AS (
+            SELECT ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography AS geog
+        )
+        SELECT
+            building_id, building_name, ST_AsText(geom) AS geom, building_height,
+            ST_Distance(geom::geography, p.geog) AS distance
+        FROM
+            %s, p
+        WHERE
+            ST_DWithin(geom::geography, p.geog, $3)
+            AND $4 < building_height
+        ORDER BY distance
+    `, table)
+
+	utils.Debug(ctx, "executing zone query", "lon", longitude, "lat", latitude, "dist", maxDistance, "height", minHeight, "as_of", r.asOf)
+
+	rows, err := r.dbpool.Query(ctx, query, args...)
 	if err != nil {
-		utils.Errorf("Failed to open file: %v", err)
-		return map[string]interface{}{
-			"success":  false,
-			"code":     500,
-			"errorMsg": "File not found",
-		}, nil
+		utils.Error(ctx, "database query failed", "error", err)
+		return nil, fmt.Errorf("failed to execute query: %w", err)
 	}
-	defer file.Close()
+	defer rows.Close()
 
-	// Read all lines
-	scanner := bufio.NewScanner(file)
-	var lines []string
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
+	var buildings []model.BuildingDistance
+	for rows.Next() {
+		var b model.BuildingDistance
+		err := rows.Scan(&b.BuildingID, &b.BuildingName, &b.Geom, &b.BuildingHeight, &b.Distance)
+		if err != nil {
+			utils.Error(ctx, "failed to scan row", "error", err)
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		buildings = append(buildings, b)
 	}
 
-	if err := scanner.Err(); err != nil {
-		utils.Errorf("Error reading file: %v", err)
-		return map[string]interface{}{
-			"success":  false,
-			"code":     500,
-			"errorMsg": fmt.Sprintf("Error reading file: %v", err),
-		}, nil
+	if err = rows.Err(); err != nil {
+		utils.Error(ctx, "row iteration error", "error", err)
+		return nil, fmt.Errorf("row iteration error: %w", err)
 	}
 
-	utils.Infof("Read %d lines from file", len(lines))
+	return buildings, nil
+}
 
-	successCount := 0
-	errorCount := 0
+// GetBuildingsAlongRoute finds buildings within buffer meters of a route whose roof
+// is above minHeight, together with their horizontal distance to the route.
+func (r *BuildingRepository) GetBuildingsAlongRoute(ctx context.Context, routeWKT string, minHeight, buffer float64) ([]model.BuildingDistance, error) {
+	defer metrics.ObserveQuery("buildings_along_route", time.Now())
+
+	table, _, args := r.relations([]any{routeWKT, buffer, minHeight})
+	query := fmt.Sprintf(`
+        WITH route AS (
+            SELECT ST_GeomFromText($1, 4326)::geography AS geog
+        )
+        SELECT
+            building_id, building_name, ST_AsText(geom) AS geom, building_height,
+            ST_Distance(geom::geography, route.geog) AS distance
+        FROM
+            %s, route
+        WHERE
+            ST_DWithin(geom::geography, route.geog, $2)
+            AND $3 < building_height
+        ORDER BY building_height DESC
+    `, table)
+
+	utils.Debug(ctx, "executing route query", "route", routeWKT, "buffer", buffer, "height", minHeight, "as_of", r.asOf)
+
+	rows, err := r.dbpool.Query(ctx, query, args...)
+	if err != nil {
+		utils.Error(ctx, "database query failed", "error", err)
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	var buildings []model.BuildingDistance
+	for rows.Next() {
+		var b model.BuildingDistance
+		err := rows.Scan(&b.BuildingID, &b.BuildingName, &b.Geom, &b.BuildingHeight, &b.Distance)
+		if err != nil {
+			utils.Error(ctx, "failed to scan row", "error", err)
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		buildings = append(buildings, b)
+	}
+
+	if err = rows.Err(); err != nil {
+		utils.Error(ctx, "row iteration error", "error", err)
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return buildings, nil
+}
+
+// GetRaySpans finds the parts of the ground track from (lon1, lat1) to (lon2, lat2)
+// that cross building volumes between minHeight and maxHeight. Each span is given as
+// fractions of the track length, ordered by where it starts.
+// In Mode3D the per-part solids from hzdk_building_solids are used; otherwise each
+// footprint is extruded from below ground up to building_height.
+func (r *BuildingRepository) GetRaySpans(ctx context.Context, lon1, lat1, lon2, lat2, minHeight, maxHeight float64, mode string) ([]model.RaySpan, error) {
+	defer metrics.ObserveQuery("ray_spans", time.Now())
+
+	query := `
+        WITH ray AS (
+            SELECT ST_SetSRID(ST_MakeLine(ST_MakePoint($1, $2), ST_MakePoint($3, $4)), 4326) AS g
+        )
+        SELECT
+            b.building_id, b.building_name, ST_AsText(b.geom) AS geom, b.building_height,
+            0 AS part_id, '-Infinity'::float8 AS base, b.building_height::float8 AS top,
+            LEAST(ST_LineLocatePoint(ray.g, ST_StartPoint(d.geom)), ST_LineLocatePoint(ray.g, ST_EndPoint(d.geom))) AS f_from,
+            GREATEST(ST_LineLocatePoint(ray.g, ST_StartPoint(d.geom)), ST_LineLocatePoint(ray.g, ST_EndPoint(d.geom))) AS f_to
+        FROM
+            %[1]s b
+            CROSS JOIN ray
+            CROSS JOIN LATERAL ST_Dump(ST_CollectionExtract(ST_Intersection(b.geom, ray.g), 2)) d
+        WHERE
+            ST_Intersects(b.geom, ray.g)
+            AND $5 < b.building_height
+        ORDER BY f_from
+    `
+	args := []interface{}{lon1, lat1, lon2, lat2, minHeight}
+	if mode == model.Mode3D {
+		query = `
+            WITH ray AS (
+                SELECT ST_SetSRID(ST_MakeLine(ST_MakePoint($1, $2), ST_MakePoint($3, $4)), 4326) AS g
+            )
+            SELECT
+                b.building_id, b.building_name, ST_AsText(b.geom) AS geom, b.building_height,
+                s.part_id, s.base_height::float8 AS base, s.top_height::float8 AS top,
+                LEAST(ST_LineLocatePoint(ray.g, ST_StartPoint(d.geom)), ST_LineLocatePoint(ray.g, ST_EndPoint(d.geom))) AS f_from,
+                GREATEST(ST_LineLocatePoint(ray.g, ST_StartPoint(d.geom)), ST_LineLocatePoint(ray.g, ST_EndPoint(d.geom))) AS f_to
+            FROM
+                %[2]s s
+                JOIN %[1]s b ON b.building_id = s.building_id
+                CROSS JOIN ray
+                CROSS JOIN LATERAL ST_Dump(ST_CollectionExtract(ST_Intersection(s.geom, ray.g), 2)) d
+            WHERE
+                ST_Intersects(s.geom, ray.g)
+                AND $5 < s.top_height
+                AND s.base_height < $6
+            ORDER BY f_from
+        `
+		args = append(args, maxHeight)
+	}
+	table, solids, args := r.relations(args)
+	query = fmt.Sprintf(query, table, solids)
+
+	utils.Debug(ctx, "executing ray query", "mode", mode, "from_lon", lon1, "from_lat", lat1, "to_lon", lon2, "to_lat", lat2, "min_height", minHeight, "max_height", maxHeight, "as_of", r.asOf)
+
+	rows, err := r.dbpool.Query(ctx, query, args...)
+	if err != nil {
+		utils.Error(ctx, "database query failed", "error", err)
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	var spans []model.RaySpan
+	for rows.Next() {
+		var s model.RaySpan
+		err := rows.Scan(&s.BuildingID, &s.BuildingName, &s.Geom, &s.BuildingHeight, &s.PartID, &s.Base, &s.Top, &s.From, &s.To)
+		if err != nil {
+			utils.Error(ctx, "failed to scan row", "error", err)
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		spans = append(spans, s)
+	}
+
+	if err = rows.Err(); err != nil {
+		utils.Error(ctx, "row iteration error", "error", err)
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return spans, nil
+}
+
+// GetTrajectoryCollisions finds buildings within collisionDistance of a projected
+// trajectory whose roof reaches above it less verticalMargin, checked along the legs between consecutive samples. Each building is
+// returned once, at the point of the earliest leg reaching it that is closest to the
+// building, with the time offset and height interpolated there. The result is
+// ordered by that time offset.
+func (r *BuildingRepository) GetTrajectoryCollisions(ctx context.Context, samples []model.TrajectorySample, collisionDistance, verticalMargin float64) ([]model.TrajectoryHit, error) {
+	defer metrics.ObserveQuery("trajectory_collisions", time.Now())
+
+	if len(samples) == 0 {
+		return nil, nil
+	}
+
+	lons := make([]float64, len(samples))
+	lats := make([]float64, len(samples))
+	heights := make([]float64, len(samples))
+	offsets := make([]float64, len(samples))
+	for i, s := range samples {
+		lons[i], lats[i], heights[i], offsets[i] = s.Longitude, s.Latitude, s.Height, s.T
+	}
+
+	table, _, args := r.relations([]any{lons, lats, heights, offsets, collisionDistance, verticalMargin})
+	// Each leg between consecutive samples is checked as a line, so that nothing
+	// between two samples is missed. Height varies linearly along a leg: a leg hits
+	// a building within the distance when its lower end, less the vertical margin,
+	// is below the roof.
+	query := fmt.Sprintf(`
+        WITH samples AS (
+            SELECT * FROM unnest($1::float8[], $2::float8[], $3::float8[], $4::float8[]) WITH ORDINALITY AS s(lon, lat, height, t, i)
+        ),
+        legs AS (
+            SELECT
+                a.t AS t0, b.t AS t1, a.height AS h0, b.height AS h1,
+                ST_MakeLine(ST_SetSRID(ST_MakePoint(a.lon, a.lat), 4326), ST_SetSRID(ST_MakePoint(b.lon, b.lat), 4326)) AS geom
+            FROM samples a JOIN samples b ON b.i = a.i + 1
+        ),
+        hits AS (
+            SELECT DISTINCT ON (b.building_id)
+                b.building_id, b.building_name, ST_AsText(b.geom) AS geom, b.building_height,
+                l.t0, l.t1, l.h0, l.h1,
+                ST_ClosestPoint(l.geom, b.geom) AS at,
+                CASE WHEN ST_Length(l.geom) = 0 THEN 0
+                     ELSE ST_LineLocatePoint(l.geom, ST_ClosestPoint(l.geom, b.geom)) END AS f
+            FROM
+                legs l
+                JOIN %s b ON ST_DWithin(b.geom::geography, l.geom::geography, $5)
+                AND LEAST(l.h0, l.h1) - $6 < b.building_height
+            ORDER BY b.building_id, l.t0
+        )
+        SELECT
+            building_id, building_name, geom, building_height,
+            t0 + f * (t1 - t0) AS t, ST_X(at), ST_Y(at), h0 + f * (h1 - h0)
+        FROM hits
+        ORDER BY t
+    `, table)
+
+	utils.Debug(ctx, "executing trajectory query", "samples", len(samples), "dist", collisionDistance, "vertical_margin", verticalMargin, "as_of", r.asOf)
+
+	rows, err := r.dbpool.Query(ctx, query, args...)
+	if err != nil {
+		utils.Error(ctx, "trajectory query failed", "error", err)
+		return nil, fmt.Errorf("failed to execute trajectory query: %w", err)
+	}
+	defer rows.Close()
+
+	var hits []model.TrajectoryHit
+	for rows.Next() {
+		var h model.TrajectoryHit
+		err := rows.Scan(&h.Building.BuildingID, &h.Building.BuildingName, &h.Building.Geom, &h.Building.BuildingHeight,
+			&h.At.T, &h.At.Longitude, &h.At.Latitude, &h.At.Height)
+		if err != nil {
+			utils.Error(ctx, "failed to scan trajectory row", "error", err)
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		hits = append(hits, h)
+	}
+
+	if err = rows.Err(); err != nil {
+		utils.Error(ctx, "row iteration error", "error", err)
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return hits, nil
+}
+
+// InsertBuildingsFromFile imports buildings from a file of "WKT,height" lines.
+// Footprints are parsed and validated before they reach PostGIS; lines that fail are
+// rejected, repaired or quarantined as opts.InvalidGeometry says, and every issue is
+// listed in the result.
+// progress, if non-nil, is called with the number of lines processed so far and the total.
+func (r *BuildingRepository) InsertBuildingsFromFile(ctx context.Context, filePath string, opts model.ImportOptions, progress ProgressFunc) (*model.ImportResult, error) {
+	utils.Info(ctx, "inserting buildings from file", "file_path", filePath, "invalid_geometry", opts.InvalidGeometry)
+
+	lines, err := readImportFile(ctx, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.ImportResult{TotalCount: len(lines)}
 
 	// Process each line individually
 	for lineNum, line := range lines {
+		if progress != nil {
+			progress(lineNum, len(lines))
+		}
 		originalLineNum := lineNum + 1
 		line = strings.TrimSpace(line)
 		if line == "" {
@@ -112,89 +449,517 @@ func (r *BuildingRepository) InsertBuildingsFromFile(ctx context.Context, filePa
 		}
 
 		// Process single record
-		success, err := r.processSingleRecord(ctx, line, originalLineNum)
-		if err != nil {
-			utils.Infof("Line %d: Processing failed - %v", originalLineNum, err)
-			errorCount++
-			continue
-		}
-
-		if success {
-			successCount++
-			utils.Infof("✓ Line %d: Insert successful", originalLineNum)
+		var res lineResult
+		rec, rejected := parseRecord(line, originalLineNum, opts.InvalidGeometry)
+		if rejected != nil {
+			res = lineResult{issue: rejected}
 		} else {
-			errorCount++
-			utils.Infof("✗ Line %d: Insert failed", originalLineNum)
+			res = r.processSingleRecord(ctx, rec, opts.InvalidGeometry)
+		}
+		if res.quarantinable(opts.InvalidGeometry) {
+			if err := r.quarantine(ctx, filePath, originalLineNum, line, res.issue); err != nil {
+				utils.Error(ctx, "failed to quarantine line", "line", originalLineNum, "error", err)
+			} else {
+				res.issue.Action = model.ImportQuarantined
+			}
 		}
+		if res.imported {
+			utils.Debug(ctx, "line insert successful", "line", originalLineNum)
+		}
+		if res.issue != nil {
+			utils.Debug(ctx, "line "+res.issue.Action, "line", originalLineNum, "reason", res.issue.Reason, "detail", res.issue.Detail)
+		}
+		metrics.ObserveImportLine(res.tally(result))
+	}
+
+	if progress != nil {
+		progress(len(lines), len(lines))
 	}
 
 	// Calculate success rate
-	successRate := 0.0
 	if len(lines) > 0 {
-		successRate = float64(successCount) / float64(len(lines)) * 100
+		result.SuccessRate = float64(result.SuccessCount) / float64(len(lines)) * 100
 	}
 
-	utils.Infof("File data insertion completed!")
-	utils.Infof("Successfully inserted: %d", successCount)
-	utils.Infof("Failed to process: %d", errorCount)
-	utils.Infof("Overall success rate: %.1f%%", successRate)
+	utils.Info(ctx, "file data insertion completed",
+		"success_count", result.SuccessCount, "error_count", result.ErrorCount, "repaired_count", result.RepairedCount,
+		"quarantined_count", result.QuarantinedCount, "success_rate", result.SuccessRate)
 
-	return map[string]interface{}{
-		"success": true,
-		"code":    200,
-		"data": map[string]interface{}{
-			"success_count": successCount,
-			"error_count":   errorCount,
-			"total_count":   len(lines),
-			"success_rate":  successRate,
-		},
-	}, nil
+	return result, nil
 }
 
-func (r *BuildingRepository) processSingleRecord(ctx context.Context, line string, lineNum int) (bool, error) {
-	// Parse the line (format: MULTIPOLYGON(((...))),13.56)
-	parts := strings.Split(line, ",")
-	if len(parts) < 2 {
-		return false, fmt.Errorf("invalid format")
+// readImportFile reads every line of an import file.
+func readImportFile(ctx context.Context, filePath string) ([]string, error) {
+	// Open the file
+	file, err := os.Open(filePath)
+	if err != nil {
+		utils.Error(ctx, "failed to open file", "file_path", filePath, "error", err)
+		return nil, fmt.Errorf("%w: %w", ErrUnreadableFile, err)
+	}
+	defer file.Close()
+
+	// Read all lines
+	scanner := bufio.NewScanner(file)
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
 	}
 
-	// Get WKT and height parts
-	wktPart := strings.Join(parts[:len(parts)-1], ",")
-	heightStr := parts[len(parts)-1]
+	if err := scanner.Err(); err != nil {
+		utils.Error(ctx, "error reading file", "file_path", filePath, "error", err)
+		return nil, fmt.Errorf("%w: %w", ErrUnreadableFile, err)
+	}
 
-	// Clean WKT and height strings
-	wktGeom := strings.Trim(strings.TrimSpace(wktPart), `"'`)
-	heightStr = strings.Trim(strings.TrimSpace(heightStr), `"'`)
+	utils.Info(ctx, "read lines from file", "lines", len(lines))
+	return lines, nil
+}
+
+// importRecord is an import line that passed the checks made before PostGIS.
+type importRecord struct {
+	line       int
+	wkt        string // the WKT to store: the line's, or with rings closed when repairing
+	height     float64
+	buildingID int64
+	footprint  geom.MultiPolygon
+	repair     *model.ImportIssue // the problem being repaired, nil when none
+}
+
+// lineResult is the outcome of one import line.
+type lineResult struct {
+	imported   bool
+	reoriented bool               // the footprint's rings were reversed
+	issue      *model.ImportIssue // why the line was rejected or repaired, nil when imported as is
+}
+
+// quarantinable reports whether the line goes to quarantine under policy: it was
+// rejected for its content rather than by a database failure.
+func (res lineResult) quarantinable(policy string) bool {
+	return !res.imported && policy == model.InvalidGeometryQuarantine && res.issue.Reason != model.ReasonInsertFailed
+}
+
+// tally adds the line to result and returns its outcome for the import metrics.
+func (res lineResult) tally(result *model.ImportResult) string {
+	outcome := "success"
+	switch {
+	case res.imported:
+		result.SuccessCount++
+		if res.issue != nil {
+			outcome = "repaired"
+			result.RepairedCount++
+		}
+		if res.reoriented {
+			result.ReorientedCount++
+		}
+	case res.issue.Action == model.ImportQuarantined:
+		outcome = "quarantined"
+		result.ErrorCount++
+		result.QuarantinedCount++
+	default:
+		outcome = "failure"
+		result.ErrorCount++
+	}
+	if res.issue != nil {
+		result.AddIssue(*res.issue)
+	}
+	return outcome
+}
+
+// parseRecord parses and validates an import line. It returns the issue rejecting
+// the line when it cannot be imported under policy.
+func parseRecord(line string, lineNum int, policy string) (importRecord, *model.ImportIssue) {
+	reject := func(reason, detail string) (importRecord, *model.ImportIssue) {
+		return importRecord{}, &model.ImportIssue{Line: lineNum, Action: model.ImportRejected, Reason: reason, Detail: detail}
+	}
 
-	// Validate WKT format
-	if !strings.HasPrefix(strings.ToUpper(wktGeom), "MULTIPOLYGON") || !strings.HasSuffix(wktGeom, "))") {
-		return false, fmt.Errorf("invalid WKT format")
+	// Parse the line (format: MULTIPOLYGON(((...))),13.56)
+	sep := strings.LastIndex(line, ",")
+	if sep < 0 {
+		return reject(model.ReasonMalformedLine, "expected WKT,height")
 	}
 
+	// Clean WKT and height strings
+	wktGeom := strings.Trim(strings.TrimSpace(line[:sep]), `"'`)
+	heightStr := strings.Trim(strings.TrimSpace(line[sep+1:]), `"'`)
+
 	// Parse height
 	buildingHeight, err := strconv.ParseFloat(heightStr, 64)
+	if err != nil || math.IsNaN(buildingHeight) || math.IsInf(buildingHeight, 0) || buildingHeight < 0 {
+		return reject(model.ReasonInvalidHeight, fmt.Sprintf("%q is not a non-negative number", heightStr))
+	}
+
+	// Parse and validate the footprint
+	footprint, err := geom.ParseWKT(wktGeom)
 	if err != nil {
-		return false, fmt.Errorf("invalid height format: %v", err)
+		return reject(model.ReasonInvalidWKT, err.Error())
+	}
+	rec := importRecord{line: lineNum, wkt: wktGeom, height: buildingHeight, footprint: footprint}
+	if problem := geom.Validate(footprint); problem != nil {
+		if policy != model.InvalidGeometryRepair || !problem.Repairable() {
+			return reject(problem.Reason, problem.Detail)
+		}
+		// Close open rings here; ST_MakeValid repairs the rest
+		rec.footprint = footprint.Closed()
+		if p := geom.Validate(rec.footprint); p != nil && !p.Repairable() {
+			return reject(p.Reason, p.Detail)
+		}
+		rec.wkt = rec.footprint.WKT()
+		rec.repair = &model.ImportIssue{Line: lineNum, Action: model.ImportRepaired, Reason: problem.Reason, Detail: problem.Detail}
 	}
 
 	// Generate building ID
-	buildingID, err := GenerateBuildingIDPureCode(wktGeom)
+	rec.buildingID, err = GenerateBuildingIDPureCode(wktGeom)
 	if err != nil {
-		return false, fmt.Errorf("failed to generate building ID: %v", err)
+		return reject(model.ReasonInsertFailed, fmt.Sprintf("failed to generate building ID: %v", err))
 	}
+	return rec, nil
+}
 
-	// Execute single insert with individual transaction
-	insertQuery := `
-		INSERT INTO hzdk_buildings (geom, building_height, building_id)
-		VALUES (ST_GeomFromText($1, 4326), $2, $3)
-	`
+// checkedRecord is the outcome of PostGIS validation of an import record.
+type checkedRecord struct {
+	valid      bool   // ST_IsValid
+	reason     string // ST_IsValidReason when not valid
+	importable bool   // valid, or repaired to a non-empty footprint
+}
+
+// result maps the PostGIS validation of rec to the line's outcome under policy.
+func (c checkedRecord) result(rec importRecord, policy string) lineResult {
+	reject := func(reason, detail string) lineResult {
+		return lineResult{issue: &model.ImportIssue{Line: rec.line, Action: model.ImportRejected, Reason: reason, Detail: detail}}
+	}
+	issue := rec.repair
+	switch {
+	case !c.importable && !c.valid && policy == model.InvalidGeometryRepair:
+		return reject(model.ReasonRepairFailed, c.reason)
+	case !c.importable && !c.valid:
+		return reject(model.ReasonInvalidGeometry, c.reason)
+	case !c.importable:
+		return reject(model.ReasonInsertFailed, "no row inserted")
+	case !c.valid && issue == nil:
+		issue = &model.ImportIssue{Line: rec.line, Action: model.ImportRepaired, Reason: model.ReasonInvalidGeometry, Detail: c.reason}
+	}
+	return lineResult{imported: true, reoriented: issue == nil && !rec.footprint.IsOriented(true), issue: issue}
+}
 
-	_, err = r.dbpool.Exec(ctx, insertQuery, wktGeom, buildingHeight, buildingID)
+// validityColumns checks the geometry g: it selects valid, reason and geom, the
+// footprint to store (NULL when invalid and not repaired). $n is the repair flag.
+const validityColumns = `
+	valid,
+	CASE WHEN valid THEN '' ELSE ST_IsValidReason(g) END AS reason,
+	CASE WHEN valid THEN g WHEN $%d::boolean THEN ST_Multi(ST_CollectionExtract(ST_MakeValid(g), 3)) END AS geom`
+
+func (r *BuildingRepository) processSingleRecord(ctx context.Context, rec importRecord, policy string) lineResult {
+	// PostGIS has the final say on validity (e.g. holes outside their shell). Invalid
+	// geometries are inserted only when repairing, as the polygons ST_MakeValid
+	// yields. Rings are stored counter-clockwise, holes clockwise.
+	insertQuery := fmt.Sprintf(`
+		WITH src AS (
+			SELECT g, ST_IsValid(g) AS valid FROM ST_Multi(ST_GeomFromText($1, 4326)) AS g
+		), fixed AS (
+			SELECT %s
+			FROM src
+		), inserted AS (
+			INSERT INTO hzdk_buildings (geom, building_height, building_id)
+			SELECT ST_ForcePolygonCCW(geom), $2::numeric, $3::bigint FROM fixed WHERE NOT ST_IsEmpty(geom)
+			RETURNING 1
+		)
+		SELECT valid, reason, EXISTS (SELECT 1 FROM inserted) FROM fixed
+	`, fmt.Sprintf(validityColumns, 4))
+
+	var c checkedRecord
+	err := r.dbpool.QueryRow(ctx, insertQuery, rec.wkt, rec.height, rec.buildingID, policy == model.InvalidGeometryRepair).Scan(&c.valid, &c.reason, &c.importable)
 	if err != nil {
-		return false, fmt.Errorf("database insert failed: %v", err)
+		return lineResult{issue: &model.ImportIssue{Line: rec.line, Action: model.ImportRejected, Reason: model.ReasonInsertFailed,
+			Detail: fmt.Sprintf("database insert failed: %v", err)}}
 	}
+	return c.result(rec, policy)
+}
+
+// dryRunBatch is the number of import lines checked against PostGIS per query in a
+// dry run.
+const dryRunBatch = 500
+
+// dryRunAreaRadius is how far, in meters, a dry run looks for an existing building
+// with an area code to assign a footprint to its area.
+const dryRunAreaRadius = 200
 
-	return true, nil
+// DryRunBuildingsFromFile reports what InsertBuildingsFromFile would do with the file
+// under opts, without writing anything. Lines are parsed and validated as the import
+// would, checked by PostGIS in batches, and looked up for duplicate building IDs and
+// their area: the area code of the nearest existing building within
+// dryRunAreaRadius meters, preferring one the footprint intersects.
+// progress, if non-nil, is called with the number of lines processed so far and the total.
+func (r *BuildingRepository) DryRunBuildingsFromFile(ctx context.Context, filePath string, opts model.ImportOptions, progress ProgressFunc) (*model.ImportReport, error) {
+	utils.Info(ctx, "dry-running import of buildings from file", "file_path", filePath, "invalid_geometry", opts.InvalidGeometry)
+
+	lines, err := readImportFile(ctx, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &model.ImportReport{
+		ImportResult:    model.ImportResult{TotalCount: len(lines)},
+		FilePath:        filePath,
+		InvalidGeometry: opts.InvalidGeometry,
+		Areas:           map[string]int{},
+	}
+	bounds := geom.EmptyBBox()
+	var heights []float64
+	firstLine := map[int64]int{} // building_id -> first importable line
+
+	// Lines are tallied in file order once the batch holding them has been checked
+	type pendingLine struct {
+		rec      importRecord
+		rejected *model.ImportIssue
+	}
+	var pending []pendingLine
+	var batch []importRecord
+	flush := func() error {
+		checks, err := r.checkRecords(ctx, batch, opts.InvalidGeometry)
+		if err != nil {
+			return err
+		}
+		next := 0
+		for _, p := range pending {
+			res := lineResult{issue: p.rejected}
+			var check dryRunCheck
+			if p.rejected == nil {
+				check = checks[next]
+				next++
+				res = check.result(p.rec, opts.InvalidGeometry)
+			}
+			if res.quarantinable(opts.InvalidGeometry) {
+				res.issue.Action = model.ImportQuarantined
+			}
+			res.tally(&report.ImportResult)
+			if !res.imported {
+				continue
+			}
+
+			heights = append(heights, p.rec.height)
+			bounds = bounds.Extend(p.rec.footprint.Bounds())
+			area := model.UnassignedArea
+			if check.area != nil {
+				area = *check.area
+			}
+			report.Areas[area]++
+			first, seen := firstLine[p.rec.buildingID]
+			switch {
+			case check.existing:
+				report.AddDuplicate(model.ImportDuplicate{Line: p.rec.line, BuildingID: p.rec.buildingID, Existing: true})
+			case seen:
+				report.AddDuplicate(model.ImportDuplicate{Line: p.rec.line, BuildingID: p.rec.buildingID, FirstLine: first})
+			}
+			if !seen {
+				firstLine[p.rec.buildingID] = p.rec.line
+			}
+		}
+		pending, batch = pending[:0], batch[:0]
+		return nil
+	}
+
+	for lineNum, line := range lines {
+		if progress != nil {
+			progress(lineNum, len(lines))
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		rec, rejected := parseRecord(line, lineNum+1, opts.InvalidGeometry)
+		pending = append(pending, pendingLine{rec: rec, rejected: rejected})
+		if rejected == nil {
+			batch = append(batch, rec)
+		}
+		if len(batch) == dryRunBatch {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	if progress != nil {
+		progress(len(lines), len(lines))
+	}
+
+	if len(lines) > 0 {
+		report.SuccessRate = float64(report.SuccessCount) / float64(len(lines)) * 100
+	}
+	report.Heights = model.NewHeightStats(heights)
+	if !bounds.IsEmpty() {
+		report.BBox = &[4]float64{bounds.MinX, bounds.MinY, bounds.MaxX, bounds.MaxY}
+	}
+
+	utils.Info(ctx, "import dry run completed",
+		"success_count", report.SuccessCount, "error_count", report.ErrorCount, "duplicate_count", report.DuplicateCount)
+
+	return report, nil
+}
+
+// dryRunCheck is the PostGIS check of an import record in a dry run.
+type dryRunCheck struct {
+	checkedRecord
+	existing bool    // hzdk_buildings already holds the building_id
+	area     *string // area_code of the nearest existing building with one, nil when none is near
+	err      error   // the query failed for this record
+}
+
+func (c dryRunCheck) result(rec importRecord, policy string) lineResult {
+	if c.err != nil {
+		return lineResult{issue: &model.ImportIssue{Line: rec.line, Action: model.ImportRejected, Reason: model.ReasonInsertFailed,
+			Detail: fmt.Sprintf("database check failed: %v", c.err)}}
+	}
+	return c.checkedRecord.result(rec, policy)
+}
+
+// checkRecords validates recs with PostGIS as processSingleRecord would, and looks up
+// their duplicates and areas. When the batch fails, the records are checked one by
+// one so that a geometry PostGIS cannot read fails only its own line.
+func (r *BuildingRepository) checkRecords(ctx context.Context, recs []importRecord, policy string) ([]dryRunCheck, error) {
+	if len(recs) == 0 {
+		return nil, nil
+	}
+	checks, err := r.queryChecks(ctx, recs, policy)
+	switch {
+	case err == nil:
+		return checks, nil
+	case ctx.Err() != nil:
+		return nil, ctx.Err()
+	case len(recs) == 1:
+		return []dryRunCheck{{err: err}}, nil
+	}
+
+	utils.Warn(ctx, "dry run batch check failed, checking lines one by one", "lines", len(recs), "error", err)
+	checks = make([]dryRunCheck, len(recs))
+	for i := range recs {
+		c, err := r.queryChecks(ctx, recs[i:i+1], policy)
+		switch {
+		case ctx.Err() != nil:
+			return nil, ctx.Err()
+		case err != nil:
+			checks[i].err = err
+		default:
+			checks[i] = c[0]
+		}
+	}
+	return checks, nil
+}
+
+func (r *BuildingRepository) queryChecks(ctx context.Context, recs []importRecord, policy string) ([]dryRunCheck, error) {
+	defer metrics.ObserveQuery("import_dry_run", time.Now())
+
+	wkts := make([]string, len(recs))
+	ids := make([]int64, len(recs))
+	for i, rec := range recs {
+		wkts[i], ids[i] = rec.wkt, rec.buildingID
+	}
+	query := fmt.Sprintf(`
+		WITH src AS (
+			SELECT i.ord, i.id, g, ST_IsValid(g) AS valid
+			FROM unnest($1::text[], $2::bigint[]) WITH ORDINALITY AS i(wkt, id, ord)
+				CROSS JOIN LATERAL ST_Multi(ST_GeomFromText(i.wkt, 4326)) AS g
+		), fixed AS (
+			SELECT ord, id, %s
+			FROM src
+		)
+		SELECT
+			valid, reason, COALESCE(NOT ST_IsEmpty(geom), false),
+			EXISTS (SELECT 1 FROM hzdk_buildings b WHERE b.building_id = f.id),
+			(SELECT b.area_code FROM hzdk_buildings b
+			 WHERE b.area_code <> '' AND ST_DWithin(b.geom::geography, f.geom::geography, $4)
+			 ORDER BY ST_Distance(b.geom::geography, f.geom::geography), b.building_id
+			 LIMIT 1)
+		FROM fixed f
+		ORDER BY ord
+	`, fmt.Sprintf(validityColumns, 3))
+
+	rows, err := r.dbpool.Query(ctx, query, wkts, ids, policy == model.InvalidGeometryRepair, dryRunAreaRadius)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	checks := make([]dryRunCheck, 0, len(recs))
+	for rows.Next() {
+		var c dryRunCheck
+		if err := rows.Scan(&c.valid, &c.reason, &c.importable, &c.existing, &c.area); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		checks = append(checks, c)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+	if len(checks) != len(recs) {
+		return nil, fmt.Errorf("checked %d of %d lines", len(checks), len(recs))
+	}
+	return checks, nil
+}
+
+// quarantine stores an import line that failed validation in hzdk_buildings_quarantine.
+func (r *BuildingRepository) quarantine(ctx context.Context, filePath string, lineNum int, line string, issue *model.ImportIssue) error {
+	_, err := r.dbpool.Exec(ctx, `
+		INSERT INTO hzdk_buildings_quarantine (file_path, line_number, line, reason, detail)
+		VALUES ($1, $2, $3, $4, $5)
+	`, filePath, lineNum, line, issue.Reason, issue.Detail)
+	if err != nil {
+		return fmt.Errorf("failed to quarantine line: %w", err)
+	}
+	return nil
+}
+
+// ExportBuildings streams the buildings matching filter, ordered by building_id, to
+// fn. Rows are streamed from the connection, so exports of any size use constant memory.
+// Iteration stops at the first error returned by fn.
+func (r *BuildingRepository) ExportBuildings(ctx context.Context, filter model.BuildingFilter, fn func(model.BuildingRecord) error) error {
+	defer metrics.ObserveQuery("export_buildings", time.Now())
+
+	var bbox []float64
+	if filter.BBox != nil {
+		bbox = filter.BBox[:]
+	}
+	table, _, args := r.relations([]any{bbox, filter.AreaCode, filter.Type})
+	query := fmt.Sprintf(`
+        SELECT
+            building_id, building_type, building_name, building_addr, area_code,
+            building_height::float8, roof_height::float8, create_time, update_time, ST_AsBinary(geom)
+        FROM
+            %s
+        WHERE
+            ($1::float8[] IS NULL OR geom && ST_MakeEnvelope($1[1], $1[2], $1[3], $1[4], 4326))
+            AND ($2 = '' OR area_code = $2)
+            AND ($3 = '' OR building_type = $3)
+        ORDER BY building_id
+    `, table)
+
+	utils.Debug(ctx, "executing export query", "bbox", bbox, "area_code", filter.AreaCode, "type", filter.Type, "as_of", r.asOf)
+
+	rows, err := r.dbpool.Query(ctx, query, args...)
+	if err != nil {
+		utils.Error(ctx, "database query failed", "error", err)
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var b model.BuildingRecord
+		err := rows.Scan(&b.BuildingID, &b.BuildingType, &b.BuildingName, &b.BuildingAddr, &b.AreaCode,
+			&b.BuildingHeight, &b.RoofHeight, &b.CreateTime, &b.UpdateTime, &b.WKB)
+		if err != nil {
+			utils.Error(ctx, "failed to scan row", "error", err)
+			return fmt.Errorf("failed to scan row: %w", err)
+		}
+		if err := fn(b); err != nil {
+			return err
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		utils.Error(ctx, "row iteration error", "error", err)
+		return fmt.Errorf("row iteration error: %w", err)
+	}
+	return nil
 }
 
 func GenerateBuildingIDPureCode(wktGeom string) (int64, error) {
@@ -217,17 +982,13 @@ func GenerateBuildingIDPureCode(wktGeom string) (int64, error) {
 }
 
 // Placeholder for update logic
-func (r *BuildingRepository) UpdateAllBuildingsInfoBatch(ctx context.Context) (map[string]interface{}, error) {
-	utils.Info("Updating all buildings info (batch)...")
+func (r *BuildingRepository) UpdateAllBuildingsInfoBatch(ctx context.Context) (*model.UpdateResult, error) {
+	utils.Info(ctx, "updating all buildings info (batch)")
 	// TODO: Implement actual update logic
 	// Example:
 	// 1. Query for buildings needing update
 	// 2. Process them
 	// 3. Update records
 	// For now, simulate success
-	return map[string]interface{}{
-		"success": true,
-		"code":    200,
-		"message": "All buildings info updated (placeholder)",
-	}, nil
+	return &model.UpdateResult{}, nil
 }
@@ -0,0 +1,297 @@
+// internal/repository/flight_plan_repo.go
+package repository
+
+import (
+	"collision_app_go/internal/model"
+	"collision_app_go/utils"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrNotFound is returned when a requested record does not exist.
+var ErrNotFound = errors.New("record not found")
+
+// planApprovalLock is the PostgreSQL advisory lock key serializing plan approval.
+const planApprovalLock = 0x666c6967687470 // "flightp"
+
+const flightPlanColumns = `
+    id, drone_id, route, altitude_min, altitude_max, start_time, end_time,
+    status, reasons, submitted_by, create_time, update_time
+`
+
+type FlightPlanRepository struct {
+	dbpool *pgxpool.Pool
+}
+
+func NewFlightPlanRepository(dbpool *pgxpool.Pool) *FlightPlanRepository {
+	return &FlightPlanRepository{dbpool: dbpool}
+}
+
+// LockPlanApproval runs fn while holding an advisory lock shared by every instance,
+// so that plans deconflicted and stored by fn see every plan approved before them.
+// fn must commit its writes before returning.
+func (r *FlightPlanRepository) LockPlanApproval(ctx context.Context, fn func() error) error {
+	conn, err := r.dbpool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, int64(planApprovalLock)); err != nil {
+		utils.Error(ctx, "failed to lock plan approval", "error", err)
+		return fmt.Errorf("failed to lock plan approval: %w", err)
+	}
+	defer func() {
+		// A connection still holding the lock must not return to the pool
+		if _, err := conn.Exec(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, int64(planApprovalLock)); err != nil {
+			utils.Error(ctx, "failed to unlock plan approval, closing the connection", "error", err)
+			conn.Conn().Close(context.WithoutCancel(ctx))
+		}
+	}()
+	return fn()
+}
+
+// CreateFlightPlan stores a new flight plan and records the first history entry.
+// The plan's ID and timestamps are filled in on success.
+func (r *FlightPlanRepository) CreateFlightPlan(ctx context.Context, plan *model.FlightPlan, actor string) error {
+	tx, err := r.dbpool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	insertQuery := `
+        INSERT INTO flight_plans
+            (drone_id, route, geom, altitude_min, altitude_max, start_time, end_time, status, reasons, submitted_by)
+        VALUES
+            ($1, $2, ST_GeomFromText($3, 4326), $4, $5, $6, $7, $8, $9, $10)
+        RETURNING id, create_time, update_time
+    `
+	err = tx.QueryRow(ctx, insertQuery,
+		plan.DroneID, plan.Route, model.LineStringWKT(plan.Route), plan.AltitudeMin, plan.AltitudeMax,
+		plan.StartTime, plan.EndTime, plan.Status, plan.Reasons, plan.SubmittedBy,
+	).Scan(&plan.ID, &plan.CreateTime, &plan.UpdateTime)
+	if err != nil {
+		utils.Error(ctx, "failed to insert flight plan", "error", err)
+		return fmt.Errorf("failed to insert flight plan: %w", err)
+	}
+
+	if err := insertPlanEvent(ctx, tx, plan.ID, plan.Status, plan.Reasons, actor, nil); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit flight plan: %w", err)
+	}
+	return nil
+}
+
+// GetFlightPlan returns the flight plan with the given ID, or ErrNotFound.
+func (r *FlightPlanRepository) GetFlightPlan(ctx context.Context, id int64) (*model.FlightPlan, error) {
+	query := `SELECT ` + flightPlanColumns + ` FROM flight_plans WHERE id = $1`
+
+	plan, err := scanFlightPlan(r.dbpool.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		utils.Error(ctx, "failed to get flight plan", "plan_id", id, "error", err)
+		return nil, fmt.Errorf("failed to get flight plan: %w", err)
+	}
+	return plan, nil
+}
+
+// ListFlightPlans returns flight plans, optionally filtered by drone ID and status.
+// Empty filter values match every plan.
+func (r *FlightPlanRepository) ListFlightPlans(ctx context.Context, droneID, status string) ([]model.FlightPlan, error) {
+	query := `
+        SELECT ` + flightPlanColumns + `
+        FROM flight_plans
+        WHERE ($1 = '' OR drone_id = $1)
+            AND ($2 = '' OR status = $2)
+        ORDER BY id DESC
+    `
+
+	rows, err := r.dbpool.Query(ctx, query, droneID, status)
+	if err != nil {
+		utils.Error(ctx, "database query failed", "error", err)
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	plans := []model.FlightPlan{}
+	for rows.Next() {
+		plan, err := scanFlightPlan(rows)
+		if err != nil {
+			utils.Error(ctx, "failed to scan row", "error", err)
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		plans = append(plans, *plan)
+	}
+
+	if err = rows.Err(); err != nil {
+		utils.Error(ctx, "row iteration error", "error", err)
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+	return plans, nil
+}
+
+// ListActiveFlightPlans returns approved and pending plans whose time window overlaps
+// [start, end] and whose route passes within distance meters of routeWKT.
+// The plan with excludeID (e.g. the plan being checked) is left out.
+func (r *FlightPlanRepository) ListActiveFlightPlans(ctx context.Context, start, end time.Time, routeWKT string, distance float64, excludeID int64) ([]model.FlightPlan, error) {
+	query := `
+        SELECT ` + flightPlanColumns + `
+        FROM flight_plans
+        WHERE status IN ($1, $2)
+            AND start_time < $4
+            AND end_time > $3
+            AND id <> $7
+            AND ST_DWithin(geom::geography, ST_GeomFromText($5, 4326)::geography, $6)
+        ORDER BY start_time
+    `
+
+	rows, err := r.dbpool.Query(ctx, query, model.PlanApproved, model.PlanNeedsReview, start, end, routeWKT, distance, excludeID)
+	if err != nil {
+		utils.Error(ctx, "database query failed", "error", err)
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	var plans []model.FlightPlan
+	for rows.Next() {
+		plan, err := scanFlightPlan(rows)
+		if err != nil {
+			utils.Error(ctx, "failed to scan row", "error", err)
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		plans = append(plans, *plan)
+	}
+
+	if err = rows.Err(); err != nil {
+		utils.Error(ctx, "row iteration error", "error", err)
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+	return plans, nil
+}
+
+// GetActivePlanDistance returns the approved plan of droneID whose time window
+// contains at, together with the horizontal distance in meters from the point to
+// its route. It returns ErrNotFound when the drone has no such plan; when several
+// overlap, the one starting last is used.
+func (r *FlightPlanRepository) GetActivePlanDistance(ctx context.Context, droneID string, at time.Time, longitude, latitude float64) (*model.FlightPlan, float64, error) {
+	query := `
+        SELECT ` + flightPlanColumns + `,
+            ST_Distance(geom::geography, ST_SetSRID(ST_MakePoint($4, $5), 4326)::geography)
+        FROM flight_plans
+        WHERE drone_id = $1 AND status = $2 AND start_time <= $3 AND end_time >= $3
+        ORDER BY start_time DESC
+        LIMIT 1
+    `
+	var p model.FlightPlan
+	var distance float64
+	err := r.dbpool.QueryRow(ctx, query, droneID, model.PlanApproved, at, longitude, latitude).Scan(
+		&p.ID, &p.DroneID, &p.Route, &p.AltitudeMin, &p.AltitudeMax, &p.StartTime, &p.EndTime,
+		&p.Status, &p.Reasons, &p.SubmittedBy, &p.CreateTime, &p.UpdateTime, &distance,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, 0, ErrNotFound
+	}
+	if err != nil {
+		utils.Error(ctx, "failed to get active flight plan", "drone_id", droneID, "error", err)
+		return nil, 0, fmt.Errorf("failed to get active flight plan: %w", err)
+	}
+	return &p, distance, nil
+}
+
+// UpdateFlightPlanStatus changes a plan's status and appends the change to its history.
+func (r *FlightPlanRepository) UpdateFlightPlanStatus(ctx context.Context, id int64, status string, reasons []string, actor string, comment *string) (*model.FlightPlan, error) {
+	tx, err := r.dbpool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	updateQuery := `
+        UPDATE flight_plans SET status = $2, reasons = $3
+        WHERE id = $1
+        RETURNING ` + flightPlanColumns
+
+	plan, err := scanFlightPlan(tx.QueryRow(ctx, updateQuery, id, status, reasons))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		utils.Error(ctx, "failed to update flight plan", "plan_id", id, "error", err)
+		return nil, fmt.Errorf("failed to update flight plan: %w", err)
+	}
+
+	if err := insertPlanEvent(ctx, tx, id, status, reasons, actor, comment); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit flight plan: %w", err)
+	}
+	return plan, nil
+}
+
+// GetFlightPlanHistory returns the status history of a plan, oldest first.
+func (r *FlightPlanRepository) GetFlightPlanHistory(ctx context.Context, planID int64) ([]model.FlightPlanEvent, error) {
+	query := `
+        SELECT id, plan_id, status, reasons, actor, comment, create_time
+        FROM flight_plan_history
+        WHERE plan_id = $1
+        ORDER BY id
+    `
+
+	rows, err := r.dbpool.Query(ctx, query, planID)
+	if err != nil {
+		utils.Error(ctx, "database query failed", "error", err)
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	events := []model.FlightPlanEvent{}
+	for rows.Next() {
+		var e model.FlightPlanEvent
+		if err := rows.Scan(&e.ID, &e.PlanID, &e.Status, &e.Reasons, &e.Actor, &e.Comment, &e.CreateTime); err != nil {
+			utils.Error(ctx, "failed to scan row", "error", err)
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		events = append(events, e)
+	}
+
+	if err = rows.Err(); err != nil {
+		utils.Error(ctx, "row iteration error", "error", err)
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+	return events, nil
+}
+
+func insertPlanEvent(ctx context.Context, tx pgx.Tx, planID int64, status string, reasons []string, actor string, comment *string) error {
+	insertQuery := `
+        INSERT INTO flight_plan_history (plan_id, status, reasons, actor, comment)
+        VALUES ($1, $2, $3, $4, $5)
+    `
+	if _, err := tx.Exec(ctx, insertQuery, planID, status, reasons, actor, comment); err != nil {
+		utils.Error(ctx, "failed to insert flight plan history", "plan_id", planID, "error", err)
+		return fmt.Errorf("failed to insert flight plan history: %w", err)
+	}
+	return nil
+}
+
+func scanFlightPlan(row pgx.Row) (*model.FlightPlan, error) {
+	var p model.FlightPlan
+	err := row.Scan(&p.ID, &p.DroneID, &p.Route, &p.AltitudeMin, &p.AltitudeMax, &p.StartTime, &p.EndTime,
+		&p.Status, &p.Reasons, &p.SubmittedBy, &p.CreateTime, &p.UpdateTime)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
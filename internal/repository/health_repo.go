@@ -0,0 +1,51 @@
+// internal/repository/health_repo.go
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type HealthRepository struct {
+	dbpool *pgxpool.Pool
+}
+
+func NewHealthRepository(dbpool *pgxpool.Pool) *HealthRepository {
+	return &HealthRepository{dbpool: dbpool}
+}
+
+// Ping verifies that a connection can be acquired and the database responds.
+func (r *HealthRepository) Ping(ctx context.Context) error {
+	return r.dbpool.Ping(ctx)
+}
+
+// PostGISVersion returns the installed PostGIS extension version, or "" if missing.
+func (r *HealthRepository) PostGISVersion(ctx context.Context) (string, error) {
+	var version string
+	err := r.dbpool.QueryRow(ctx, `SELECT extversion FROM pg_extension WHERE extname = 'postgis'`).Scan(&version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to query postgis extension: %w", err)
+	}
+	return version, nil
+}
+
+// TableExists reports whether a table (optionally schema-qualified) is visible.
+func (r *HealthRepository) TableExists(ctx context.Context, name string) (bool, error) {
+	var exists bool
+	if err := r.dbpool.QueryRow(ctx, `SELECT to_regclass($1) IS NOT NULL`, name).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to look up table %s: %w", name, err)
+	}
+	return exists, nil
+}
+
+// PoolStat returns a snapshot of the connection pool statistics.
+func (r *HealthRepository) PoolStat() *pgxpool.Stat {
+	return r.dbpool.Stat()
+}
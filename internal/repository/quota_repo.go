@@ -0,0 +1,67 @@
+// internal/repository/quota_repo.go
+package repository
+
+import (
+	"collision_app_go/internal/metrics"
+	"collision_app_go/utils"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// QuotaRepository stores daily request counts per client.
+type QuotaRepository struct {
+	dbpool *pgxpool.Pool
+}
+
+func NewQuotaRepository(dbpool *pgxpool.Pool) *QuotaRepository {
+	return &QuotaRepository{dbpool: dbpool}
+}
+
+// LoadQuotaUsage returns the request counts of every client for day (2006-01-02).
+func (r *QuotaRepository) LoadQuotaUsage(ctx context.Context, day string) (map[string]int64, error) {
+	defer metrics.ObserveQuery("load_quota_usage", time.Now())
+
+	rows, err := r.dbpool.Query(ctx, `SELECT client, requests FROM client_quota_usage WHERE day = $1::date`, day)
+	if err != nil {
+		utils.Error(ctx, "database query failed", "error", err)
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	usage := map[string]int64{}
+	for rows.Next() {
+		var client string
+		var requests int64
+		if err := rows.Scan(&client, &requests); err != nil {
+			return nil, fmt.Errorf("failed to scan quota usage: %w", err)
+		}
+		usage[client] = requests
+	}
+	return usage, rows.Err()
+}
+
+// AddQuotaUsage adds the given request counts to the clients' totals for day.
+func (r *QuotaRepository) AddQuotaUsage(ctx context.Context, day string, deltas map[string]int64) error {
+	defer metrics.ObserveQuery("add_quota_usage", time.Now())
+
+	upsertQuery := `
+        INSERT INTO client_quota_usage (day, client, requests)
+        VALUES ($1::date, $2, $3)
+        ON CONFLICT (day, client) DO UPDATE
+        SET requests = client_quota_usage.requests + EXCLUDED.requests,
+            update_time = CURRENT_TIMESTAMP
+    `
+	batch := &pgx.Batch{}
+	for client, n := range deltas {
+		batch.Queue(upsertQuery, day, client, n)
+	}
+	if err := r.dbpool.SendBatch(ctx, batch).Close(); err != nil {
+		utils.Error(ctx, "failed to update quota usage", "error", err)
+		return fmt.Errorf("failed to update quota usage: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+// internal/repository/webhook_repo.go
+package repository
+
+import (
+	"collision_app_go/internal/metrics"
+	"collision_app_go/internal/model"
+	"collision_app_go/utils"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const subscriptionColumns = `id, url, events, description, active, created_by, create_time`
+
+const deliveryColumns = `
+    id, subscription_id, event_id, event_type, status, attempts, next_attempt_time,
+    last_status, last_error, create_time, delivered_time
+`
+
+type WebhookRepository struct {
+	dbpool *pgxpool.Pool
+}
+
+func NewWebhookRepository(dbpool *pgxpool.Pool) *WebhookRepository {
+	return &WebhookRepository{dbpool: dbpool}
+}
+
+// CreateSubscription stores a new subscription together with its signing secret.
+func (r *WebhookRepository) CreateSubscription(ctx context.Context, sub *model.WebhookSubscription, secret string) error {
+	query := `
+        INSERT INTO webhook_subscriptions (url, secret, events, description, created_by)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id, active, create_time
+    `
+	err := r.dbpool.QueryRow(ctx, query, sub.URL, secret, sub.Events, sub.Description, sub.CreatedBy).
+		Scan(&sub.ID, &sub.Active, &sub.CreateTime)
+	if err != nil {
+		utils.Error(ctx, "failed to insert webhook subscription", "error", err)
+		return fmt.Errorf("failed to insert webhook subscription: %w", err)
+	}
+	return nil
+}
+
+// GetSubscription returns the subscription with the given ID, or ErrNotFound.
+func (r *WebhookRepository) GetSubscription(ctx context.Context, id int64) (*model.WebhookSubscription, error) {
+	query := `SELECT ` + subscriptionColumns + ` FROM webhook_subscriptions WHERE id = $1`
+	sub, err := scanSubscription(r.dbpool.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		utils.Error(ctx, "failed to get webhook subscription", "subscription_id", id, "error", err)
+		return nil, fmt.Errorf("failed to get webhook subscription: %w", err)
+	}
+	return sub, nil
+}
+
+// ListSubscriptions returns every subscription, newest first.
+func (r *WebhookRepository) ListSubscriptions(ctx context.Context) ([]model.WebhookSubscription, error) {
+	rows, err := r.dbpool.Query(ctx, `SELECT `+subscriptionColumns+` FROM webhook_subscriptions ORDER BY id DESC`)
+	if err != nil {
+		utils.Error(ctx, "database query failed", "error", err)
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	subs := []model.WebhookSubscription{}
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook subscription: %w", err)
+		}
+		subs = append(subs, *sub)
+	}
+	return subs, rows.Err()
+}
+
+// DeactivateSubscription stops deliveries to a subscription. Its delivery history is
+// kept. It returns ErrNotFound for an unknown ID.
+func (r *WebhookRepository) DeactivateSubscription(ctx context.Context, id int64) error {
+	tag, err := r.dbpool.Exec(ctx, `UPDATE webhook_subscriptions SET active = false WHERE id = $1`, id)
+	if err != nil {
+		utils.Error(ctx, "failed to deactivate webhook subscription", "error", err)
+		return fmt.Errorf("failed to deactivate webhook subscription: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// EnqueueEvent adds an outbox entry for every active subscription to the event's
+// type and returns how many were added.
+func (r *WebhookRepository) EnqueueEvent(ctx context.Context, eventID, eventType string, payload []byte) (int64, error) {
+	defer metrics.ObserveQuery("enqueue_webhook_event", time.Now())
+
+	query := `
+        INSERT INTO webhook_outbox (subscription_id, event_id, event_type, payload)
+        SELECT id, $1, $2, $3
+        FROM webhook_subscriptions
+        WHERE active AND $2 = ANY (events)
+    `
+	tag, err := r.dbpool.Exec(ctx, query, eventID, eventType, payload)
+	if err != nil {
+		utils.Error(ctx, "failed to enqueue webhook event", "event_type", eventType, "error", err)
+		return 0, fmt.Errorf("failed to enqueue webhook event: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// ClaimDueDeliveries leases up to limit pending deliveries whose next attempt is due,
+// counting the attempt and pushing next_attempt_time out by lease so that other
+// dispatchers skip them. A dispatcher that dies mid-delivery therefore only delays
+// the retry until the lease expires.
+func (r *WebhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]model.WebhookDelivery, error) {
+	defer metrics.ObserveQuery("claim_webhook_deliveries", time.Now())
+
+	query := `
+        WITH due AS (
+            SELECT o.id
+            FROM webhook_outbox o
+            JOIN webhook_subscriptions s ON s.id = o.subscription_id
+            WHERE o.status = 'pending' AND o.next_attempt_time <= now() AND s.active
+            ORDER BY o.next_attempt_time
+            LIMIT $1
+            FOR UPDATE OF o SKIP LOCKED
+        )
+        UPDATE webhook_outbox o
+        SET attempts = o.attempts + 1, next_attempt_time = now() + $2 * interval '1 second'
+        FROM due, webhook_subscriptions s
+        WHERE o.id = due.id AND s.id = o.subscription_id
+        RETURNING o.id, o.subscription_id, o.event_id, o.event_type, o.status, o.attempts, o.next_attempt_time,
+            o.last_status, o.last_error, o.create_time, o.delivered_time, o.payload, s.url, s.secret
+    `
+	rows, err := r.dbpool.Query(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		utils.Error(ctx, "failed to claim webhook deliveries", "error", err)
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []model.WebhookDelivery
+	for rows.Next() {
+		var d model.WebhookDelivery
+		err := rows.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.Status, &d.Attempts, &d.NextAttemptTime,
+			&d.LastStatus, &d.LastError, &d.CreateTime, &d.DeliveredTime, &d.Payload, &d.URL, &d.Secret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+// MarkDelivered records a successful delivery.
+func (r *WebhookRepository) MarkDelivered(ctx context.Context, id int64, status int) error {
+	query := `
+        UPDATE webhook_outbox
+        SET status = 'delivered', last_status = $2, last_error = NULL, delivered_time = now()
+        WHERE id = $1
+    `
+	if _, err := r.dbpool.Exec(ctx, query, id, status); err != nil {
+		utils.Error(ctx, "failed to mark webhook delivered", "delivery_id", id, "error", err)
+		return fmt.Errorf("failed to mark webhook delivered: %w", err)
+	}
+	return nil
+}
+
+// MarkAttemptFailed records a failed attempt. With retryAt nil the delivery is given
+// up; otherwise it stays pending until retryAt. status is 0 when no response arrived.
+func (r *WebhookRepository) MarkAttemptFailed(ctx context.Context, id int64, status int, reason string, retryAt *time.Time) error {
+	query := `
+        UPDATE webhook_outbox
+        SET status = CASE WHEN $4::timestamptz IS NULL THEN 'failed' ELSE 'pending' END,
+            next_attempt_time = COALESCE($4, next_attempt_time),
+            last_status = NULLIF($2, 0),
+            last_error = $3
+        WHERE id = $1
+    `
+	if _, err := r.dbpool.Exec(ctx, query, id, status, reason, retryAt); err != nil {
+		utils.Error(ctx, "failed to record webhook attempt", "delivery_id", id, "error", err)
+		return fmt.Errorf("failed to record webhook attempt: %w", err)
+	}
+	return nil
+}
+
+// ListDeliveries returns the newest deliveries of a subscription, optionally filtered
+// by status.
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, subscriptionID int64, status string, limit int) ([]model.WebhookDelivery, error) {
+	query := `
+        SELECT ` + deliveryColumns + `
+        FROM webhook_outbox
+        WHERE subscription_id = $1 AND ($2 = '' OR status = $2)
+        ORDER BY id DESC
+        LIMIT $3
+    `
+	rows, err := r.dbpool.Query(ctx, query, subscriptionID, status, limit)
+	if err != nil {
+		utils.Error(ctx, "database query failed", "error", err)
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []model.WebhookDelivery{}
+	for rows.Next() {
+		var d model.WebhookDelivery
+		err := rows.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.Status, &d.Attempts, &d.NextAttemptTime,
+			&d.LastStatus, &d.LastError, &d.CreateTime, &d.DeliveredTime)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+func scanSubscription(row pgx.Row) (*model.WebhookSubscription, error) {
+	var s model.WebhookSubscription
+	if err := row.Scan(&s.ID, &s.URL, &s.Events, &s.Description, &s.Active, &s.CreatedBy, &s.CreateTime); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
@@ -0,0 +1,250 @@
+// internal/service/audit_service.go
+package service
+
+import (
+	"collision_app_go/internal/apperr"
+	"collision_app_go/internal/model"
+	"collision_app_go/internal/repository"
+	"collision_app_go/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Auditor records completed collision checks. Record must not block: it is called
+// on the request path.
+type Auditor interface {
+	Record(entry model.AuditEntry)
+}
+
+// Origin describes where a call came from, for the audit log.
+type Origin struct {
+	Source string // see model.Source*
+	Addr   string // client address
+}
+
+type originKey struct{}
+
+// WithOrigin returns a copy of ctx carrying the call's origin.
+func WithOrigin(ctx context.Context, source, addr string) context.Context {
+	return context.WithValue(ctx, originKey{}, Origin{Source: source, Addr: addr})
+}
+
+// OriginFromContext returns the origin carried by ctx, or a zero Origin.
+func OriginFromContext(ctx context.Context) Origin {
+	o, _ := ctx.Value(originKey{}).(Origin)
+	return o
+}
+
+// audit records a finished check when an auditor is configured. result is the
+// check's answer and is ignored when err is set.
+func (s *CollisionService) audit(ctx context.Context, check string, start time.Time, input model.AuditInput, result any, err error) {
+	if s.auditor == nil {
+		return
+	}
+	origin := OriginFromContext(ctx)
+	input.AsOf = s.asOf
+	entry := model.AuditEntry{
+		Time:       start,
+		RequestID:  utils.RequestIDFromContext(ctx),
+		Source:     origin.Source,
+		ClientAddr: origin.Addr,
+		Check:      check,
+		Input:      input,
+		LatencyMs:  float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if p := PrincipalFromContext(ctx); p != nil {
+		entry.Caller, entry.AuthMethod = p.Subject, p.Method
+	}
+	if err != nil {
+		entry.AuditOutcome = model.AuditOutcome{BuildingIDs: []int64{}, ErrorCode: string(apperr.From(err).Code)}
+	} else {
+		entry.AuditOutcome = outcome(result)
+		if b, err := json.Marshal(result); err == nil {
+			entry.Result = b
+		}
+	}
+	s.auditor.Record(entry)
+}
+
+// outcome extracts the comparable part of a check result.
+func outcome(result any) model.AuditOutcome {
+	o := model.AuditOutcome{}
+	var ids []int64
+	switch r := result.(type) {
+	case *model.CollisionResult:
+		o.IsCollision = r.IsCollision
+		for _, b := range r.BuildingInfos {
+			ids = append(ids, b.BuildingID)
+		}
+	case *model.SolidCollisionResult:
+		o.IsCollision = r.IsCollision
+		for _, h := range r.BuildingInfos {
+			ids = append(ids, h.Building.BuildingID)
+		}
+	case *model.ZonesResult:
+		o.IsCollision, o.Level = r.IsCollision, r.Level
+		for _, buildings := range r.Buildings {
+			for _, b := range buildings {
+				ids = append(ids, b.BuildingID)
+			}
+		}
+	case *model.LineOfSightResult:
+		o.IsCollision = !r.IsVisible
+		for _, b := range r.BlockingBuildings {
+			ids = append(ids, b.Building.BuildingID)
+		}
+	case *model.RouteResult:
+		o.IsCollision = r.IsCollision
+		for _, leg := range r.Legs {
+			for _, b := range leg.Buildings {
+				ids = append(ids, b.BuildingID)
+			}
+		}
+	case *model.PredictionResult:
+		o.IsCollision = r.IsCollision
+		for _, h := range r.Obstacles {
+			ids = append(ids, h.Building.BuildingID)
+		}
+	}
+	slices.Sort(ids)
+	o.BuildingIDs = slices.Compact(ids)
+	if o.BuildingIDs == nil {
+		o.BuildingIDs = []int64{}
+	}
+	return o
+}
+
+type AuditService struct {
+	repo      *repository.AuditRepository
+	collision *CollisionService
+}
+
+// NewAuditService returns a service replaying recorded checks with collision,
+// which should not itself be audited.
+func NewAuditService(repo *repository.AuditRepository, collision *CollisionService) *AuditService {
+	return &AuditService{repo: repo, collision: collision}
+}
+
+// Replay re-runs the recorded checks matching filter against the current data, in
+// recorded order, and calls fn with each comparison.
+func (s *AuditService) Replay(ctx context.Context, filter repository.AuditFilter, fn func(model.AuditReplay) error) (*model.AuditReplaySummary, error) {
+	summary := &model.AuditReplaySummary{}
+	err := s.repo.ScanAuditEntries(ctx, filter, func(e model.AuditEntry) error {
+		replayed, err := s.run(ctx, e.Check, e.Input)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			var ae *apperr.Error
+			if !errors.As(err, &ae) {
+				return err
+			}
+			replayed = model.AuditOutcome{BuildingIDs: []int64{}, ErrorCode: string(ae.Code)}
+		}
+
+		r := model.AuditReplay{
+			EntryID:   e.ID,
+			Time:      e.Time,
+			RequestID: e.RequestID,
+			Check:     e.Check,
+			Recorded:  e.AuditOutcome,
+			Replayed:  replayed,
+			Added:     difference(replayed.BuildingIDs, e.BuildingIDs),
+			Removed:   difference(e.BuildingIDs, replayed.BuildingIDs),
+		}
+		r.Differs = r.Recorded.IsCollision != r.Replayed.IsCollision || r.Recorded.Level != r.Replayed.Level ||
+			r.Recorded.ErrorCode != r.Replayed.ErrorCode || len(r.Added) > 0 || len(r.Removed) > 0
+
+		summary.Replayed++
+		if r.Differs {
+			summary.Differ++
+		} else {
+			summary.Identical++
+		}
+		return fn(r)
+	})
+	return summary, err
+}
+
+// run repeats one recorded check. Entries with inputs that cannot be replayed
+// return an error without an apperr code.
+func (s *AuditService) run(ctx context.Context, check string, in model.AuditInput) (model.AuditOutcome, error) {
+	collision := s.collision
+	if in.AsOf != nil {
+		collision = collision.AsOf(*in.AsOf)
+	}
+	footprint := model.Footprint{}
+	if in.Footprint != nil {
+		footprint = *in.Footprint
+	}
+	missing := func(field string) error {
+		return fmt.Errorf("%s entry without %s cannot be replayed", check, field)
+	}
+
+	var result any
+	var err error
+	switch check {
+	case model.CheckPoint, model.CheckPoint3D:
+		if in.Position == nil || in.CollisionDistance == nil {
+			return model.AuditOutcome{}, missing("position")
+		}
+		p := in.Position
+		if check == model.CheckPoint3D {
+			result, err = collision.CheckCollision3D(ctx, p.Longitude, p.Latitude, p.Height, *in.CollisionDistance, footprint)
+		} else {
+			result, err = collision.CheckCollision(ctx, p.Longitude, p.Latitude, p.Height, *in.CollisionDistance, footprint)
+		}
+	case model.CheckZones:
+		if in.Position == nil || len(in.Zones) == 0 {
+			return model.AuditOutcome{}, missing("position")
+		}
+		p := in.Position
+		result, err = collision.CheckCollisionZones(ctx, p.Longitude, p.Latitude, p.Height, in.Zones, footprint)
+	case model.CheckLineOfSight:
+		if in.From == nil || in.To == nil {
+			return model.AuditOutcome{}, missing("from/to")
+		}
+		result, err = collision.CheckLineOfSight(ctx, *in.From, *in.To, in.Mode)
+	case model.CheckRoute:
+		if len(in.Route) < 2 || in.CollisionDistance == nil {
+			return model.AuditOutcome{}, missing("route")
+		}
+		result, err = collision.CheckRoute(ctx, in.Route, *in.CollisionDistance, footprint)
+	case model.CheckPredict:
+		if in.Position == nil || in.Heading == nil || in.GroundSpeed == nil || in.LookAhead == nil || in.CollisionDistance == nil {
+			return model.AuditOutcome{}, missing("motion state")
+		}
+		state := MotionState{
+			Longitude:   in.Position.Longitude,
+			Latitude:    in.Position.Latitude,
+			Height:      in.Position.Height,
+			Heading:     *in.Heading,
+			GroundSpeed: *in.GroundSpeed,
+		}
+		if in.VerticalSpeed != nil {
+			state.VerticalSpeed = *in.VerticalSpeed
+		}
+		result, err = collision.PredictCollision(ctx, state, *in.LookAhead, *in.CollisionDistance, footprint)
+	default:
+		return model.AuditOutcome{}, fmt.Errorf("unknown check kind %q", check)
+	}
+	if err != nil {
+		return model.AuditOutcome{}, err
+	}
+	return outcome(result), nil
+}
+
+// difference returns the sorted IDs in a but not in b; both must be sorted.
+func difference(a, b []int64) []int64 {
+	out := []int64{}
+	for _, id := range a {
+		if _, found := slices.BinarySearch(b, id); !found {
+			out = append(out, id)
+		}
+	}
+	return out
+}
@@ -0,0 +1,173 @@
+// internal/service/auth_service.go
+package service
+
+import (
+	"collision_app_go/internal/model"
+	"collision_app_go/internal/repository"
+	"collision_app_go/utils"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// APIKeyPrefix starts every generated API key so that leaked keys are easy to recognise.
+const APIKeyPrefix = "hzdk_"
+
+// ErrUnauthenticated is returned when credentials are missing, malformed or invalid.
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// jwtMethods are the accepted JWT signing algorithms. HMAC is excluded because the
+// JWKS holds public keys only.
+var jwtMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// JWTConfig describes how bearer tokens are verified.
+type JWTConfig struct {
+	Issuer    string        // required iss claim, empty to skip the check
+	Audience  string        // required aud claim, empty to skip the check
+	RoleClaim string        // claim holding the role(s), dotted for nested claims (e.g. realm_access.roles)
+	Leeway    time.Duration // clock skew tolerated for exp/nbf
+}
+
+type AuthService struct {
+	keys   *repository.APIKeyRepository
+	jwks   *JWKS // nil disables JWT authentication
+	jwtCfg JWTConfig
+}
+
+func NewAuthService(keys *repository.APIKeyRepository, jwks *JWKS, jwtCfg JWTConfig) *AuthService {
+	return &AuthService{keys: keys, jwks: jwks, jwtCfg: jwtCfg}
+}
+
+// HashAPIKey returns the hex SHA-256 digest stored for an API key. Keys are random
+// 256-bit values, so a fast unsalted hash is sufficient.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateAPIKey generates and stores a new key. The plaintext key is returned once
+// and cannot be recovered later.
+func (s *AuthService) CreateAPIKey(ctx context.Context, name, role string) (string, *model.APIKey, error) {
+	if name == "" {
+		return "", nil, fmt.Errorf("key name must not be empty")
+	}
+	if !model.ValidRole(role) {
+		return "", nil, fmt.Errorf("invalid role %q: must be %s, %s or %s", role, model.RoleViewer, model.RoleOperator, model.RoleAdmin)
+	}
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	raw := APIKeyPrefix + base64.RawURLEncoding.EncodeToString(b)
+
+	key, err := s.keys.CreateAPIKey(ctx, name, role, HashAPIKey(raw))
+	if err != nil {
+		return "", nil, err
+	}
+	utils.Info(ctx, "service: api key created", "key_id", key.ID, "name", name, "role", role)
+	return raw, key, nil
+}
+
+// ListAPIKeys returns every stored key (without secrets).
+func (s *AuthService) ListAPIKeys(ctx context.Context) ([]model.APIKey, error) {
+	return s.keys.ListAPIKeys(ctx)
+}
+
+// RevokeAPIKey revokes a key by ID.
+func (s *AuthService) RevokeAPIKey(ctx context.Context, id int64) error {
+	return s.keys.RevokeAPIKey(ctx, id)
+}
+
+// AuthenticateAPIKey resolves a plaintext API key to its principal.
+func (s *AuthService) AuthenticateAPIKey(ctx context.Context, raw string) (*model.Principal, error) {
+	key, err := s.keys.GetAPIKeyByHash(ctx, HashAPIKey(raw))
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, fmt.Errorf("%w: unknown or revoked API key", ErrUnauthenticated)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &model.Principal{Subject: key.Name, Role: key.Role, Method: model.AuthMethodAPIKey, KeyID: key.ID}, nil
+}
+
+// AuthenticateToken verifies a JWT against the JWKS and resolves it to a principal.
+// The token must carry an exp claim and a known role in the configured role claim.
+func (s *AuthService) AuthenticateToken(ctx context.Context, raw string) (*model.Principal, error) {
+	if s.jwks == nil {
+		return nil, fmt.Errorf("%w: bearer tokens are not accepted (no JWKS configured)", ErrUnauthenticated)
+	}
+
+	opts := []jwt.ParserOption{jwt.WithValidMethods(jwtMethods), jwt.WithExpirationRequired(), jwt.WithLeeway(s.jwtCfg.Leeway)}
+	if s.jwtCfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(s.jwtCfg.Issuer))
+	}
+	if s.jwtCfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(s.jwtCfg.Audience))
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.NewParser(opts...).ParseWithClaims(raw, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return s.jwks.lookup(kid, t.Method.Alg())
+	})
+	if err != nil {
+		utils.Debug(ctx, "jwt rejected", "error", err)
+		return nil, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+
+	subject, _ := claims.GetSubject()
+	if subject == "" {
+		return nil, fmt.Errorf("%w: token has no sub claim", ErrUnauthenticated)
+	}
+	role := model.HighestRole(claimStrings(claims, s.jwtCfg.RoleClaim))
+	if role == "" {
+		return nil, fmt.Errorf("%w: token has no known role in claim %q", ErrUnauthenticated, s.jwtCfg.RoleClaim)
+	}
+	return &model.Principal{Subject: subject, Role: role, Method: model.AuthMethodJWT}, nil
+}
+
+// claimStrings returns the string or string-array value at a dotted claim path.
+func claimStrings(claims jwt.MapClaims, path string) []string {
+	var v interface{} = map[string]interface{}(claims)
+	for _, part := range strings.Split(path, ".") {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = m[part]
+	}
+	switch v := v.(type) {
+	case string:
+		return strings.Fields(v) // also accepts space-separated scope-style values
+	case []interface{}:
+		var out []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the authenticated caller.
+func WithPrincipal(ctx context.Context, p *model.Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the authenticated caller carried by ctx, if any.
+func PrincipalFromContext(ctx context.Context) *model.Principal {
+	p, _ := ctx.Value(principalKey{}).(*model.Principal)
+	return p
+}
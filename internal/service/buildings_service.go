@@ -2,10 +2,13 @@
 package service
 
 import (
+	"collision_app_go/internal/apperr"
 	"collision_app_go/utils"
 	"context"
-	"fmt"
+	"errors"
+	"time"
 
+	"collision_app_go/internal/model"
 	"collision_app_go/internal/repository"
 )
 
@@ -17,36 +20,71 @@ func NewBuildingsService(repo *repository.BuildingRepository) *BuildingsService
 	return &BuildingsService{repo: repo}
 }
 
+// AsOf returns a service that exports the buildings as they were at t.
+func (s *BuildingsService) AsOf(t time.Time) *BuildingsService {
+	return &BuildingsService{repo: s.repo.AsOf(t)}
+}
+
 // InsertBuildings handles the logic for inserting buildings from a file.
-func (s *BuildingsService) InsertBuildings(ctx context.Context, filePath string) (map[string]interface{}, error) {
-	utils.Infof("Service: Inserting buildings from file: %s", filePath)
+// progress, if non-nil, receives the number of lines processed so far.
+func (s *BuildingsService) InsertBuildings(ctx context.Context, filePath string, opts model.ImportOptions, progress repository.ProgressFunc) (*model.ImportResult, error) {
+	utils.Info(ctx, "service: inserting buildings from file", "file_path", filePath)
 
-	result, err := s.repo.InsertBuildingsFromFile(ctx, filePath)
+	result, err := s.repo.InsertBuildingsFromFile(ctx, filePath, importOptions(opts), progress)
 	if err != nil {
-		utils.Errorf("Service error during insert: %v", err)
-		// Return a standardized error response
-		return map[string]interface{}{
-			"success":  false,
-			"code":     501,
-			"errorMsg": fmt.Sprintf("导入时发生异常: %v", err),
-		}, nil // Return the error *response* as data
+		utils.Error(ctx, "service error during insert", "error", err)
+		return nil, importError(err)
 	}
 	return result, nil
 }
 
+// DryRunImport reports what InsertBuildings would do with a file, without writing to
+// hzdk_buildings.
+func (s *BuildingsService) DryRunImport(ctx context.Context, filePath string, opts model.ImportOptions, progress repository.ProgressFunc) (*model.ImportReport, error) {
+	utils.Info(ctx, "service: dry-running import from file", "file_path", filePath)
+
+	report, err := s.repo.DryRunBuildingsFromFile(ctx, filePath, importOptions(opts), progress)
+	if err != nil {
+		utils.Error(ctx, "service error during dry run", "error", err)
+		return nil, importError(err)
+	}
+	return report, nil
+}
+
+// importOptions fills in the defaults of opts.
+func importOptions(opts model.ImportOptions) model.ImportOptions {
+	if opts.InvalidGeometry == "" {
+		opts.InvalidGeometry = model.InvalidGeometryReject
+	}
+	return opts
+}
+
+func importError(err error) error {
+	if errors.Is(err, repository.ErrUnreadableFile) {
+		return &apperr.Error{Code: apperr.CodeImportFileUnreadable, Err: err}
+	}
+	return apperr.Wrap(apperr.CodeImportFailed, err)
+}
+
+// ExportBuildings streams the buildings matching filter to fn.
+func (s *BuildingsService) ExportBuildings(ctx context.Context, filter model.BuildingFilter, fn func(model.BuildingRecord) error) error {
+	utils.Info(ctx, "service: exporting buildings", "area_code", filter.AreaCode, "type", filter.Type)
+
+	if err := s.repo.ExportBuildings(ctx, filter, fn); err != nil {
+		utils.Error(ctx, "service error during export", "error", err)
+		return apperr.Wrap(apperr.CodeExportFailed, err)
+	}
+	return nil
+}
+
 // UpdateBuildings handles the logic for updating all buildings.
-func (s *BuildingsService) UpdateBuildings(ctx context.Context) (map[string]interface{}, error) {
-	utils.Info("Service: Updating all buildings info...")
+func (s *BuildingsService) UpdateBuildings(ctx context.Context) (*model.UpdateResult, error) {
+	utils.Info(ctx, "service: updating all buildings info")
 
 	result, err := s.repo.UpdateAllBuildingsInfoBatch(ctx)
 	if err != nil {
-		utils.Errorf("Service error during update: %v", err)
-		// Return a standardized error response
-		return map[string]interface{}{
-			"success":  false,
-			"code":     500,
-			"errorMsg": fmt.Sprintf("更新时发生错误: %v", err),
-		}, nil // Return the error *response* as data
+		utils.Error(ctx, "service error during update", "error", err)
+		return nil, apperr.Wrap(apperr.CodeUpdateFailed, err)
 	}
 	return result, nil
 }
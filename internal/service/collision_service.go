@@ -2,38 +2,370 @@
 package service
 
 import (
+	"collision_app_go/internal/apperr"
+	"collision_app_go/internal/metrics"
+	"collision_app_go/internal/model"
 	"collision_app_go/internal/repository"
 	"collision_app_go/utils"
 	"context"
+	"math"
+	"time"
 )
 
 type CollisionService struct {
-	repo *repository.BuildingRepository
+	repo    *repository.BuildingRepository
+	zones   []model.WarningZone // default warning zones, most severe first
+	auditor Auditor             // nil disables the audit log
+	asOf    *time.Time          // set by AsOf
 }
 
-func NewCollisionService(repo *repository.BuildingRepository) *CollisionService {
-	return &CollisionService{repo: repo}
+func NewCollisionService(repo *repository.BuildingRepository, zones []model.WarningZone, auditor Auditor) *CollisionService {
+	return &CollisionService{repo: repo, zones: zones, auditor: auditor}
+}
+
+// AsOf returns a service that checks against the buildings as they were at t, to
+// reconstruct what obstacles existed at the time of an incident.
+func (s *CollisionService) AsOf(t time.Time) *CollisionService {
+	c := *s
+	c.repo = s.repo.AsOf(t)
+	c.asOf = &t
+	return &c
 }
 
 // CheckCollision checks if a point collides with any buildings.
-func (s *CollisionService) CheckCollision(ctx context.Context, longitude, latitude, height, collisionDistance float64) (map[string]interface{}, error) {
-	utils.Infof("Checking collision for point: lon=%f, lat=%f, height=%f, distance=%f", longitude, latitude, height, collisionDistance)
+// The query volume is inflated by the drone's footprint and position uncertainty.
+func (s *CollisionService) CheckCollision(ctx context.Context, longitude, latitude, height, collisionDistance float64, footprint model.Footprint) (result *model.CollisionResult, err error) {
+	defer func(start time.Time) {
+		s.audit(ctx, model.CheckPoint, start, model.AuditInput{
+			Position:          &model.Point3D{Longitude: longitude, Latitude: latitude, Height: height},
+			CollisionDistance: &collisionDistance,
+			Footprint:         &footprint,
+		}, result, err)
+	}(time.Now())
+
+	utils.Info(ctx, "checking collision for point",
+		"lon", longitude, "lat", latitude, "height", height, "distance", collisionDistance, "footprint", footprint)
 
-	buildings, err := s.repo.GetCollisionBuildingsInfo(ctx, longitude, latitude, height, collisionDistance)
+	margin := footprint.Breakdown(collisionDistance, 0)
+	buildings, err := s.repo.GetCollisionBuildingsInfo(ctx, longitude, latitude, height-margin.TotalVertical, margin.TotalHorizontal)
 	if err != nil {
-		return nil, err // Propagate error
+		return nil, apperr.Wrap(apperr.CodeCollisionCheckFailed, err)
 	}
 
 	isCollision := len(buildings) > 0
+	metrics.ObserveCollisionCheck(model.CheckPoint, isCollision)
+
+	return &model.CollisionResult{
+		Mode:          model.Mode25D,
+		IsCollision:   isCollision,
+		Margin:        margin,
+		BuildingInfos: buildings,
+	}, nil
+}
+
+// CheckCollision3D checks a point against the extruded building part solids using the
+// true 3D distance, so setbacks, towers on podiums and overhangs are honoured.
+// Building parts keep no history, so it is not available on a service from AsOf.
+func (s *CollisionService) CheckCollision3D(ctx context.Context, longitude, latitude, height, collisionDistance float64, footprint model.Footprint) (result *model.SolidCollisionResult, err error) {
+	if s.asOf != nil {
+		return nil, apperr.InvalidArgument("mode", "not_with", "as_of")
+	}
+	defer func(start time.Time) {
+		s.audit(ctx, model.CheckPoint3D, start, model.AuditInput{
+			Position:          &model.Point3D{Longitude: longitude, Latitude: latitude, Height: height},
+			CollisionDistance: &collisionDistance,
+			Footprint:         &footprint,
+		}, result, err)
+	}(time.Now())
+
+	utils.Info(ctx, "checking 3D collision for point",
+		"lon", longitude, "lat", latitude, "height", height, "distance", collisionDistance, "footprint", footprint)
+
+	margin := footprint.Breakdown(collisionDistance, 0)
+	vertical := footprint.VerticalMargin()
+	hits, err := s.repo.GetCollisionSolids(ctx, longitude, latitude, height-vertical, height+vertical, margin.TotalHorizontal)
+	if err != nil {
+		return nil, apperr.Wrap(apperr.CodeCollisionCheckFailed, err)
+	}
+
+	isCollision := len(hits) > 0
+	metrics.ObserveCollisionCheck(model.CheckPoint3D, isCollision)
+
+	return &model.SolidCollisionResult{
+		Mode:          model.Mode3D,
+		IsCollision:   isCollision,
+		Margin:        margin,
+		BuildingInfos: hits,
+	}, nil
+}
+
+// CheckCollisionZones classifies the buildings around a point into tiered warning zones.
+// If zones is empty the service's default zones are used. Each building is listed only
+// under the most severe zone it falls in. Every zone is inflated by the drone's footprint.
+func (s *CollisionService) CheckCollisionZones(ctx context.Context, longitude, latitude, height float64, zones []model.WarningZone, footprint model.Footprint) (result *model.ZonesResult, err error) {
+	if len(zones) == 0 {
+		zones = s.zones
+	}
+	defer func(start time.Time, zones []model.WarningZone) {
+		s.audit(ctx, model.CheckZones, start, model.AuditInput{
+			Position:  &model.Point3D{Longitude: longitude, Latitude: latitude, Height: height},
+			Footprint: &footprint,
+			Zones:     zones,
+		}, result, err)
+	}(time.Now(), zones)
+	utils.Info(ctx, "checking collision zones for point",
+		"lon", longitude, "lat", latitude, "height", height, "zones", zones, "footprint", footprint)
+
+	margins := make(map[string]model.MarginBreakdown, len(zones))
+	inflated := make([]model.WarningZone, len(zones))
+	for i, z := range zones {
+		margins[z.Level] = footprint.Breakdown(z.Horizontal, z.Vertical)
+		inflated[i] = z.Inflate(footprint)
+	}
+	zones = inflated
 
-	response := map[string]interface{}{
-		"status":       "success",
-		"is_collision": isCollision,
+	maxHorizontal, maxVertical := 0.0, 0.0
+	for _, z := range zones {
+		maxHorizontal = math.Max(maxHorizontal, z.Horizontal)
+		maxVertical = math.Max(maxVertical, z.Vertical)
+	}
+
+	buildings, err := s.repo.GetBuildingsWithinDistance(ctx, longitude, latitude, height-maxVertical, maxHorizontal)
+	if err != nil {
+		return nil, apperr.Wrap(apperr.CodeZoneCheckFailed, err)
+	}
+
+	byLevel := make(map[string][]model.BuildingDistance, len(zones))
+	for _, z := range zones {
+		byLevel[z.Level] = []model.BuildingDistance{}
+	}
+
+	highest := ""
+	highestSeverity := 0
+	for _, b := range buildings {
+		if b.BuildingHeight == nil {
+			continue
+		}
+		for _, z := range zones {
+			if z.Contains(b.Distance, height, *b.BuildingHeight) {
+				byLevel[z.Level] = append(byLevel[z.Level], b)
+				if z.Severity() > highestSeverity {
+					highest, highestSeverity = z.Level, z.Severity()
+				}
+				break // zones are ordered most severe first
+			}
+		}
+	}
+
+	metrics.ObserveCollisionCheck(model.CheckZones, highest != "")
+
+	return &model.ZonesResult{
+		IsCollision: highest == model.ZoneCritical,
+		Level:       highest,
+		Zones:       zones,
+		Margins:     margins,
+		Buildings:   byLevel,
+	}, nil
+}
+
+// CheckLineOfSight checks whether the straight line between two 3D points is blocked by
+// any building. In Mode3D the per-part solids are used; otherwise each footprint is
+// extruded to its building_height. Each blocking building is reported with the points
+// where the line enters and leaves its volume. Mode3D is not available on a service
+// from AsOf, as building parts keep no history.
+func (s *CollisionService) CheckLineOfSight(ctx context.Context, from, to model.Point3D, mode string) (result *model.LineOfSightResult, err error) {
+	if mode == model.Mode3D && s.asOf != nil {
+		return nil, apperr.InvalidArgument("mode", "not_with", "as_of")
+	}
+	defer func(start time.Time) {
+		s.audit(ctx, model.CheckLineOfSight, start, model.AuditInput{From: &from, To: &to, Mode: mode}, result, err)
+	}(time.Now())
+
+	utils.Info(ctx, "checking line of sight", "mode", mode, "from", from, "to", to)
+
+	spans, err := s.repo.GetRaySpans(ctx, from.Longitude, from.Latitude, to.Longitude, to.Latitude,
+		math.Min(from.Height, to.Height), math.Max(from.Height, to.Height), mode)
+	if err != nil {
+		return nil, apperr.Wrap(apperr.CodeLineOfSightFailed, err)
+	}
+
+	length := math.Hypot(utils.Haversine(from.Longitude, from.Latitude, to.Longitude, to.Latitude), to.Height-from.Height)
+	rise := to.Height - from.Height
+
+	blocks := []model.LineOfSightBlock{}
+	for _, span := range spans {
+		// Clip the footprint span to the part where the ray is between base and top.
+		enter, exit := span.From, span.To
+		if rise == 0 {
+			if from.Height < span.Base || from.Height >= span.Top {
+				continue
+			}
+		} else {
+			a, b := (span.Base-from.Height)/rise, (span.Top-from.Height)/rise
+			enter = math.Max(enter, math.Min(a, b))
+			exit = math.Min(exit, math.Max(a, b))
+		}
+		if enter > exit {
+			continue
+		}
+
+		blocks = append(blocks, model.LineOfSightBlock{
+			Building:      span.Building,
+			PartID:        span.PartID,
+			Entry:         from.Lerp(to, enter),
+			Exit:          from.Lerp(to, exit),
+			EntryDistance: length * enter,
+			ExitDistance:  length * exit,
+		})
+	}
+
+	metrics.ObserveCollisionCheck(model.CheckLineOfSight, len(blocks) > 0)
+
+	return &model.LineOfSightResult{
+		Mode:              mode,
+		IsVisible:         len(blocks) == 0,
+		Distance:          length,
+		BlockingBuildings: blocks,
+	}, nil
+}
+
+// CheckRoute checks each leg of a 3D route. A leg collides with a building whose
+// footprint is within the horizontal margin of the leg and whose roof reaches above
+// the lower end of the leg minus the vertical margin, as in the 2.5D point check.
+func (s *CollisionService) CheckRoute(ctx context.Context, route []model.Point3D, collisionDistance float64, footprint model.Footprint) (result *model.RouteResult, err error) {
+	defer func(start time.Time) {
+		s.audit(ctx, model.CheckRoute, start, model.AuditInput{
+			Route:             route,
+			CollisionDistance: &collisionDistance,
+			Footprint:         &footprint,
+		}, result, err)
+	}(time.Now())
+
+	utils.Info(ctx, "checking route", "waypoints", len(route), "distance", collisionDistance, "footprint", footprint)
+
+	margin := footprint.Breakdown(collisionDistance, 0)
+	legs := []model.RouteLeg{}
+	for i := 0; i+1 < len(route); i++ {
+		from, to := route[i], route[i+1]
+		wkt := model.LineStringWKT([]model.Waypoint{
+			{Longitude: from.Longitude, Latitude: from.Latitude},
+			{Longitude: to.Longitude, Latitude: to.Latitude},
+		})
+		buildings, err := s.repo.GetBuildingsAlongRoute(ctx, wkt, math.Min(from.Height, to.Height)-margin.TotalVertical, margin.TotalHorizontal)
+		if err != nil {
+			return nil, apperr.Wrap(apperr.CodeCollisionCheckFailed, err)
+		}
+		if len(buildings) > 0 {
+			legs = append(legs, model.RouteLeg{Index: i, Buildings: buildings})
+		}
+	}
+
+	metrics.ObserveCollisionCheck(model.CheckRoute, len(legs) > 0)
+
+	return &model.RouteResult{
+		IsCollision: len(legs) > 0,
+		Margin:      margin,
+		Legs:        legs,
+	}, nil
+}
+
+// Trajectory sampling limits for PredictCollision.
+const (
+	maxSampleInterval = 1.0  // seconds between samples at most
+	maxTrajectorySize = 1000 // upper bound on samples sent to the database
+)
+
+// MotionState describes a drone's current position and velocity.
+type MotionState struct {
+	Longitude     float64
+	Latitude      float64
+	Height        float64
+	Heading       float64 // degrees clockwise from north
+	GroundSpeed   float64 // meters per second
+	VerticalSpeed float64 // meters per second, positive is climbing
+}
+
+// PredictCollision projects the drone's trajectory forward for lookAhead seconds and
+// reports the time to the first collision with a building, if any. The corridor
+// around the trajectory is inflated by the footprint as in CheckCollision.
+func (s *CollisionService) PredictCollision(ctx context.Context, state MotionState, lookAhead, collisionDistance float64, footprint model.Footprint) (result *model.PredictionResult, err error) {
+	defer func(start time.Time) {
+		s.audit(ctx, model.CheckPredict, start, model.AuditInput{
+			Position:          &model.Point3D{Longitude: state.Longitude, Latitude: state.Latitude, Height: state.Height},
+			Heading:           &state.Heading,
+			GroundSpeed:       &state.GroundSpeed,
+			VerticalSpeed:     &state.VerticalSpeed,
+			LookAhead:         &lookAhead,
+			CollisionDistance: &collisionDistance,
+			Footprint:         &footprint,
+		}, result, err)
+	}(time.Now())
+
+	utils.Info(ctx, "predicting collision for point",
+		"state", state, "look_ahead", lookAhead, "distance", collisionDistance, "footprint", footprint)
+
+	samples := ProjectTrajectory(state, lookAhead)
+
+	margin := footprint.Breakdown(collisionDistance, 0)
+	hits, err := s.repo.GetTrajectoryCollisions(ctx, samples, margin.TotalHorizontal, margin.TotalVertical)
+	if err != nil {
+		return nil, apperr.Wrap(apperr.CodePredictionFailed, err)
+	}
+
+	isCollision := len(hits) > 0
+	metrics.ObserveCollisionCheck(model.CheckPredict, isCollision)
+
+	result = &model.PredictionResult{
+		IsCollision: isCollision,
+		LookAhead:   lookAhead,
+		Margin:      margin,
 	}
 
 	if isCollision {
-		response["building_infos"] = buildings
+		first := hits[0]
+		climb := recommendedClimbAltitude(hits, collisionDistance+footprint.VerticalMargin())
+		result.TimeToCollision = &first.At.T
+		result.FirstObstacle = &first.Building
+		result.CollisionPoint = &first.At
+		result.Obstacles = hits
+		result.RecommendedClimbAltitude = &climb
 	}
 
-	return response, nil
+	return result, nil
+}
+
+// ProjectTrajectory samples the straight-line trajectory of a drone over the look-ahead
+// horizon. The samples are the vertices of the path checked against buildings leg by
+// leg, so the interval only sets the time resolution of the reported hits.
+func ProjectTrajectory(state MotionState, lookAhead float64) []model.TrajectorySample {
+	interval := maxSampleInterval
+	if lookAhead/interval > maxTrajectorySize {
+		interval = lookAhead / maxTrajectorySize
+	}
+
+	steps := int(math.Ceil(lookAhead / interval))
+	samples := make([]model.TrajectorySample, 0, steps+1)
+	for i := 0; i <= steps; i++ {
+		t := math.Min(float64(i)*interval, lookAhead)
+		lon, lat := utils.Destination(state.Longitude, state.Latitude, state.Heading, state.GroundSpeed*t)
+		samples = append(samples, model.TrajectorySample{
+			T:         t,
+			Longitude: lon,
+			Latitude:  lat,
+			Height:    state.Height + state.VerticalSpeed*t,
+		})
+	}
+	return samples
+}
+
+// recommendedClimbAltitude returns the altitude that clears every obstacle on the
+// projected path by the given vertical margin.
+func recommendedClimbAltitude(hits []model.TrajectoryHit, margin float64) float64 {
+	highest := 0.0
+	for _, h := range hits {
+		if h.Building.BuildingHeight != nil && *h.Building.BuildingHeight > highest {
+			highest = *h.Building.BuildingHeight
+		}
+	}
+	return highest + margin
 }
@@ -0,0 +1,261 @@
+// internal/service/deconfliction.go
+package service
+
+import (
+	"collision_app_go/internal/model"
+	"collision_app_go/utils"
+	"math"
+	"sort"
+	"time"
+)
+
+// DeconflictionConfig holds the separation minima used for strategic 4D deconfliction.
+type DeconflictionConfig struct {
+	HorizontalSeparation float64       // meters
+	VerticalSeparation   float64       // meters
+	TimeBuffer           time.Duration // padding applied to each volume's time window
+	SliceDuration        time.Duration // duration of each space-time volume
+	MaxTimeShift         time.Duration // largest shift tried when suggesting a fix
+	MaxPlanDuration      time.Duration // longest plan accepted, from start to end time
+	MaxPlanWaypoints     int           // most waypoints accepted in a plan's route
+}
+
+// DefaultDeconflictionConfig returns the default separation minima.
+func DefaultDeconflictionConfig() DeconflictionConfig {
+	return DeconflictionConfig{
+		HorizontalSeparation: 50,
+		VerticalSeparation:   10,
+		TimeBuffer:           30 * time.Second,
+		SliceDuration:        10 * time.Second,
+		MaxTimeShift:         30 * time.Minute,
+		MaxPlanDuration:      4 * time.Hour,
+		MaxPlanWaypoints:     500,
+	}
+}
+
+// DiscretizePlan splits a flight plan into consecutive space-time volumes of at most
+// sliceDuration each. The drone is assumed to fly the route at constant ground speed
+// so that it leaves the first waypoint at StartTime and reaches the last at EndTime.
+func DiscretizePlan(plan model.FlightPlan, sliceDuration time.Duration) []model.SpaceTimeVolume {
+	if len(plan.Route) == 0 || sliceDuration <= 0 {
+		return nil
+	}
+
+	legs := make([]float64, len(plan.Route)-1)
+	total := 0.0
+	for i := range legs {
+		a, b := plan.Route[i], plan.Route[i+1]
+		legs[i] = utils.Haversine(a.Longitude, a.Latitude, b.Longitude, b.Latitude)
+		total += legs[i]
+	}
+
+	duration := plan.EndTime.Sub(plan.StartTime)
+	positionAt := func(offset time.Duration) model.Waypoint {
+		if duration <= 0 || total == 0 {
+			return plan.Route[0]
+		}
+		along := total * float64(offset) / float64(duration)
+		for i, leg := range legs {
+			if along <= leg || i == len(legs)-1 {
+				f := 0.0
+				if leg > 0 {
+					f = math.Min(along/leg, 1)
+				}
+				a, b := plan.Route[i], plan.Route[i+1]
+				return model.Waypoint{
+					Longitude: a.Longitude + (b.Longitude-a.Longitude)*f,
+					Latitude:  a.Latitude + (b.Latitude-a.Latitude)*f,
+				}
+			}
+			along -= leg
+		}
+		return plan.Route[len(plan.Route)-1]
+	}
+
+	var volumes []model.SpaceTimeVolume
+	for offset := time.Duration(0); offset < duration || len(volumes) == 0; offset += sliceDuration {
+		end := offset + sliceDuration
+		if end > duration {
+			end = duration
+		}
+		volumes = append(volumes, model.SpaceTimeVolume{
+			T0:          plan.StartTime.Add(offset),
+			T1:          plan.StartTime.Add(end),
+			From:        positionAt(offset),
+			To:          positionAt(end),
+			AltitudeMin: plan.AltitudeMin,
+			AltitudeMax: plan.AltitudeMax,
+		})
+	}
+	return volumes
+}
+
+// FindConflicts compares the volumes of a plan against another plan's volumes and
+// returns the losses of separation, with overlapping time windows merged. Both
+// slices must be ordered by T0 with consecutive, non-overlapping windows, as
+// DiscretizePlan returns them, so that only volumes close in time are compared.
+func FindConflicts(volumes []model.SpaceTimeVolume, other model.FlightPlan, otherVolumes []model.SpaceTimeVolume, cfg DeconflictionConfig) []model.PlanConflict {
+	var conflicts []model.PlanConflict
+	first := 0 // the first of otherVolumes not ending before the current volume
+	for _, a := range volumes {
+		for first < len(otherVolumes) && !a.T0.Add(-cfg.TimeBuffer).Before(otherVolumes[first].T1) {
+			first++
+		}
+		for _, b := range otherVolumes[first:] {
+			if !b.T0.Add(-cfg.TimeBuffer).Before(a.T1) {
+				break
+			}
+			vertical := math.Max(0, math.Max(a.AltitudeMin, b.AltitudeMin)-math.Min(a.AltitudeMax, b.AltitudeMax))
+			if vertical >= cfg.VerticalSeparation {
+				continue
+			}
+			horizontal, location := segmentSeparation(a, b)
+			if horizontal >= cfg.HorizontalSeparation {
+				continue
+			}
+
+			start, end := maxTime(a.T0, b.T0), minTime(a.T1, b.T1)
+			if end.Before(start) {
+				start, end = end, start // only within the time buffer
+			}
+			conflicts = append(conflicts, model.PlanConflict{
+				PlanID:               other.ID,
+				DroneID:              other.DroneID,
+				PlanStatus:           other.Status,
+				Start:                start,
+				End:                  end,
+				Location:             location,
+				HorizontalSeparation: horizontal,
+				VerticalSeparation:   vertical,
+			})
+		}
+	}
+	return mergeConflicts(conflicts)
+}
+
+// mergeConflicts joins conflicts with the same plan whose time windows touch,
+// keeping the location of the smallest horizontal separation.
+func mergeConflicts(conflicts []model.PlanConflict) []model.PlanConflict {
+	sort.Slice(conflicts, func(i, j int) bool {
+		if conflicts[i].PlanID != conflicts[j].PlanID {
+			return conflicts[i].PlanID < conflicts[j].PlanID
+		}
+		return conflicts[i].Start.Before(conflicts[j].Start)
+	})
+
+	var merged []model.PlanConflict
+	for _, c := range conflicts {
+		if n := len(merged); n > 0 && merged[n-1].PlanID == c.PlanID && !c.Start.After(merged[n-1].End) {
+			last := &merged[n-1]
+			last.End = maxTime(last.End, c.End)
+			last.VerticalSeparation = math.Min(last.VerticalSeparation, c.VerticalSeparation)
+			if c.HorizontalSeparation < last.HorizontalSeparation {
+				last.HorizontalSeparation = c.HorizontalSeparation
+				last.Location = c.Location
+			}
+			continue
+		}
+		merged = append(merged, c)
+	}
+	return merged
+}
+
+// SuggestTimeShift returns the smallest shift, tried in SliceDuration steps up to
+// MaxTimeShift with later departures preferred, that clears every conflict.
+func SuggestTimeShift(volumes []model.SpaceTimeVolume, others []model.FlightPlan, otherVolumes [][]model.SpaceTimeVolume, cfg DeconflictionConfig) (time.Duration, bool) {
+	shifted := make([]model.SpaceTimeVolume, len(volumes))
+	clear := func(d time.Duration) bool {
+		for i, v := range volumes {
+			shifted[i] = v.Shift(d)
+		}
+		for i, other := range others {
+			if len(FindConflicts(shifted, other, otherVolumes[i], cfg)) > 0 {
+				return false
+			}
+		}
+		return true
+	}
+
+	for d := cfg.SliceDuration; d <= cfg.MaxTimeShift; d += cfg.SliceDuration {
+		if clear(d) {
+			return d, true
+		}
+		if clear(-d) {
+			return -d, true
+		}
+	}
+	return 0, false
+}
+
+// segmentSeparation returns the horizontal distance in meters between the route
+// segments of two volumes and the point on a's segment closest to b.
+func segmentSeparation(a, b model.SpaceTimeVolume) (float64, model.Waypoint) {
+	refLon, refLat := a.From.Longitude, a.From.Latitude
+	ax1, ay1 := utils.ToLocal(refLon, refLat, a.From.Longitude, a.From.Latitude)
+	ax2, ay2 := utils.ToLocal(refLon, refLat, a.To.Longitude, a.To.Latitude)
+	bx1, by1 := utils.ToLocal(refLon, refLat, b.From.Longitude, b.From.Latitude)
+	bx2, by2 := utils.ToLocal(refLon, refLat, b.To.Longitude, b.To.Latitude)
+
+	best, bx, by := math.Inf(1), 0.0, 0.0
+	consider := func(d, x, y float64) {
+		if d < best {
+			best, bx, by = d, x, y
+		}
+	}
+
+	if x, y, ok := segmentIntersection(ax1, ay1, ax2, ay2, bx1, by1, bx2, by2); ok {
+		consider(0, x, y)
+	} else {
+		// Closest points are at an endpoint of one of the segments.
+		x, y := closestOnSegment(bx1, by1, ax1, ay1, ax2, ay2)
+		consider(math.Hypot(x-bx1, y-by1), x, y)
+		x, y = closestOnSegment(bx2, by2, ax1, ay1, ax2, ay2)
+		consider(math.Hypot(x-bx2, y-by2), x, y)
+		x, y = closestOnSegment(ax1, ay1, bx1, by1, bx2, by2)
+		consider(math.Hypot(x-ax1, y-ay1), ax1, ay1)
+		x, y = closestOnSegment(ax2, ay2, bx1, by1, bx2, by2)
+		consider(math.Hypot(x-ax2, y-ay2), ax2, ay2)
+	}
+
+	lon, lat := utils.FromLocal(refLon, refLat, bx, by)
+	return best, model.Waypoint{Longitude: lon, Latitude: lat}
+}
+
+// closestOnSegment returns the point of segment (x1,y1)-(x2,y2) closest to (px,py).
+func closestOnSegment(px, py, x1, y1, x2, y2 float64) (float64, float64) {
+	dx, dy := x2-x1, y2-y1
+	lengthSq := dx*dx + dy*dy
+	if lengthSq == 0 {
+		return x1, y1
+	}
+	t := math.Max(0, math.Min(1, ((px-x1)*dx+(py-y1)*dy)/lengthSq))
+	return x1 + t*dx, y1 + t*dy
+}
+
+// segmentIntersection returns the intersection point of two segments, if any.
+func segmentIntersection(x1, y1, x2, y2, x3, y3, x4, y4 float64) (float64, float64, bool) {
+	denom := (x2-x1)*(y4-y3) - (y2-y1)*(x4-x3)
+	if denom == 0 {
+		return 0, 0, false // parallel or degenerate; handled by the endpoint checks
+	}
+	t := ((x3-x1)*(y4-y3) - (y3-y1)*(x4-x3)) / denom
+	u := ((x3-x1)*(y2-y1) - (y3-y1)*(x2-x1)) / denom
+	if t < 0 || t > 1 || u < 0 || u > 1 {
+		return 0, 0, false
+	}
+	return x1 + t*(x2-x1), y1 + t*(y2-y1), true
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
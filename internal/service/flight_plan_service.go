@@ -0,0 +1,290 @@
+// internal/service/flight_plan_service.go
+package service
+
+import (
+	"collision_app_go/internal/apperr"
+	"collision_app_go/internal/model"
+	"collision_app_go/internal/repository"
+	"collision_app_go/utils"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// systemActor is recorded in the plan history for automatic assessments.
+const systemActor = "system"
+
+type FlightPlanService struct {
+	plans     *repository.FlightPlanRepository
+	buildings *repository.BuildingRepository
+	deconf    DeconflictionConfig
+}
+
+func NewFlightPlanService(plans *repository.FlightPlanRepository, buildings *repository.BuildingRepository, deconf DeconflictionConfig) *FlightPlanService {
+	return &FlightPlanService{plans: plans, buildings: buildings, deconf: deconf}
+}
+
+// SubmitFlightPlan validates a new plan against obstacle data, assigns it a status and stores it.
+// buffer is the horizontal and vertical clearance, in meters, required around obstacles.
+// Deconfliction and storage are serialized with other approvals, so that plans
+// submitted together cannot both be approved when they conflict with each other.
+func (s *FlightPlanService) SubmitFlightPlan(ctx context.Context, plan model.FlightPlan, buffer float64) (*model.FlightPlanSubmission, error) {
+	utils.Info(ctx, "service: submitting flight plan", "drone_id", plan.DroneID, "waypoints", len(plan.Route))
+
+	if len(plan.Route) > s.deconf.MaxPlanWaypoints {
+		return nil, apperr.InvalidArgument("route", "max_items", s.deconf.MaxPlanWaypoints)
+	}
+	if plan.EndTime.Sub(plan.StartTime) > s.deconf.MaxPlanDuration {
+		return nil, apperr.InvalidArgument("end_time", "max", "start_time + "+s.deconf.MaxPlanDuration.String())
+	}
+
+	status, reasons, err := s.assess(ctx, plan, buffer)
+	if err != nil {
+		return nil, planError(err)
+	}
+
+	var conflicts []model.PlanConflict
+	var shift *time.Duration
+	err = s.plans.LockPlanApproval(ctx, func() error {
+		var err error
+		conflicts, shift, err = s.deconflict(ctx, plan, true)
+		if err != nil {
+			return err
+		}
+		if len(conflicts) > 0 && status == model.PlanApproved {
+			status = model.PlanNeedsReview
+			reasons = reasons[:0]
+		}
+		for _, c := range conflicts {
+			reasons = append(reasons, conflictReason(c))
+		}
+		plan.Status = status
+		plan.Reasons = reasons
+		return s.plans.CreateFlightPlan(ctx, &plan, systemActor)
+	})
+	if err != nil {
+		return nil, planError(err)
+	}
+	utils.Info(ctx, "service: flight plan assessed", "plan_id", plan.ID, "drone_id", plan.DroneID, "plan_status", plan.Status, "conflicts", len(conflicts))
+
+	return &model.FlightPlanSubmission{
+		Plan:               plan,
+		Conflicts:          conflicts,
+		SuggestedTimeShift: shiftSeconds(shift),
+	}, nil
+}
+
+// CheckFlightPlanConflicts runs 4D deconfliction of a stored plan against all other
+// active plans. When suggestShift is set, a departure time shift clearing every
+// conflict is searched for.
+func (s *FlightPlanService) CheckFlightPlanConflicts(ctx context.Context, id int64, suggestShift bool) (*model.FlightPlanConflicts, error) {
+	plan, err := s.plans.GetFlightPlan(ctx, id)
+	if err != nil {
+		return nil, planError(err)
+	}
+
+	conflicts, shift, err := s.deconflict(ctx, *plan, suggestShift)
+	if err != nil {
+		return nil, planError(err)
+	}
+
+	return &model.FlightPlanConflicts{
+		PlanID:             plan.ID,
+		IsConflict:         len(conflicts) > 0,
+		Conflicts:          conflicts,
+		SuggestedTimeShift: shiftSeconds(shift),
+	}, nil
+}
+
+// conflictReason describes a conflict in a plan's reasons.
+func conflictReason(c model.PlanConflict) string {
+	return fmt.Sprintf("conflicts with plan %d (drone %s) from %s to %s, separation %.1fm horizontal / %.1fm vertical",
+		c.PlanID, c.DroneID, c.Start.Format(time.RFC3339), c.End.Format(time.RFC3339), c.HorizontalSeparation, c.VerticalSeparation)
+}
+
+// shiftSeconds converts an optional time shift to seconds.
+func shiftSeconds(shift *time.Duration) *float64 {
+	if shift == nil {
+		return nil
+	}
+	seconds := shift.Seconds()
+	return &seconds
+}
+
+// CheckGeofence compares a drone position at time at with the approved flight plan
+// the drone is flying: the position must lie within corridor meters of the route
+// and between the plan's altitude limits. It returns nil when the drone has no
+// active approved plan.
+func (s *FlightPlanService) CheckGeofence(ctx context.Context, droneID string, at time.Time, pos model.Point3D, corridor float64) (*model.GeofenceStatus, error) {
+	plan, distance, err := s.plans.GetActivePlanDistance(ctx, droneID, at, pos.Longitude, pos.Latitude)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, planError(err)
+	}
+
+	status := &model.GeofenceStatus{
+		PlanID:      plan.ID,
+		Distance:    distance,
+		Corridor:    corridor,
+		Height:      pos.Height,
+		AltitudeMin: plan.AltitudeMin,
+		AltitudeMax: plan.AltitudeMax,
+		Reasons:     []string{},
+	}
+	if distance > corridor {
+		status.Reasons = append(status.Reasons, fmt.Sprintf("%.1fm from the planned route, corridor is %.1fm", distance, corridor))
+	}
+	if pos.Height < plan.AltitudeMin {
+		status.Reasons = append(status.Reasons, fmt.Sprintf("height %.1fm below the plan minimum of %.1fm", pos.Height, plan.AltitudeMin))
+	}
+	if pos.Height > plan.AltitudeMax {
+		status.Reasons = append(status.Reasons, fmt.Sprintf("height %.1fm above the plan maximum of %.1fm", pos.Height, plan.AltitudeMax))
+	}
+	status.Inside = len(status.Reasons) == 0
+	return status, nil
+}
+
+// planError classifies a repository error for the API.
+func planError(err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return &apperr.Error{Code: apperr.CodeFlightPlanNotFound, Err: err}
+	}
+	return apperr.Wrap(apperr.CodeFlightPlanFailed, err)
+}
+
+// deconflict compares the plan against active plans overlapping it in space and time.
+// If suggestShift is set and conflicts exist, the returned shift (if non-nil) clears them.
+func (s *FlightPlanService) deconflict(ctx context.Context, plan model.FlightPlan, suggestShift bool) ([]model.PlanConflict, *time.Duration, error) {
+	// Look far enough around the plan to also cover every candidate time shift.
+	margin := s.deconf.TimeBuffer
+	if suggestShift {
+		margin += s.deconf.MaxTimeShift
+	}
+	others, err := s.plans.ListActiveFlightPlans(ctx, plan.StartTime.Add(-margin), plan.EndTime.Add(margin),
+		model.LineStringWKT(plan.Route), s.deconf.HorizontalSeparation, plan.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	volumes := DiscretizePlan(plan, s.deconf.SliceDuration)
+	otherVolumes := make([][]model.SpaceTimeVolume, len(others))
+	conflicts := []model.PlanConflict{}
+	for i, other := range others {
+		otherVolumes[i] = DiscretizePlan(other, s.deconf.SliceDuration)
+		conflicts = append(conflicts, FindConflicts(volumes, other, otherVolumes[i], s.deconf)...)
+	}
+
+	if len(conflicts) == 0 || !suggestShift {
+		return conflicts, nil, nil
+	}
+	shift, ok := SuggestTimeShift(volumes, others, otherVolumes, s.deconf)
+	if !ok {
+		return conflicts, nil, nil
+	}
+	return conflicts, &shift, nil
+}
+
+// assess checks the plan's route corridor and altitude band against buildings.
+// A building that reaches above the whole band rejects the plan; one that only
+// reaches into the band requires a manual review.
+func (s *FlightPlanService) assess(ctx context.Context, plan model.FlightPlan, buffer float64) (string, []string, error) {
+	buildings, err := s.buildings.GetBuildingsAlongRoute(ctx, model.LineStringWKT(plan.Route), plan.AltitudeMin-buffer, buffer)
+	if err != nil {
+		return "", nil, err
+	}
+
+	status := model.PlanApproved
+	reasons := []string{}
+	for _, b := range buildings {
+		if b.BuildingHeight == nil {
+			continue
+		}
+		top := *b.BuildingHeight + buffer
+		if top >= plan.AltitudeMax {
+			status = model.PlanRejected
+			reasons = append(reasons, fmt.Sprintf("building %d (height %.2fm, %.1fm from route) blocks the whole altitude band", b.BuildingID, *b.BuildingHeight, b.Distance))
+			continue
+		}
+		if status != model.PlanRejected {
+			status = model.PlanNeedsReview
+		}
+		reasons = append(reasons, fmt.Sprintf("building %d (height %.2fm, %.1fm from route) requires flying above %.2fm", b.BuildingID, *b.BuildingHeight, b.Distance, top))
+	}
+	if len(reasons) == 0 {
+		reasons = append(reasons, "no obstacles within the route corridor and altitude band")
+	}
+	return status, reasons, nil
+}
+
+// GetFlightPlan returns a plan together with its status history.
+func (s *FlightPlanService) GetFlightPlan(ctx context.Context, id int64) (*model.FlightPlanDetail, error) {
+	plan, err := s.plans.GetFlightPlan(ctx, id)
+	if err != nil {
+		return nil, planError(err)
+	}
+	history, err := s.plans.GetFlightPlanHistory(ctx, id)
+	if err != nil {
+		return nil, planError(err)
+	}
+	return &model.FlightPlanDetail{Plan: *plan, History: history}, nil
+}
+
+// ListFlightPlans returns plans filtered by drone ID and status (empty matches all).
+func (s *FlightPlanService) ListFlightPlans(ctx context.Context, droneID, status string) ([]model.FlightPlan, error) {
+	plans, err := s.plans.ListFlightPlans(ctx, droneID, status)
+	if err != nil {
+		return nil, planError(err)
+	}
+	return plans, nil
+}
+
+// ReviewFlightPlan records a reviewer's approve or reject decision for a plan that
+// needs review. A plan cannot be reviewed by the caller who submitted it, and an
+// approval is refused while the plan conflicts with a plan approved since.
+func (s *FlightPlanService) ReviewFlightPlan(ctx context.Context, id int64, decision, reviewer, comment string) (*model.FlightPlan, error) {
+	utils.Info(ctx, "service: reviewing flight plan", "plan_id", id, "reviewer", reviewer, "decision", decision)
+
+	var note *string
+	if comment != "" {
+		note = &comment
+	}
+
+	var reviewed *model.FlightPlan
+	err := s.plans.LockPlanApproval(ctx, func() error {
+		plan, err := s.plans.GetFlightPlan(ctx, id)
+		if err != nil {
+			return err
+		}
+		if plan.Status != model.PlanNeedsReview {
+			return apperr.New(apperr.CodeFlightPlanNotReviewable, plan.Status)
+		}
+		if plan.SubmittedBy != "" && plan.SubmittedBy == reviewer {
+			return apperr.New(apperr.CodeFlightPlanSelfReview)
+		}
+
+		if decision == model.PlanApproved {
+			conflicts, _, err := s.deconflict(ctx, *plan, false)
+			if err != nil {
+				return err
+			}
+			for _, c := range conflicts {
+				if c.PlanStatus == model.PlanApproved {
+					return apperr.New(apperr.CodeFlightPlanConflict, c.PlanID).WithDetails(conflicts)
+				}
+			}
+		}
+
+		reasons := make([]string, len(plan.Reasons), len(plan.Reasons)+1)
+		copy(reasons, plan.Reasons)
+		reasons = append(reasons, fmt.Sprintf("%s by reviewer %s", decision, reviewer))
+		reviewed, err = s.plans.UpdateFlightPlanStatus(ctx, id, decision, reasons, reviewer, note)
+		return err
+	})
+	if err != nil {
+		return nil, planError(err)
+	}
+	return reviewed, nil
+}
@@ -0,0 +1,92 @@
+// internal/service/health_service.go
+package service
+
+import (
+	"collision_app_go/internal/model"
+	"collision_app_go/internal/repository"
+	"collision_app_go/utils"
+	"context"
+	"sync/atomic"
+)
+
+// requiredTables must exist for the service to answer requests.
+var requiredTables = []string{"hzdk_buildings"}
+
+type HealthService struct {
+	repo     *repository.HealthRepository
+	draining atomic.Bool
+}
+
+func NewHealthService(repo *repository.HealthRepository) *HealthService {
+	return &HealthService{repo: repo}
+}
+
+// SetDraining marks the instance as shutting down; readiness reports not-ready from then on.
+func (s *HealthService) SetDraining() {
+	s.draining.Store(true)
+}
+
+// Readiness checks the database dependencies and reports whether the instance
+// should receive traffic, together with the result of each check.
+func (s *HealthService) Readiness(ctx context.Context) (*model.Readiness, bool) {
+	ready := true
+	checks := map[string]any{}
+
+	if s.draining.Load() {
+		ready = false
+		checks["shutdown"] = "draining"
+	}
+
+	if err := s.repo.Ping(ctx); err != nil {
+		utils.Warn(ctx, "readiness: database ping failed", "error", err)
+		checks["database"] = err.Error()
+		ready = false
+	} else {
+		checks["database"] = "ok"
+
+		version, err := s.repo.PostGISVersion(ctx)
+		switch {
+		case err != nil:
+			checks["postgis"] = err.Error()
+			ready = false
+		case version == "":
+			checks["postgis"] = "extension not installed"
+			ready = false
+		default:
+			checks["postgis"] = version
+		}
+
+		for _, table := range requiredTables {
+			exists, err := s.repo.TableExists(ctx, table)
+			switch {
+			case err != nil:
+				checks[table] = err.Error()
+				ready = false
+			case !exists:
+				checks[table] = "table missing"
+				ready = false
+			default:
+				checks[table] = "ok"
+			}
+		}
+	}
+
+	stat := s.repo.PoolStat()
+	saturation := 0.0
+	if stat.MaxConns() > 0 {
+		saturation = float64(stat.AcquiredConns()) / float64(stat.MaxConns())
+	}
+	checks["pool"] = model.PoolStats{
+		Acquired:   stat.AcquiredConns(),
+		Idle:       stat.IdleConns(),
+		Total:      stat.TotalConns(),
+		Max:        stat.MaxConns(),
+		Saturation: saturation,
+	}
+
+	status := model.ReadinessReady
+	if !ready {
+		status = model.ReadinessNotReady
+	}
+	return &model.Readiness{Status: status, Checks: checks}, ready
+}
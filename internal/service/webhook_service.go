@@ -0,0 +1,300 @@
+// internal/service/webhook_service.go
+package service
+
+import (
+	"collision_app_go/internal/apperr"
+	"collision_app_go/internal/model"
+	"collision_app_go/internal/repository"
+	"collision_app_go/utils"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// WebhookSecretPrefix starts every generated webhook signing secret.
+const WebhookSecretPrefix = "whsec_"
+
+// publishTimeout bounds storing an event in the outbox. Publishing outlives the
+// request that triggered it, so a client disconnecting does not drop the event.
+const publishTimeout = 5 * time.Second
+
+// maxDeliveries caps the delivery history returned for a subscription.
+const maxDeliveries = 200
+
+// Check events are queued in memory and written to the outbox by Run.
+const (
+	checkQueueSize = 1000
+	// checkStateTTL is how long the last result of a caller's check is remembered.
+	// A caller still colliding after this much silence raises a new event.
+	checkStateTTL = 5 * time.Minute
+)
+
+type WebhookService struct {
+	repo *repository.WebhookRepository
+	// queued receives a signal whenever events were added to the outbox, so that the
+	// dispatcher does not have to wait for its next poll.
+	queued chan struct{}
+	// checks holds the events raised by PublishCheck until Run stores them.
+	checks chan checkEvent
+
+	mu sync.Mutex
+	// states is the last positive result of each caller's check, keyed by checkKey.
+	states map[string]checkState
+}
+
+type checkEvent struct {
+	ctx       context.Context
+	eventType string
+	data      model.CollisionEvent
+}
+
+type checkState struct {
+	state string // event type and level
+	seen  time.Time
+}
+
+func NewWebhookService(repo *repository.WebhookRepository) *WebhookService {
+	return &WebhookService{
+		repo:   repo,
+		queued: make(chan struct{}, 1),
+		checks: make(chan checkEvent, checkQueueSize),
+		states: make(map[string]checkState),
+	}
+}
+
+// Queued signals that new deliveries may be due.
+func (s *WebhookService) Queued() <-chan struct{} {
+	return s.queued
+}
+
+// CreateSubscription validates and stores a subscription. The returned subscription
+// carries its signing secret, which cannot be retrieved later.
+func (s *WebhookService) CreateSubscription(ctx context.Context, sub model.WebhookSubscription) (*model.WebhookSubscription, error) {
+	if u, err := url.Parse(sub.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, apperr.InvalidArgument("url", "format", "must be an absolute http(s) URL")
+	}
+	if len(sub.Events) == 0 {
+		return nil, apperr.InvalidArgument("events", "min_items", 1)
+	}
+	seen := make(map[string]bool, len(sub.Events))
+	events := sub.Events[:0]
+	for _, e := range sub.Events {
+		if !model.ValidEventType(e) {
+			return nil, apperr.InvalidArgument("events", "enum", model.EventTypes)
+		}
+		if !seen[e] {
+			seen[e] = true
+			events = append(events, e)
+		}
+	}
+	sub.Events = events
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, apperr.Wrap(apperr.CodeWebhookFailed, err)
+	}
+	secret := WebhookSecretPrefix + base64.RawURLEncoding.EncodeToString(b)
+
+	if err := s.repo.CreateSubscription(ctx, &sub, secret); err != nil {
+		return nil, webhookError(err)
+	}
+	sub.Secret = secret
+	utils.Info(ctx, "service: webhook subscription created", "subscription_id", sub.ID, "url", sub.URL, "events", sub.Events)
+	return &sub, nil
+}
+
+// ListSubscriptions returns every subscription (without secrets).
+func (s *WebhookService) ListSubscriptions(ctx context.Context) ([]model.WebhookSubscription, error) {
+	subs, err := s.repo.ListSubscriptions(ctx)
+	if err != nil {
+		return nil, webhookError(err)
+	}
+	return subs, nil
+}
+
+// DeleteSubscription deactivates a subscription. Pending deliveries are no longer sent.
+func (s *WebhookService) DeleteSubscription(ctx context.Context, id int64) error {
+	if err := s.repo.DeactivateSubscription(ctx, id); err != nil {
+		return webhookError(err)
+	}
+	utils.Info(ctx, "service: webhook subscription deactivated", "subscription_id", id)
+	return nil
+}
+
+// ListDeliveries returns the latest deliveries of a subscription, optionally only
+// those with the given status.
+func (s *WebhookService) ListDeliveries(ctx context.Context, id int64, status string) ([]model.WebhookDelivery, error) {
+	if _, err := s.repo.GetSubscription(ctx, id); err != nil {
+		return nil, webhookError(err)
+	}
+	deliveries, err := s.repo.ListDeliveries(ctx, id, status, maxDeliveries)
+	if err != nil {
+		return nil, webhookError(err)
+	}
+	return deliveries, nil
+}
+
+// Publish stores an event in the outbox of every subscriber. Failures are logged
+// rather than returned: the operation that raised the event has already succeeded.
+// A nil service publishes nothing, so callers need not check whether webhooks are
+// enabled.
+func (s *WebhookService) Publish(ctx context.Context, eventType string, data any) {
+	if s == nil {
+		return
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		utils.Error(ctx, "failed to generate webhook event id", "error", err)
+		return
+	}
+	event := model.WebhookEvent{
+		ID:         hex.EncodeToString(b),
+		Type:       eventType,
+		CreateTime: time.Now().UTC(),
+		Data:       data,
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		utils.Error(ctx, "failed to encode webhook event", "event_type", eventType, "error", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), publishTimeout)
+	defer cancel()
+	n, err := s.repo.EnqueueEvent(ctx, event.ID, eventType, payload)
+	if err != nil || n == 0 {
+		return
+	}
+	utils.Debug(ctx, "webhook event queued", "event_id", event.ID, "event_type", eventType, "deliveries", n)
+	select {
+	case s.queued <- struct{}{}:
+	default:
+	}
+}
+
+// PublishCheck publishes a collision or warning event when a caller's check turns
+// positive or changes level; repeating the same result publishes nothing, so a
+// client polling near a building raises one event rather than one per request.
+// Checks are told apart by caller, source, check kind and drone ID. Events are
+// queued and stored by Run, off the request path; when the queue is full they are
+// dropped with a warning.
+func (s *WebhookService) PublishCheck(ctx context.Context, source, check, droneID string, pos *model.Point3D, result any) {
+	if s == nil {
+		return
+	}
+	eventType, level := "", ""
+	switch r := result.(type) {
+	case *model.CollisionResult:
+		if r.IsCollision {
+			eventType = model.EventCollision
+		}
+	case *model.SolidCollisionResult:
+		if r.IsCollision {
+			eventType = model.EventCollision
+		}
+	case *model.RouteResult:
+		if r.IsCollision {
+			eventType = model.EventCollision
+		}
+	case *model.PredictionResult:
+		if r.IsCollision {
+			eventType = model.EventCollision
+		}
+	case *model.ZonesResult:
+		level = r.Level
+		if r.IsCollision {
+			eventType = model.EventCollision
+		} else if r.Level != "" {
+			eventType = model.EventWarning
+		}
+	}
+	if !s.changed(checkKey(ctx, source, check, droneID), eventType+":"+level, eventType != "") {
+		return
+	}
+	event := checkEvent{ctx: context.WithoutCancel(ctx), eventType: eventType, data: model.CollisionEvent{
+		Source:   source,
+		Check:    check,
+		DroneID:  droneID,
+		Position: pos,
+		Level:    level,
+		Result:   result,
+	}}
+	select {
+	case s.checks <- event:
+	default:
+		utils.Warn(ctx, "webhook event queue full, dropping event", "event_type", eventType, "check", check, "drone_id", droneID)
+	}
+}
+
+// changed records the latest result of a check and reports whether it is positive
+// and differs from the previous one.
+func (s *WebhookService) changed(key, state string, positive bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !positive {
+		delete(s.states, key)
+		return false
+	}
+	prev, ok := s.states[key]
+	s.states[key] = checkState{state: state, seen: time.Now()}
+	return !ok || prev.state != state
+}
+
+// checkKey identifies a caller's check: the authenticated subject or, for anonymous
+// callers, the client address.
+func checkKey(ctx context.Context, source, check, droneID string) string {
+	caller := "ip:" + OriginFromContext(ctx).Addr
+	if p := PrincipalFromContext(ctx); p != nil && p.Method != model.AuthMethodNone {
+		caller = p.Method + ":" + p.Subject
+	}
+	return caller + "|" + source + "|" + check + "|" + droneID
+}
+
+// Run stores the events queued by PublishCheck in the outbox and forgets check
+// results not repeated within checkStateTTL, until ctx is done. Events still
+// queued then are stored before it returns.
+func (s *WebhookService) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			for {
+				select {
+				case e := <-s.checks:
+					s.Publish(e.ctx, e.eventType, e.data)
+				default:
+					return
+				}
+			}
+		case e := <-s.checks:
+			s.Publish(e.ctx, e.eventType, e.data)
+		case <-ticker.C:
+			s.evictStates()
+		}
+	}
+}
+
+func (s *WebhookService) evictStates() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cutoff := time.Now().Add(-checkStateTTL)
+	for key, st := range s.states {
+		if st.seen.Before(cutoff) {
+			delete(s.states, key)
+		}
+	}
+}
+
+func webhookError(err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return &apperr.Error{Code: apperr.CodeWebhookNotFound, Err: err}
+	}
+	return apperr.Wrap(apperr.CodeWebhookFailed, err)
+}
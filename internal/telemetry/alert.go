@@ -0,0 +1,234 @@
+// internal/telemetry/alert.go
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"collision_app_go/internal/metrics"
+	"collision_app_go/internal/model"
+	"collision_app_go/internal/service"
+	"collision_app_go/utils"
+)
+
+// Alerter delivers telemetry alerts. Alert is called from the check goroutine of a
+// single system, so implementations must be safe for concurrent use.
+type Alerter interface {
+	Alert(ctx context.Context, alert model.TelemetryAlert)
+}
+
+// Alerters fans an alert out to every alerter in order.
+type Alerters []Alerter
+
+func (a Alerters) Alert(ctx context.Context, alert model.TelemetryAlert) {
+	metrics.TelemetryAlerts.WithLabelValues(alertLabel(alert)).Inc()
+	for _, alerter := range a {
+		alerter.Alert(ctx, alert)
+	}
+}
+
+// LogAlerter writes alerts to the application log.
+type LogAlerter struct{}
+
+func (LogAlerter) Alert(ctx context.Context, alert model.TelemetryAlert) {
+	if alert.Type == model.AlertGeofence {
+		args := []any{
+			"system_id", alert.SystemID,
+			"addr", alert.Addr,
+			"lon", alert.Position.Longitude,
+			"lat", alert.Position.Latitude,
+			"height", alert.Position.Height,
+		}
+		if alert.Geofence == nil {
+			utils.Info(ctx, "telemetry geofence cleared, no active flight plan", args...)
+			return
+		}
+		args = append(args, "plan_id", alert.Geofence.PlanID, "distance", alert.Geofence.Distance, "reasons", alert.Geofence.Reasons)
+		if alert.Geofence.Inside {
+			utils.Info(ctx, "telemetry geofence cleared", args...)
+			return
+		}
+		utils.Warn(ctx, "telemetry geofence breach", args...)
+		return
+	}
+
+	args := []any{
+		"system_id", alert.SystemID,
+		"addr", alert.Addr,
+		"level", alert.Level,
+		"previous_level", alert.PreviousLevel,
+		"is_collision", alert.IsCollision,
+		"lon", alert.Position.Longitude,
+		"lat", alert.Position.Latitude,
+		"height", alert.Position.Height,
+		"buildings", len(alert.Buildings),
+	}
+	if alert.Level == "" {
+		utils.Info(ctx, "telemetry alert cleared", args...)
+		return
+	}
+	utils.Warn(ctx, "telemetry alert", args...)
+}
+
+// WebhookAlerter POSTs each alert as JSON to a fixed URL. Failed deliveries are
+// logged and dropped.
+type WebhookAlerter struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookAlerter returns an alerter posting to url with the given request timeout.
+func NewWebhookAlerter(url string, timeout time.Duration) *WebhookAlerter {
+	return &WebhookAlerter{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+func (w *WebhookAlerter) Alert(ctx context.Context, alert model.TelemetryAlert) {
+	if err := w.post(ctx, alert); err != nil {
+		utils.Error(ctx, "failed to deliver telemetry alert webhook", "system_id", alert.SystemID, "level", alert.Level, "error", err)
+	}
+}
+
+func (w *WebhookAlerter) post(ctx context.Context, alert model.TelemetryAlert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if id := utils.RequestIDFromContext(ctx); id != "" {
+		req.Header.Set("X-Request-ID", id)
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// EventAlerter publishes alerts as webhook events: collision when a system enters
+// the critical zone, warning when it enters another warning zone and
+// geofence_breach when it leaves its flight plan corridor. Clearing alerts publish
+// nothing.
+type EventAlerter struct {
+	webhooks *service.WebhookService
+}
+
+func NewEventAlerter(webhooks *service.WebhookService) *EventAlerter {
+	return &EventAlerter{webhooks: webhooks}
+}
+
+func (e *EventAlerter) Alert(ctx context.Context, alert model.TelemetryAlert) {
+	droneID := strconv.Itoa(int(alert.SystemID))
+	switch {
+	case alert.Type == model.AlertGeofence:
+		if alert.Breached() {
+			e.webhooks.Publish(ctx, model.EventGeofenceBreach, model.GeofenceEvent{
+				Source:   "telemetry",
+				DroneID:  droneID,
+				Position: alert.Position,
+				Geofence: *alert.Geofence,
+			})
+		}
+	case alert.Level != "":
+		eventType := model.EventWarning
+		if alert.IsCollision {
+			eventType = model.EventCollision
+		}
+		pos := alert.Position
+		e.webhooks.Publish(ctx, eventType, model.CollisionEvent{
+			Source:   "telemetry",
+			Check:    "telemetry",
+			DroneID:  droneID,
+			Position: &pos,
+			Level:    alert.Level,
+			Result:   alert,
+		})
+	}
+}
+
+// subscriberBuffer is the number of alerts queued for a slow stream subscriber
+// before further alerts to it are dropped.
+const subscriberBuffer = 64
+
+// Hub broadcasts alerts to stream subscribers such as the SSE endpoint.
+type Hub struct {
+	mu     sync.Mutex
+	subs   map[chan model.TelemetryAlert]struct{}
+	closed bool
+}
+
+func NewHub() *Hub {
+	return &Hub{subs: make(map[chan model.TelemetryAlert]struct{})}
+}
+
+// Subscribe returns a channel of alerts and a function that cancels the
+// subscription. The channel is closed on cancel or when the hub closes.
+func (h *Hub) Subscribe() (<-chan model.TelemetryAlert, func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ch := make(chan model.TelemetryAlert, subscriberBuffer)
+	if h.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	h.subs[ch] = struct{}{}
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.subs[ch]; ok {
+			delete(h.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// Alert sends alert to every subscriber without blocking.
+func (h *Hub) Alert(ctx context.Context, alert model.TelemetryAlert) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs {
+		select {
+		case ch <- alert:
+		default:
+			utils.Warn(ctx, "telemetry alert dropped for slow subscriber", "system_id", alert.SystemID)
+		}
+	}
+}
+
+// Close ends every subscription so that streaming handlers return, e.g. on shutdown.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for ch := range h.subs {
+		delete(h.subs, ch)
+		close(ch)
+	}
+}
+
+// alertLabel is the metric label of an alert: its new level (or "clear") for zone
+// alerts, geofence_breach or geofence_clear for geofence alerts.
+func alertLabel(alert model.TelemetryAlert) string {
+	switch {
+	case alert.Type == model.AlertGeofence && alert.Breached():
+		return "geofence_breach"
+	case alert.Type == model.AlertGeofence:
+		return "geofence_clear"
+	case alert.Level == "":
+		return "clear"
+	}
+	return alert.Level
+}
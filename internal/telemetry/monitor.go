@@ -0,0 +1,372 @@
+// internal/telemetry/monitor.go
+// Package telemetry tracks vehicles from MAVLink telemetry and checks their
+// positions against the warning zones of CollisionService and the geofence of
+// their approved flight plans.
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"collision_app_go/internal/mavlink"
+	"collision_app_go/internal/metrics"
+	"collision_app_go/internal/model"
+	"collision_app_go/internal/service"
+	"collision_app_go/utils"
+)
+
+// checkTimeout bounds a single zone check of one position.
+const checkTimeout = 5 * time.Second
+
+// maxDatagram is the largest UDP payload.
+const maxDatagram = 65535
+
+// Options configures a Monitor.
+type Options struct {
+	// CheckInterval is the minimum time between two checks of the same system.
+	// Positions received in between only update the tracked state.
+	CheckInterval time.Duration
+	// StaleAfter drops systems that have sent nothing for this long.
+	StaleAfter time.Duration
+	// Footprint inflates the warning zones for every vehicle.
+	Footprint model.Footprint
+	// GeofenceCorridor is the allowed horizontal distance from the route of the
+	// vehicle's active flight plan; 0 disables the geofence check.
+	GeofenceCorridor float64
+	// Recorder, when set, receives every datagram for later replay.
+	Recorder *mavlink.TlogWriter
+	// AllowedSources lists the networks Listen accepts datagrams from; datagrams
+	// from other senders are dropped. Empty accepts every sender.
+	AllowedSources []*net.IPNet
+}
+
+// Monitor tracks MAVLink systems and alerts when their warning level changes or
+// they breach their geofence.
+type Monitor struct {
+	collision *service.CollisionService
+	plans     *service.FlightPlanService // nil disables the geofence check
+	alerter   Alerter
+	opts      Options
+
+	mu      sync.Mutex
+	systems map[systemKey]*system
+	checks  sync.WaitGroup
+}
+
+// systemKey identifies a system by its sender as well as its ID, since vehicles
+// on different links often share the default system ID 1.
+type systemKey struct {
+	addr string
+	id   uint8
+}
+
+type system struct {
+	model.TrackedSystem
+	checking     bool
+	checkStarted time.Time
+	lastSeen     time.Time
+}
+
+func NewMonitor(collision *service.CollisionService, plans *service.FlightPlanService, alerter Alerter, opts Options) *Monitor {
+	return &Monitor{
+		collision: collision,
+		plans:     plans,
+		alerter:   alerter,
+		opts:      opts,
+		systems:   make(map[systemKey]*system),
+	}
+}
+
+// Listen reads datagrams from conn until ctx is cancelled, then closes conn and
+// waits for running checks.
+func (m *Monitor) Listen(ctx context.Context, conn net.PacketConn) error {
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+	defer m.checks.Wait()
+
+	buf := make([]byte, maxDatagram)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("telemetry read failed: %w", err)
+		}
+		if !m.allowed(addr) {
+			metrics.TelemetryFrames.WithLabelValues("rejected").Inc()
+			utils.Debug(ctx, "telemetry datagram from a source that is not allowed", "addr", addr)
+			continue
+		}
+		m.HandleDatagram(ctx, buf[:n], addr, time.Now())
+	}
+}
+
+// allowed reports whether datagrams from addr are accepted.
+func (m *Monitor) allowed(addr net.Addr) bool {
+	if len(m.opts.AllowedSources) == 0 {
+		return true
+	}
+	udp, ok := addr.(*net.UDPAddr)
+	if !ok {
+		return false
+	}
+	for _, n := range m.opts.AllowedSources {
+		if n.Contains(udp.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+// Run drops stale systems and flushes the recorder until ctx is cancelled.
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.opts.StaleAfter / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			m.evict(ctx, now)
+			if m.opts.Recorder != nil {
+				if err := m.opts.Recorder.Flush(); err != nil {
+					utils.Warn(ctx, "failed to flush telemetry recording", "error", err)
+				}
+			}
+		}
+	}
+}
+
+// HandleDatagram decodes every frame in a datagram received at the given time.
+func (m *Monitor) HandleDatagram(ctx context.Context, data []byte, from net.Addr, at time.Time) {
+	if m.opts.Recorder != nil {
+		if err := m.opts.Recorder.Write(at, data); err != nil {
+			utils.Warn(ctx, "failed to record telemetry datagram", "addr", from, "error", err)
+		}
+	}
+
+	for len(data) > 0 {
+		frame, n, err := mavlink.Parse(data)
+		data = data[n:]
+		switch {
+		case errors.Is(err, mavlink.ErrUnknownMessage):
+			metrics.TelemetryFrames.WithLabelValues("other").Inc()
+			continue
+		case err != nil:
+			metrics.TelemetryFrames.WithLabelValues("invalid").Inc()
+			utils.Debug(ctx, "invalid telemetry frame", "addr", from, "error", err)
+			continue
+		}
+
+		msg, err := mavlink.Decode(frame)
+		if err != nil {
+			metrics.TelemetryFrames.WithLabelValues("invalid").Inc()
+			continue
+		}
+		switch msg := msg.(type) {
+		case mavlink.Heartbeat:
+			metrics.TelemetryFrames.WithLabelValues("heartbeat").Inc()
+			m.heartbeat(ctx, frame, from, msg, at)
+		case mavlink.GlobalPositionInt:
+			metrics.TelemetryFrames.WithLabelValues("global_position_int").Inc()
+			m.position(ctx, frame, from, msg, at)
+		}
+	}
+}
+
+// Systems returns a snapshot of the tracked systems ordered by system ID and
+// address.
+func (m *Monitor) Systems() []model.TrackedSystem {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]model.TrackedSystem, 0, len(m.systems))
+	for _, s := range m.systems {
+		out = append(out, s.TrackedSystem)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].SystemID != out[j].SystemID {
+			return out[i].SystemID < out[j].SystemID
+		}
+		return out[i].Addr < out[j].Addr
+	})
+	return out
+}
+
+func (m *Monitor) heartbeat(ctx context.Context, frame mavlink.Frame, from net.Addr, hb mavlink.Heartbeat, at time.Time) {
+	// Ground stations, cameras and companion computers send heartbeats too
+	if !hb.IsVehicle() {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.track(ctx, frame, from, at)
+	s.VehicleType = hb.Type
+	s.Autopilot = hb.Autopilot
+	s.Armed = hb.Armed()
+	s.LastHeartbeat = &at
+}
+
+func (m *Monitor) position(ctx context.Context, frame mavlink.Frame, from net.Addr, p mavlink.GlobalPositionInt, at time.Time) {
+	// Building heights are above ground, so the altitude above home is used as the
+	// drone's height. This assumes take-off near ground level.
+	pos := model.Point3D{
+		Longitude: p.Longitude(),
+		Latitude:  p.Latitude(),
+		Height:    math.Max(p.RelativeAltitude(), 0),
+	}
+	if pos.Longitude < -180 || pos.Longitude > 180 || pos.Latitude < -90 || pos.Latitude > 90 || (p.Lat == 0 && p.Lon == 0) {
+		utils.Debug(ctx, "ignoring telemetry position without a fix", "system_id", frame.SystemID, "lat", pos.Latitude, "lon", pos.Longitude)
+		return
+	}
+
+	m.mu.Lock()
+	s := m.track(ctx, frame, from, at)
+	north, east, up := p.Velocity()
+	s.Position = &pos
+	s.GroundSpeed = math.Hypot(north, east)
+	s.VerticalSpeed = up
+	s.Heading = nil
+	if heading, ok := p.Heading(); ok {
+		s.Heading = &heading
+	}
+	s.LastPosition = &at
+
+	start := !s.checking && at.Sub(s.checkStarted) >= m.opts.CheckInterval
+	if start {
+		s.checking, s.checkStarted = true, at
+	}
+	m.mu.Unlock()
+
+	if start {
+		m.checks.Add(1)
+		go func() {
+			defer m.checks.Done()
+			m.check(ctx, s, pos, at)
+		}()
+	}
+}
+
+// track returns the state of the frame's system, creating it on first contact.
+// The caller must hold m.mu.
+func (m *Monitor) track(ctx context.Context, frame mavlink.Frame, from net.Addr, at time.Time) *system {
+	key := systemKey{addr: from.String(), id: frame.SystemID}
+	s, ok := m.systems[key]
+	if !ok {
+		s = &system{TrackedSystem: model.TrackedSystem{SystemID: frame.SystemID, Addr: key.addr}}
+		m.systems[key] = s
+		metrics.TelemetrySystems.Set(float64(len(m.systems)))
+		utils.Info(ctx, "telemetry system discovered", "system_id", frame.SystemID, "component_id", frame.ComponentID, "addr", from)
+	}
+	s.ComponentID = frame.ComponentID
+	s.lastSeen = at
+	return s
+}
+
+// check runs the zone and geofence checks for one position and alerts on changes.
+func (m *Monitor) check(ctx context.Context, s *system, pos model.Point3D, at time.Time) {
+	ctx = utils.WithRequestID(ctx, fmt.Sprintf("mavlink-%d", s.SystemID))
+	ctx = service.WithOrigin(ctx, model.SourceTelemetry, s.Addr)
+	checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+	defer func() {
+		m.mu.Lock()
+		s.checking = false
+		m.mu.Unlock()
+	}()
+
+	m.checkZones(ctx, checkCtx, s, pos, at)
+	if m.plans != nil && m.opts.GeofenceCorridor > 0 {
+		m.checkGeofence(ctx, checkCtx, s, pos, at)
+	}
+}
+
+// checkZones alerts when the warning level of the system changes.
+func (m *Monitor) checkZones(ctx, checkCtx context.Context, s *system, pos model.Point3D, at time.Time) {
+	result, err := m.collision.CheckCollisionZones(checkCtx, pos.Longitude, pos.Latitude, pos.Height, nil, m.opts.Footprint)
+
+	if err != nil {
+		if ctx.Err() == nil {
+			utils.Warn(ctx, "telemetry collision check failed", "system_id", s.SystemID, "error", err)
+		}
+		return
+	}
+
+	m.mu.Lock()
+	previous := s.Level
+	s.Level = result.Level
+	s.LastCheck = &at
+	m.mu.Unlock()
+
+	if result.Level == previous {
+		return
+	}
+	buildings := []model.BuildingDistance{}
+	for _, z := range result.Zones {
+		buildings = append(buildings, result.Buildings[z.Level]...)
+	}
+	m.alerter.Alert(ctx, model.TelemetryAlert{
+		Type:          model.AlertZone,
+		SystemID:      s.SystemID,
+		Addr:          s.Addr,
+		Level:         result.Level,
+		PreviousLevel: previous,
+		IsCollision:   result.IsCollision,
+		Position:      pos,
+		Buildings:     buildings,
+		Time:          at,
+	})
+}
+
+// checkGeofence alerts when the system leaves or re-enters the corridor of its
+// active flight plan. Flight plans are matched by the decimal system ID.
+func (m *Monitor) checkGeofence(ctx, checkCtx context.Context, s *system, pos model.Point3D, at time.Time) {
+	status, err := m.plans.CheckGeofence(checkCtx, strconv.Itoa(int(s.SystemID)), at, pos, m.opts.GeofenceCorridor)
+	if err != nil {
+		if ctx.Err() == nil {
+			utils.Warn(ctx, "telemetry geofence check failed", "system_id", s.SystemID, "error", err)
+		}
+		return
+	}
+
+	m.mu.Lock()
+	wasBreached := s.Geofence != nil && !s.Geofence.Inside
+	s.Geofence = status
+	level := s.Level
+	m.mu.Unlock()
+
+	alert := model.TelemetryAlert{
+		Type:          model.AlertGeofence,
+		SystemID:      s.SystemID,
+		Addr:          s.Addr,
+		Level:         level,
+		PreviousLevel: level,
+		Position:      pos,
+		Buildings:     []model.BuildingDistance{},
+		Geofence:      status,
+		Time:          at,
+	}
+	if alert.Breached() != wasBreached {
+		m.alerter.Alert(ctx, alert)
+	}
+}
+
+func (m *Monitor) evict(ctx context.Context, now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key, s := range m.systems {
+		if now.Sub(s.lastSeen) > m.opts.StaleAfter {
+			delete(m.systems, key)
+			utils.Warn(ctx, "telemetry system lost", "system_id", key.id, "addr", key.addr, "last_seen", s.lastSeen, "level", s.Level)
+		}
+	}
+	metrics.TelemetrySystems.Set(float64(len(m.systems)))
+}
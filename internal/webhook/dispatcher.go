@@ -0,0 +1,203 @@
+// internal/webhook/dispatcher.go
+// Package webhook delivers queued webhook events from the outbox table to their
+// subscribers.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"collision_app_go/internal/metrics"
+	"collision_app_go/internal/model"
+	"collision_app_go/internal/repository"
+	"collision_app_go/utils"
+)
+
+// Delivery request headers.
+const (
+	HeaderSignature = "X-Webhook-Signature" // t=<unix seconds>,v1=<hex HMAC-SHA256>
+	HeaderEvent     = "X-Webhook-Event"     // event type
+	HeaderEventID   = "X-Webhook-ID"        // event ID, identical across retries and subscribers
+	HeaderDelivery  = "X-Webhook-Delivery"  // outbox entry ID
+)
+
+// maxErrorBody is how much of a failed response body is kept as the delivery error.
+const maxErrorBody = 512
+
+// Options configures a Dispatcher.
+type Options struct {
+	PollInterval time.Duration // how often the outbox is checked for due deliveries
+	BatchSize    int           // deliveries claimed per poll
+	Concurrency  int           // deliveries sent in parallel
+	MaxAttempts  int           // attempts before a delivery is marked failed
+	BaseBackoff  time.Duration // delay before the first retry, doubled on each further one
+	MaxBackoff   time.Duration // upper bound of the retry delay
+	Timeout      time.Duration // timeout of a single delivery request
+	// Wake, when set, triggers a poll before PollInterval elapses.
+	Wake <-chan struct{}
+}
+
+// Dispatcher sends due outbox entries to their subscribers. Several dispatchers may
+// share a database: deliveries are leased when claimed, so each is sent by one.
+type Dispatcher struct {
+	repo   *repository.WebhookRepository
+	opts   Options
+	client *http.Client
+}
+
+func NewDispatcher(repo *repository.WebhookRepository, opts Options) *Dispatcher {
+	return &Dispatcher{
+		repo:   repo,
+		opts:   opts,
+		client: &http.Client{Timeout: opts.Timeout},
+	}
+}
+
+// Sign returns the hex HMAC-SHA256 of "<timestamp>.<body>" keyed with the
+// subscription secret. Receivers recompute it to authenticate a delivery and should
+// reject timestamps far from their own clock to prevent replays.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Run delivers due events until ctx is cancelled. Deliveries in flight when ctx
+// is cancelled are abandoned and retried after their lease expires.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ctx = utils.WithRequestID(ctx, "webhook-dispatcher")
+	ticker := time.NewTicker(d.opts.PollInterval)
+	defer ticker.Stop()
+	for {
+		// Keep claiming while full batches come back, so a backlog drains without
+		// waiting for the ticker.
+		for d.dispatch(ctx) == d.opts.BatchSize && ctx.Err() == nil {
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.opts.Wake:
+		}
+	}
+}
+
+// dispatch claims one batch, sends it and returns the number of deliveries claimed.
+func (d *Dispatcher) dispatch(ctx context.Context) int {
+	// The lease outlasts the slowest batch: every worker may time out on each of its
+	// share of the deliveries.
+	rounds := (d.opts.BatchSize + d.opts.Concurrency - 1) / d.opts.Concurrency
+	lease := time.Duration(rounds+1) * d.opts.Timeout
+	deliveries, err := d.repo.ClaimDueDeliveries(ctx, d.opts.BatchSize, lease)
+	if err != nil {
+		if ctx.Err() == nil {
+			utils.Warn(ctx, "failed to claim webhook deliveries", "error", err)
+		}
+		return 0
+	}
+
+	queue := make(chan model.WebhookDelivery)
+	var wg sync.WaitGroup
+	for i := 0; i < d.opts.Concurrency && i < len(deliveries); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for delivery := range queue {
+				d.deliver(ctx, delivery)
+			}
+		}()
+	}
+	for _, delivery := range deliveries {
+		queue <- delivery
+	}
+	close(queue)
+	wg.Wait()
+	return len(deliveries)
+}
+
+// deliver sends one delivery and records the outcome.
+func (d *Dispatcher) deliver(ctx context.Context, delivery model.WebhookDelivery) {
+	status, retryAfter, err := d.send(ctx, delivery)
+	if ctx.Err() != nil {
+		return
+	}
+	if err == nil {
+		metrics.WebhookDeliveries.WithLabelValues(delivery.EventType, "delivered").Inc()
+		_ = d.repo.MarkDelivered(ctx, delivery.ID, status)
+		return
+	}
+
+	var retryAt *time.Time
+	result := "failed"
+	if delivery.Attempts < d.opts.MaxAttempts {
+		at := time.Now().Add(max(d.backoff(delivery.Attempts), min(retryAfter, d.opts.MaxBackoff)))
+		retryAt, result = &at, "retry"
+	}
+	metrics.WebhookDeliveries.WithLabelValues(delivery.EventType, result).Inc()
+	utils.Warn(ctx, "webhook delivery failed",
+		"delivery_id", delivery.ID, "subscription_id", delivery.SubscriptionID, "event_type", delivery.EventType,
+		"attempt", delivery.Attempts, "status", status, "retry_at", retryAt, "error", err)
+	_ = d.repo.MarkAttemptFailed(ctx, delivery.ID, status, err.Error(), retryAt)
+}
+
+// send POSTs the stored event. It returns the response status (0 without a
+// response) and the delay requested by a Retry-After header, if any.
+func (d *Dispatcher) send(ctx context.Context, delivery model.WebhookDelivery) (int, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, 0, err
+	}
+	now := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "collision-webhooks/1")
+	req.Header.Set(HeaderSignature, fmt.Sprintf("t=%d,v1=%s", now, Sign(delivery.Secret, now, delivery.Payload)))
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderEventID, delivery.EventID)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(delivery.ID, 10))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBody))
+		return resp.StatusCode, 0, nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	var retryAfter time.Duration
+	if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs > 0 {
+		retryAfter = time.Duration(secs) * time.Second
+	}
+	if len(bytes.TrimSpace(body)) > 0 {
+		return resp.StatusCode, retryAfter, fmt.Errorf("subscriber returned %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+	return resp.StatusCode, retryAfter, fmt.Errorf("subscriber returned %s", resp.Status)
+}
+
+// backoff returns the delay after the given number of failed attempts: BaseBackoff
+// doubled per attempt, capped at MaxBackoff, with up to half of it randomized so
+// that retries of many deliveries do not arrive in lockstep.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.opts.MaxBackoff
+	if shift := attempts - 1; shift < 32 {
+		delay = min(d.opts.BaseBackoff<<shift, d.opts.MaxBackoff)
+	}
+	if delay <= 0 {
+		return d.opts.MaxBackoff
+	}
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
@@ -2,113 +2,66 @@
 package main
 
 import (
-	"context"
+	"flag"
 	"fmt"
-	"log"
-	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
-	"time"
-
-	"github.com/gin-gonic/gin"
-	"github.com/jackc/pgx/v5/pgxpool"
-
-	"collision_app_go/config"
-	"collision_app_go/internal/handler"
-	"collision_app_go/internal/repository"
-	"collision_app_go/internal/service"
-	"collision_app_go/utils"
 )
 
-func main() {
-	// 1. Load configuration
-	cfg, err := config.Load()
-	if err != nil {
-		log.Fatalf("Failed to load config: %v\n", err)
-	}
-	poolCfg := config.LoadPoolConfig()
-	utils.Infof("Database config loaded: %s", cfg.ConnectionString())
-	utils.Infof("Connection pool config: Min=%d, Max=%d", poolCfg.MinConns, poolCfg.MaxConns)
+// configFile is the YAML config file given with the global -config flag.
+var configFile string
 
-	// 2. Connect to database (using connection pool)
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+// command is a CLI subcommand. run receives the arguments after the command name.
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
 
-	// Configure the pool
-	poolConfig, err := pgxpool.ParseConfig(cfg.ConnectionString())
-	if err != nil {
-		utils.Errorf("Failed to parse pool config: %v\n", err)
-		log.Fatalf("Failed to parse pool config: %v\n", err)
-	}
-	poolConfig.MinConns = poolCfg.MinConns
-	poolConfig.MaxConns = poolCfg.MaxConns
-	// You can configure other pool options here like MaxConnLifetime, HealthCheckPeriod etc.
+var commands = []command{
+	{"serve", "serve                              run the HTTP and gRPC API servers (default)", runServe},
+	{"import", "import [-quiet] [-invalid-geometry P] [-dry-run [-report F]] <file> import buildings from a WKT,height file", runImport},
+	{"migrate", "migrate [up|down|status] [-steps N] apply or roll back schema migrations", runMigrate},
+	{"check", "check --lon X --lat Y --height Z [--as-of T] run a one-off collision query", runCheck},
+	{"export", "export [-o file] [-format F] [-bbox B] [-area-code C] [-type T] [-as-of T] dump buildings as WKT, GeoJSON, CSV, Shapefile or GeoPackage", runExport},
+	{"apikey", "apikey [create|list|revoke] [-name N -role R] [id] manage API keys", runAPIKey},
+	{"replay", "replay [-addr A] [-speed N] [-loop] <file.tlog> replay recorded MAVLink telemetry over UDP", runReplay},
+	{"audit-replay", "audit-replay [-from T] [-to T] [-check K] [-source S] [-limit N] [-all] re-run recorded collision checks and report changed answers", runAuditReplay},
+}
 
-	dbpool, err := pgxpool.NewWithConfig(ctx, poolConfig)
-	if err != nil {
-		utils.Errorf("Unable to create connection pool: %v\n", err)
-		log.Fatalf("Unable to create connection pool: %v\n", err)
-	}
-	defer dbpool.Close()
+func main() {
+	flag.StringVar(&configFile, "config", "", "YAML config file (default $CONFIG_FILE or ./config.yaml)")
+	flag.Usage = usage
+	flag.Parse()
 
-	// Test the connection
-	if err := dbpool.Ping(ctx); err != nil {
-		utils.Errorf("Unable to ping database: %v\n", err)
-		log.Fatalf("Unable to ping database: %v\n", err)
+	name, args := "serve", []string(nil)
+	if flag.NArg() > 0 {
+		name, args = flag.Arg(0), flag.Args()[1:]
 	}
-	utils.Info("Successfully connected to the database!")
-
-	// 3. Initialize layers
-	buildingRepo := repository.NewBuildingRepository(dbpool)
-	collisionService := service.NewCollisionService(buildingRepo)
-	buildingsService := service.NewBuildingsService(buildingRepo)
-	handler := handler.NewHandler(collisionService, buildingsService)
 
-	// 4. Setup Gin router
-	// gin.SetMode(gin.ReleaseMode) // Uncomment for production
-	r := gin.Default()
-
-	// Define routes
-	api := r.Group("/api/v1")
-	{
-		api.GET("/collision_info", handler.CollisionInfo)
-		api.POST("/insert_buildings_info", handler.InsertBuildingsInfo)
-		api.POST("/update_buildings_info", handler.UpdateBuildingsInfo)
-		// Add more routes here...
+	for _, cmd := range commands {
+		if cmd.name == name {
+			if err := cmd.run(args); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
 	}
 
-	// 5. Start server in a goroutine
-	srvAddr := ":8800" // Or get from config
-	server := &http.Server{
-		Addr:    srvAddr,
-		Handler: r,
+	if name != "help" && name != "-h" && name != "--help" {
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", name)
 	}
-
-	// Channel to listen for interrupt signal
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-
-	go func() {
-		utils.Infof("Starting server on %s...\n", srvAddr)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			utils.Errorf("listen: %s\n", err)
-			log.Fatalf("listen: %s\n", err)
-		}
-	}()
-
-	// 6. Wait for interrupt signal
-	<-quit
-	utils.Info("Shutting down server...")
-
-	// 7. Gracefully shutdown the server with a timeout
-	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	if err := server.Shutdown(ctx); err != nil {
-		utils.Errorf("Server forced to shutdown:", err)
-		log.Fatal("Server forced to shutdown:", err)
+	usage()
+	if name != "help" && name != "-h" && name != "--help" {
+		os.Exit(2)
 	}
+}
 
-	utils.Info("Server exiting")
-	fmt.Println("Server exiting")
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s [-config file] <command> [options]\n\nCommands:\n", os.Args[0])
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %s\n", cmd.usage)
+	}
+	fmt.Fprintf(os.Stderr, "\nGlobal options:\n")
+	flag.PrintDefaults()
 }
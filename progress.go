@@ -0,0 +1,47 @@
+// progress.go
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// progressBar renders a single-line text progress bar, redrawn at most every 100ms.
+type progressBar struct {
+	mu    sync.Mutex
+	w     io.Writer
+	width int
+	start time.Time
+	last  time.Time
+}
+
+func newProgressBar(w io.Writer) *progressBar {
+	return &progressBar{w: w, width: 40, start: time.Now()}
+}
+
+// Update redraws the bar; it matches repository.ProgressFunc.
+func (p *progressBar) Update(done, total int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	if done < total && now.Sub(p.last) < 100*time.Millisecond {
+		return
+	}
+	p.last = now
+
+	ratio := 1.0
+	if total > 0 {
+		ratio = float64(done) / float64(total)
+	}
+	filled := int(ratio * float64(p.width))
+	fmt.Fprintf(p.w, "\r[%s%s] %3.0f%% %d/%d %s",
+		strings.Repeat("#", filled), strings.Repeat(".", p.width-filled),
+		ratio*100, done, total, now.Sub(p.start).Round(time.Second))
+	if done >= total {
+		fmt.Fprintln(p.w)
+	}
+}
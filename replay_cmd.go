@@ -0,0 +1,99 @@
+// replay_cmd.go
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"collision_app_go/internal/mavlink"
+)
+
+// runReplay sends the frames of a .tlog recording to the telemetry listener over UDP,
+// keeping their original spacing. It needs neither the database nor the config.
+func runReplay(args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	addr := fs.String("addr", "127.0.0.1:14550", "UDP address of the telemetry listener")
+	speed := fs.Float64("speed", 1, "playback speed factor; 0 sends as fast as possible")
+	loop := fs.Bool("loop", false, "restart from the beginning at the end of the recording")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: replay [-addr host:port] [-speed N] [-loop] <file.tlog>")
+	}
+	if *speed < 0 {
+		return fmt.Errorf("-speed must not be negative")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	conn, err := net.Dial("udp", *addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	total := 0
+	for {
+		n, err := replayFile(ctx, fs.Arg(0), conn, *speed)
+		total += n
+		if err != nil || !*loop || ctx.Err() != nil {
+			fmt.Fprintf(os.Stderr, "Replayed %d frames to %s\n", total, *addr)
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// replayFile sends one pass of the recording and returns the number of frames sent.
+func replayFile(ctx context.Context, path string, w io.Writer, speed float64) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	r := mavlink.NewTlogReader(f)
+	var first time.Time
+	start := time.Now()
+	sent := 0
+	for {
+		at, frame, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return sent, nil
+		}
+		if err != nil {
+			return sent, fmt.Errorf("%s: frame %d: %w", path, sent+1, err)
+		}
+
+		if first.IsZero() {
+			first = at
+		}
+		if speed > 0 {
+			due := start.Add(time.Duration(float64(at.Sub(first)) / speed))
+			select {
+			case <-ctx.Done():
+				return sent, nil
+			case <-time.After(time.Until(due)):
+			}
+		} else if ctx.Err() != nil {
+			return sent, nil
+		}
+
+		if _, err := w.Write(frame); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+}
@@ -0,0 +1,403 @@
+// serve.go
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+
+	"collision_app_go/internal/grpcserver"
+	"collision_app_go/internal/handler"
+	"collision_app_go/internal/mavlink"
+	"collision_app_go/internal/metrics"
+	"collision_app_go/internal/middleware"
+	"collision_app_go/internal/migrate"
+	"collision_app_go/internal/model"
+	"collision_app_go/internal/openapi"
+	"collision_app_go/internal/ratelimit"
+	"collision_app_go/internal/repository"
+	"collision_app_go/internal/service"
+	"collision_app_go/internal/telemetry"
+	"collision_app_go/internal/webhook"
+	"collision_app_go/utils"
+)
+
+// runServe runs the HTTP API server, and the gRPC server when enabled, until SIGINT
+// or SIGTERM.
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	// 1. Load configuration and connect to database (using connection pool)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	a, err := newApp(ctx, false)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+	dbpool := a.dbpool
+	cfg := a.cfg
+
+	utils.Info(ctx, "warning zones loaded", "zones", cfg.Collision.WarningZones)
+
+	if cfg.Database.AutoMigrate {
+		migrator, err := migrate.New(dbpool)
+		if err != nil {
+			return fmt.Errorf("failed to load migrations: %w", err)
+		}
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to apply migrations: %w", err)
+		}
+		utils.Info(ctx, "schema migrations applied", "count", applied)
+	}
+
+	if err := metrics.RegisterPoolCollector(dbpool); err != nil {
+		return fmt.Errorf("failed to register pool metrics: %w", err)
+	}
+
+	// 2. Initialize layers
+	// Collision checks are recorded in the audit log when it is enabled
+	auditor, stopAudit := a.auditor()
+	defer stopAudit()
+	if auditor != nil {
+		utils.Info(ctx, "collision audit log enabled", "retention_months", cfg.Audit.RetentionMonths, "store_results", cfg.Audit.StoreResults)
+	}
+	buildingRepo := repository.NewBuildingRepository(dbpool)
+	collisionService := service.NewCollisionService(buildingRepo, cfg.Collision.WarningZones, auditor)
+	buildingsService := service.NewBuildingsService(buildingRepo)
+	flightPlanRepo := repository.NewFlightPlanRepository(dbpool)
+	flightPlanService := service.NewFlightPlanService(flightPlanRepo, buildingRepo, a.deconflictionConfig())
+	webhookService := a.webhookService()
+	healthService := service.NewHealthService(repository.NewHealthRepository(dbpool))
+	authService, err := a.authService()
+	if err != nil {
+		return fmt.Errorf("failed to set up authentication: %w", err)
+	}
+	authenticate := middleware.Authenticate(authService)
+	if !cfg.Auth.Enabled {
+		utils.Warn(ctx, "authentication is disabled; every request runs as admin")
+		authenticate = middleware.Anonymous()
+	}
+	healthHandler := handler.NewHealthHandler(healthService)
+	apiDoc := openapi.Build(openapi.Options{
+		Version:                  "1.0.0",
+		DefaultCollisionDistance: cfg.Collision.DefaultDistance,
+		MaxCollisionDistance:     cfg.Collision.MaxDistance,
+		DefaultLookAhead:         cfg.Collision.LookAhead,
+		MaxLookAhead:             handler.MaxLookAhead,
+		DefaultPlanBuffer:        cfg.Collision.PlanBuffer,
+		MaxPlanDuration:          cfg.Collision.MaxPlanDuration,
+		MaxPlanWaypoints:         cfg.Collision.MaxPlanWaypoints,
+		DefaultInvalidGeometry:   cfg.Import.InvalidGeometry,
+	})
+	openAPIHandler, err := handler.NewOpenAPIHandler(apiDoc)
+	if err != nil {
+		return fmt.Errorf("failed to render OpenAPI document: %w", err)
+	}
+	// MAVLink telemetry is checked against the warning zones and flight plan geofences.
+	// Alerts are streamed to /api/v1/telemetry/alerts, besides the log and webhooks
+	var telemetryHandler *handler.TelemetryHandler
+	var monitor *telemetry.Monitor
+	var alertHub *telemetry.Hub
+	var telemetryConn net.PacketConn
+	var recorder *mavlink.TlogWriter
+	if t := cfg.Telemetry; t.Enabled {
+		alertHub = telemetry.NewHub()
+		alerters := telemetry.Alerters{alertHub}
+		if t.AlertLog {
+			alerters = append(alerters, telemetry.LogAlerter{})
+		}
+		if t.AlertWebhook != "" {
+			alerters = append(alerters, telemetry.NewWebhookAlerter(t.AlertWebhook, t.WebhookTimeout))
+		}
+		if webhookService != nil {
+			alerters = append(alerters, telemetry.NewEventAlerter(webhookService))
+		}
+		if t.RecordFile != "" {
+			recording, err := os.OpenFile(t.RecordFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+			if err != nil {
+				return fmt.Errorf("failed to open telemetry recording: %w", err)
+			}
+			defer recording.Close()
+			recorder = mavlink.NewTlogWriter(recording)
+		}
+		sources, err := t.SourceNetworks()
+		if err != nil {
+			return err
+		}
+		telemetryConn, err = net.ListenPacket("udp", t.Addr)
+		if err != nil {
+			return fmt.Errorf("failed to listen for telemetry on %s: %w", t.Addr, err)
+		}
+		monitor = telemetry.NewMonitor(collisionService, flightPlanService, alerters, telemetry.Options{
+			CheckInterval:    t.CheckInterval,
+			StaleAfter:       t.StaleAfter,
+			Footprint:        model.Footprint{DroneRadius: t.DroneRadius},
+			GeofenceCorridor: t.GeofenceCorridor,
+			Recorder:         recorder,
+			AllowedSources:   sources,
+		})
+		telemetryHandler = handler.NewTelemetryHandler(monitor, alertHub)
+	}
+
+	var webhookHandler *handler.WebhookHandler
+	if webhookService != nil {
+		webhookHandler = handler.NewWebhookHandler(webhookService)
+	}
+
+	handler := handler.NewHandler(collisionService, buildingsService, flightPlanService, webhookService, handler.Defaults{
+		CollisionDistance: cfg.Collision.DefaultDistance,
+		LookAhead:         cfg.Collision.LookAhead,
+		PlanBuffer:        cfg.Collision.PlanBuffer,
+		InvalidGeometry:   cfg.Import.InvalidGeometry,
+	})
+
+	// 3. Setup Gin router
+	gin.SetMode(cfg.Server.GinMode)
+	r := gin.New()
+	r.Use(middleware.RequestID(), middleware.Origin(), middleware.AccessLog(), middleware.Metrics(), middleware.Recovery(), middleware.Errors())
+	r.NoRoute(middleware.NoRoute)
+
+	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	r.GET("/healthz", healthHandler.Healthz)
+	r.GET("/readyz", healthHandler.Readyz)
+	r.GET("/openapi.json", openAPIHandler.Spec)
+	r.GET("/swagger/*filepath", openAPIHandler.SwaggerUI)
+
+	// Rate limits and quotas apply per client IP before authentication and per client
+	// after it. Imports, exports and route planning also pass through the stricter
+	// heavy limiter, which keeps a separate budget for each route.
+	bgCtx, bgCancel := context.WithCancel(context.Background())
+	var background sync.WaitGroup
+	defer background.Wait()
+	defer bgCancel()
+
+	apiMiddleware := []gin.HandlerFunc{authenticate}
+	heavy := func(c *gin.Context) { c.Next() }
+	var ipLimiter, limiter *ratelimit.Limiter
+	var quota *ratelimit.Quota
+	if rl := cfg.RateLimit; rl.Enabled {
+		ipLimiter = ratelimit.NewLimiter(ratelimit.Limit{Rate: rl.IPRate, Burst: rl.IPBurst})
+		limiter = ratelimit.NewLimiter(ratelimit.Limit{Rate: rl.Rate, Burst: rl.Burst})
+		heavyLimiter := ratelimit.NewLimiter(ratelimit.Limit{Rate: rl.HeavyRate, Burst: rl.HeavyBurst})
+		go ipLimiter.Run(bgCtx, time.Minute)
+		go limiter.Run(bgCtx, time.Minute)
+		go heavyLimiter.Run(bgCtx, time.Minute)
+		apiMiddleware = []gin.HandlerFunc{middleware.IPRateLimit(ipLimiter, "ip"), authenticate, middleware.RateLimit(limiter, "default")}
+		heavy = middleware.RouteRateLimit(heavyLimiter, "heavy")
+
+		if rl.DailyQuota > 0 {
+			quota = ratelimit.NewQuota(rl.DailyQuota)
+			quotaStore := repository.NewQuotaRepository(dbpool)
+			if err := quota.Load(ctx, quotaStore); err != nil {
+				utils.Warn(ctx, "failed to load quota usage, starting from zero", "error", err)
+			}
+			background.Add(1)
+			go func() {
+				defer background.Done()
+				quota.Run(bgCtx, quotaStore, rl.QuotaFlushInterval)
+			}()
+			apiMiddleware = append(apiMiddleware, middleware.DailyQuota(quota))
+		}
+		utils.Info(ctx, "rate limiting enabled", "rate", rl.Rate, "burst", rl.Burst,
+			"heavy_rate", rl.HeavyRate, "heavy_burst", rl.HeavyBurst, "ip_rate", rl.IPRate, "ip_burst", rl.IPBurst, "daily_quota", rl.DailyQuota)
+	}
+
+	if monitor != nil {
+		background.Add(2)
+		go func() {
+			defer background.Done()
+			monitor.Run(bgCtx)
+		}()
+		go func() {
+			defer background.Done()
+			utils.Info(ctx, "starting telemetry listener", "addr", cfg.Telemetry.Addr, "record_file", cfg.Telemetry.RecordFile)
+			if err := monitor.Listen(bgCtx, telemetryConn); err != nil {
+				utils.Error(ctx, "telemetry listener stopped", "error", err)
+			}
+			if recorder != nil {
+				if err := recorder.Flush(); err != nil {
+					utils.Error(ctx, "failed to flush telemetry recording", "error", err)
+				}
+			}
+		}()
+	}
+
+	// Webhook events are queued by the handlers, stored in the outbox and delivered here
+	if w := cfg.Webhooks; webhookService != nil {
+		dispatcher := webhook.NewDispatcher(repository.NewWebhookRepository(dbpool), webhook.Options{
+			PollInterval: w.PollInterval,
+			BatchSize:    w.BatchSize,
+			Concurrency:  w.Concurrency,
+			MaxAttempts:  w.MaxAttempts,
+			BaseBackoff:  w.BaseBackoff,
+			MaxBackoff:   w.MaxBackoff,
+			Timeout:      w.Timeout,
+			Wake:         webhookService.Queued(),
+		})
+		background.Add(2)
+		go func() {
+			defer background.Done()
+			webhookService.Run(bgCtx)
+		}()
+		go func() {
+			defer background.Done()
+			utils.Info(ctx, "starting webhook dispatcher", "poll_interval", w.PollInterval, "concurrency", w.Concurrency)
+			dispatcher.Run(bgCtx)
+		}()
+	}
+
+	// Parameters are validated against the OpenAPI document before reaching handlers
+	apiMiddleware = append(apiMiddleware, middleware.ValidateRequest(apiDoc))
+
+	// Define routes, guarded by role: viewer < operator < admin
+	api := r.Group("/api/v1", apiMiddleware...)
+	viewer := api.Group("", middleware.RequireRole(model.RoleViewer))
+	{
+		viewer.GET("/collision_info", handler.CollisionInfo)
+		viewer.GET("/collision_predict", handler.CollisionPredict)
+		viewer.GET("/collision_zones", handler.CollisionZones)
+		viewer.GET("/line_of_sight", handler.LineOfSight)
+		if telemetryHandler != nil {
+			viewer.GET("/telemetry/systems", telemetryHandler.Systems)
+			viewer.GET("/telemetry/alerts", telemetryHandler.Alerts)
+		}
+	}
+	operator := api.Group("", middleware.RequireRole(model.RoleOperator))
+	{
+		operator.POST("/flight_plans", heavy, handler.SubmitFlightPlan)
+		operator.GET("/flight_plans", handler.ListFlightPlans)
+		operator.GET("/flight_plans/:id", handler.GetFlightPlan)
+		operator.GET("/flight_plans/:id/conflicts", heavy, handler.FlightPlanConflicts)
+		operator.GET("/buildings/export", heavy, handler.ExportBuildings)
+	}
+	admin := api.Group("", middleware.RequireRole(model.RoleAdmin))
+	{
+		admin.POST("/insert_buildings_info", heavy, handler.InsertBuildingsInfo)
+		admin.POST("/update_buildings_info", heavy, handler.UpdateBuildingsInfo)
+		admin.POST("/flight_plans/:id/review", handler.ReviewFlightPlan)
+		if webhookHandler != nil {
+			admin.POST("/webhooks", webhookHandler.CreateWebhook)
+			admin.GET("/webhooks", webhookHandler.ListWebhooks)
+			admin.DELETE("/webhooks/:id", webhookHandler.DeleteWebhook)
+			admin.GET("/webhooks/:id/deliveries", webhookHandler.ListWebhookDeliveries)
+		}
+	}
+	// Add more routes here...
+
+	// 4. Start server in a goroutine
+	srvAddr := cfg.Server.Addr
+	server := &http.Server{
+		Addr:         srvAddr,
+		Handler:      r,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+	}
+	if alertHub != nil {
+		// End open alert streams, which would otherwise hold up Shutdown
+		server.RegisterOnShutdown(alertHub.Close)
+	}
+
+	// Channel to listen for interrupt signal
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	// A server that stops serving reports here, and the other is shut down too
+	serveErrs := make(chan error, 2)
+
+	// The gRPC API shares the collision service, credentials and per-client limits.
+	// Its listener is opened before the HTTP server starts, so that failing to open
+	// it leaves nothing to shut down.
+	var grpcServer *grpc.Server
+	if cfg.GRPC.Enabled {
+		opts := grpcserver.Options{
+			DefaultCollisionDistance: cfg.Collision.DefaultDistance,
+			MaxCollisionDistance:     cfg.Collision.MaxDistance,
+			IPLimiter:                ipLimiter,
+			Limiter:                  limiter,
+			Quota:                    quota,
+			Reflection:               cfg.GRPC.Reflection,
+			Webhooks:                 webhookService,
+		}
+		if cfg.Auth.Enabled {
+			opts.AuthService = authService
+		}
+		lis, err := net.Listen("tcp", cfg.GRPC.Addr)
+		if err != nil {
+			return fmt.Errorf("failed to listen for gRPC on %s: %w", cfg.GRPC.Addr, err)
+		}
+		grpcServer = grpcserver.New(collisionService, opts)
+		go func() {
+			utils.Info(ctx, "starting gRPC server", "addr", cfg.GRPC.Addr, "reflection", cfg.GRPC.Reflection)
+			if err := grpcServer.Serve(lis); err != nil {
+				serveErrs <- fmt.Errorf("gRPC serve failed: %w", err)
+			}
+		}()
+	}
+
+	go func() {
+		utils.Info(ctx, "starting server", "addr", srvAddr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			serveErrs <- fmt.Errorf("listen failed: %w", err)
+		}
+	}()
+
+	// 5. Wait for interrupt signal, or for a server to fail
+	var serveErr error
+	select {
+	case <-quit:
+		utils.Info(ctx, "shutting down server")
+		// Report not-ready first and give load balancers time to stop routing here
+		healthService.SetDraining()
+		time.Sleep(cfg.Server.DrainDelay)
+	case serveErr = <-serveErrs:
+		utils.Error(ctx, "server failed, shutting down", "error", serveErr)
+		healthService.SetDraining()
+	}
+
+	// 6. Gracefully shutdown the server with a timeout
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer shutdownCancel()
+	grpcStopped := make(chan struct{})
+	if grpcServer != nil {
+		go func() {
+			defer close(grpcStopped)
+			grpcServer.GracefulStop()
+		}()
+	}
+	err = server.Shutdown(shutdownCtx)
+	if grpcServer != nil {
+		select {
+		case <-grpcStopped:
+		case <-shutdownCtx.Done():
+			// Open streams do not end on their own; cut them off at the deadline
+			utils.Warn(shutdownCtx, "gRPC server forced to stop")
+			grpcServer.Stop()
+			<-grpcStopped
+		}
+	}
+	if err != nil {
+		utils.Error(shutdownCtx, "server forced to shutdown", "error", err)
+		return errors.Join(serveErr, err)
+	}
+
+	utils.Info(shutdownCtx, "server exiting")
+	return serveErr
+}
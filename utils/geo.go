@@ -0,0 +1,52 @@
+// utils/geo.go
+package utils
+
+import "math"
+
+// EarthRadius is the mean Earth radius in meters used for spherical calculations.
+const EarthRadius = 6371008.8
+
+// Destination returns the point reached by travelling distance meters from
+// (lon, lat) along the given bearing (degrees clockwise from north).
+func Destination(lon, lat, bearing, distance float64) (float64, float64) {
+	if distance == 0 {
+		return lon, lat
+	}
+	phi1 := lat * math.Pi / 180
+	lambda1 := lon * math.Pi / 180
+	theta := bearing * math.Pi / 180
+	delta := distance / EarthRadius
+
+	phi2 := math.Asin(math.Sin(phi1)*math.Cos(delta) + math.Cos(phi1)*math.Sin(delta)*math.Cos(theta))
+	lambda2 := lambda1 + math.Atan2(math.Sin(theta)*math.Sin(delta)*math.Cos(phi1), math.Cos(delta)-math.Sin(phi1)*math.Sin(phi2))
+
+	// Normalise longitude to [-180, 180)
+	lon2 := math.Mod(lambda2*180/math.Pi+540, 360) - 180
+	return lon2, phi2 * 180 / math.Pi
+}
+
+// Haversine returns the great-circle distance in meters between two points.
+func Haversine(lon1, lat1, lon2, lat2 float64) float64 {
+	phi1 := lat1 * math.Pi / 180
+	phi2 := lat2 * math.Pi / 180
+	dPhi := (lat2 - lat1) * math.Pi / 180
+	dLambda := (lon2 - lon1) * math.Pi / 180
+
+	a := math.Sin(dPhi/2)*math.Sin(dPhi/2) + math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+	return 2 * EarthRadius * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+// ToLocal projects (lon, lat) onto a local east/north plane in meters centred on
+// (refLon, refLat). The equirectangular approximation is accurate over city scales.
+func ToLocal(refLon, refLat, lon, lat float64) (float64, float64) {
+	x := (lon - refLon) * math.Pi / 180 * EarthRadius * math.Cos(refLat*math.Pi/180)
+	y := (lat - refLat) * math.Pi / 180 * EarthRadius
+	return x, y
+}
+
+// FromLocal is the inverse of ToLocal.
+func FromLocal(refLon, refLat, x, y float64) (float64, float64) {
+	lon := refLon + x/(EarthRadius*math.Cos(refLat*math.Pi/180))*180/math.Pi
+	lat := refLat + y/EarthRadius*180/math.Pi
+	return lon, lat
+}
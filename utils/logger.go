@@ -2,41 +2,139 @@
 package utils
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"runtime"
+	"strings"
+	"time"
 )
 
-var Logger *log.Logger
+// Logger is the process-wide structured logger. It is replaced by SetupLogger.
+var Logger *slog.Logger
 
 func init() {
-	// Simple logger to stdout, you can configure it further (e.g., file output, levels)
-	Logger = log.New(os.Stdout, "", log.LstdFlags|log.Lshortfile)
+	// Text logger to stdout until SetupLogger is called with the real configuration
+	Logger = slog.New(&contextHandler{slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{AddSource: true})})
 }
 
-// Info logs an info message.
-func Info(v ...interface{}) {
-	Logger.Println("INFO:", v)
+// SetupLogger configures the process-wide logger.
+// level is one of debug, info, warn, error; format is text or json; output is
+// stdout, stderr or a file path (appended to). The returned closer releases the sink.
+func SetupLogger(level, format, output string) (io.Closer, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q: %w", level, err)
+	}
+
+	format = strings.ToLower(format)
+	if format != "" && format != "text" && format != "json" {
+		return nil, fmt.Errorf("invalid log format %q", format)
+	}
+
+	var w io.Writer
+	var closer io.Closer = nopCloser{}
+	switch output {
+	case "", "stdout":
+		w = os.Stdout
+	case "stderr":
+		w = os.Stderr
+	default:
+		f, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+		w, closer = f, f
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl, AddSource: true}
+	var h slog.Handler = slog.NewTextHandler(w, opts)
+	if format == "json" {
+		h = slog.NewJSONHandler(w, opts)
+	}
+
+	Logger = slog.New(&contextHandler{h})
+	slog.SetDefault(Logger)
+	return closer, nil
 }
 
-// Infof logs an info message with formatting.
-func Infof(format string, v ...interface{}) {
-	Logger.Printf("INFO: "+format, v...)
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the request ID. Every log line written
+// with that context includes it as the request_id attribute.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
 }
 
-// Error logs an error message.
-func Error(v ...interface{}) {
-	Logger.Println("ERROR:", v)
+// RequestIDFromContext returns the request ID carried by ctx, if any.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
 }
 
-// Errorf logs an error message with formatting.
-func Errorf(format string, v ...interface{}) {
-	Logger.Printf("ERROR: "+format, v...)
+// contextHandler adds the request ID from the record's context to every record.
+type contextHandler struct {
+	slog.Handler
 }
 
-// Debug logs a debug message (conditional logging example).
-func Debug(v ...interface{}) {
-	// You could check an environment variable like DEBUG=true to enable
-	if os.Getenv("DEBUG") == "true" {
-		Logger.Println("DEBUG:", v)
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestIDFromContext(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{h.Handler.WithGroup(name)}
+}
+
+// Debug logs a debug message with key/value attributes.
+func Debug(ctx context.Context, msg string, args ...any) {
+	logAt(ctx, slog.LevelDebug, msg, args...)
+}
+
+// Info logs an info message with key/value attributes.
+func Info(ctx context.Context, msg string, args ...any) {
+	logAt(ctx, slog.LevelInfo, msg, args...)
+}
+
+// Warn logs a warning message with key/value attributes.
+func Warn(ctx context.Context, msg string, args ...any) {
+	logAt(ctx, slog.LevelWarn, msg, args...)
+}
+
+// Error logs an error message with key/value attributes.
+func Error(ctx context.Context, msg string, args ...any) {
+	logAt(ctx, slog.LevelError, msg, args...)
+}
+
+// Fatal logs an error message and exits the process with status 1.
+func Fatal(ctx context.Context, msg string, args ...any) {
+	logAt(ctx, slog.LevelError, msg, args...)
+	os.Exit(1)
+}
+
+// logAt writes a record attributed to the caller of the exported helper.
+func logAt(ctx context.Context, level slog.Level, msg string, args ...any) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !Logger.Enabled(ctx, level) {
+		return
 	}
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:]) // skip runtime.Callers, logAt and the exported helper
+	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	r.Add(args...)
+	_ = Logger.Handler().Handle(ctx, r)
 }